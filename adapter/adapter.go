@@ -0,0 +1,130 @@
+// Package adapter serves http.Handler requests from the event formats Lambda
+// functions are invoked with: API Gateway REST API (v1) and HTTP API (v2)
+// payloads, Application Load Balancer target group events and Lambda Function
+// URL events, which use the HTTP API v2 payload.
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
+)
+
+const (
+	FORMAT_API_GATEWAY_V1 = "apigateway-v1"
+	FORMAT_API_GATEWAY_V2 = "apigateway-v2"
+	FORMAT_ALB            = "alb"
+)
+
+// probe holds the fields needed to tell the event formats apart.
+type probe struct {
+	Version        string `json:"version"`
+	RequestContext struct {
+		ELB *json.RawMessage `json:"elb"`
+	} `json:"requestContext"`
+}
+
+// Format detects the format of the Lambda event. Function URL events are
+// reported as FORMAT_API_GATEWAY_V2, as they share the payload.
+func Format(payload []byte) (string, error) {
+	var p probe
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return "", fmt.Errorf("failed to decode event: %w", err)
+	}
+
+	switch {
+	case p.RequestContext.ELB != nil:
+		return FORMAT_ALB, nil
+	case p.Version == "2.0":
+		return FORMAT_API_GATEWAY_V2, nil
+	default:
+		return FORMAT_API_GATEWAY_V1, nil
+	}
+}
+
+// Handler implements lambda.Handler and passes events of any supported format
+// to the http.Handler.
+type Handler struct {
+	v1  *httpadapter.HandlerAdapter
+	v2  *httpadapter.HandlerAdapterV2
+	alb *httpadapter.HandlerAdapterALB
+}
+
+func New(handler http.Handler) *Handler {
+	return &Handler{
+		v1:  httpadapter.New(handler),
+		v2:  httpadapter.NewV2(handler),
+		alb: httpadapter.NewALB(handler),
+	}
+}
+
+// Invoke detects the format of the event and responds in the same format.
+func (h *Handler) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	format, err := Format(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FORMAT_ALB:
+		var event events.ALBTargetGroupRequest
+		if err = json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("failed to decode ALB event: %w", err)
+		}
+
+		res, err := h.alb.ProxyWithContext(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(albResponse(event, res))
+	case FORMAT_API_GATEWAY_V2:
+		var event events.APIGatewayV2HTTPRequest
+		if err = json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("failed to decode API Gateway v2 event: %w", err)
+		}
+
+		res, err := h.v2.ProxyWithContext(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(res)
+	default:
+		var event events.APIGatewayProxyRequest
+		if err = json.Unmarshal(payload, &event); err != nil {
+			return nil, fmt.Errorf("failed to decode API Gateway v1 event: %w", err)
+		}
+
+		res, err := h.v1.ProxyWithContext(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+
+		return json.Marshal(res)
+	}
+}
+
+// albResponse adapts the response to the target group settings: unless
+// multi-value headers are enabled, the load balancer only reads single-value
+// headers. The status description has to include the status code.
+func albResponse(event events.ALBTargetGroupRequest, res events.ALBTargetGroupResponse) events.ALBTargetGroupResponse {
+	res.StatusDescription = fmt.Sprintf("%d %s", res.StatusCode, http.StatusText(res.StatusCode))
+
+	if event.MultiValueHeaders == nil {
+		res.Headers = make(map[string]string, len(res.MultiValueHeaders))
+
+		for key, values := range res.MultiValueHeaders {
+			res.Headers[key] = strings.Join(values, ",")
+		}
+
+		res.MultiValueHeaders = nil
+	}
+
+	return res
+}
@@ -0,0 +1,133 @@
+package adapter
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// response is the union of the response formats.
+type response struct {
+	StatusCode        int                 `json:"statusCode"`
+	StatusDescription string              `json:"statusDescription"`
+	Headers           map[string]string   `json:"headers"`
+	MultiValueHeaders map[string][]string `json:"multiValueHeaders"`
+	Body              string              `json:"body"`
+	IsBase64Encoded   bool                `json:"isBase64Encoded"`
+}
+
+func (r *response) header(key string) string {
+	if value, ok := r.Headers[key]; ok {
+		return value
+	}
+
+	if values := r.MultiValueHeaders[key]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// echo responds with the request body, as well as the request line in the
+// X-Request header.
+func echo(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+	w.Header().Set("X-Request", r.Method+" "+r.URL.RequestURI())
+	w.WriteHeader(http.StatusCreated)
+	w.Write(body)
+}
+
+func binary(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("X-Request", r.Method+" "+r.URL.RequestURI())
+	w.Write([]byte{0xde, 0xad, 0xbe, 0xef})
+}
+
+func TestHandler(t *testing.T) {
+	const body = `{"label":"deploy freeze","value":true}`
+
+	tests := []struct {
+		event       string
+		format      string
+		multiValue  bool
+		description string
+	}{
+		{event: "apigateway-v1.json", format: FORMAT_API_GATEWAY_V1, multiValue: true},
+		{event: "apigateway-v2.json", format: FORMAT_API_GATEWAY_V2},
+		{event: "function-url.json", format: FORMAT_API_GATEWAY_V2},
+		{event: "alb.json", format: FORMAT_ALB, description: "201 Created"},
+		{event: "alb-multi-value.json", format: FORMAT_ALB, multiValue: true, description: "201 Created"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.event, func(t *testing.T) {
+			payload, err := os.ReadFile(filepath.Join("testdata", tt.event))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			format, err := Format(payload)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+
+			assert.Equal(t, tt.format, format)
+
+			out, err := New(http.HandlerFunc(echo)).Invoke(context.Background(), payload)
+			if err != nil {
+				t.Fatalf("Handler.Invoke() error = %v", err)
+			}
+
+			var res response
+			if err = json.Unmarshal(out, &res); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, http.StatusCreated, res.StatusCode)
+			assert.Equal(t, tt.description, res.StatusDescription)
+			assert.Equal(t, "POST /api/v1/echo?expires_in=60", res.header("X-Request"))
+			assert.Equal(t, "application/json", res.header("Content-Type"))
+			assert.Equal(t, tt.multiValue, len(res.MultiValueHeaders) > 0)
+			assert.False(t, res.IsBase64Encoded)
+			assert.Equal(t, body, res.Body)
+
+			if out, err = New(http.HandlerFunc(binary)).Invoke(context.Background(), payload); err != nil {
+				t.Fatalf("Handler.Invoke() error = %v", err)
+			}
+
+			res = response{}
+			if err = json.Unmarshal(out, &res); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.True(t, res.IsBase64Encoded)
+
+			decoded, err := base64.StdEncoding.DecodeString(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, decoded)
+		})
+	}
+
+	t.Run("invalid event", func(t *testing.T) {
+		_, err := New(http.HandlerFunc(echo)).Invoke(context.Background(), []byte("["))
+
+		assert.Error(t, err)
+	})
+}
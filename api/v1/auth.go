@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/saschazar21/go-baas/errors"
+)
+
+// bearerToken returns the bearer token of the request, if any.
+func bearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+
+	return token
+}
+
+// authorized reports whether the request carries one of the API keys as
+// bearer token.
+func authorized(r *http.Request, keys []string) bool {
+	token := bearerToken(r)
+	if token == "" {
+		return false
+	}
+
+	for _, key := range keys {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authenticate requires one of the configured API keys for every request
+// except the health checks, unless no API keys are configured.
+func (h *handler) authenticate(next http.Handler) http.Handler {
+	if len(h.config.Auth.APIKeys) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !publicPaths[r.URL.Path] && !authorized(r, h.config.Auth.APIKeys) {
+			httpErr := errors.NewHTTPError(http.StatusUnauthorized, &errors.UNAUTHORIZED_ERROR)
+			httpErr.SetHeader("WWW-Authenticate", "Bearer")
+			httpErr.Write(w)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,63 @@
+package v1_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthentication(t *testing.T) {
+	c := config.Default()
+	c.Auth.APIKeys = []string{"first", "second"}
+
+	router := v1.NewRouter(c, db.NewPool(c))
+
+	tests := []struct {
+		name          string
+		authorization string
+		want          int
+	}{
+		{
+			name: "missing token",
+			want: http.StatusUnauthorized,
+		},
+		{
+			name:          "wrong token",
+			authorization: "Bearer third",
+			want:          http.StatusUnauthorized,
+		},
+		{
+			name:          "wrong scheme",
+			authorization: "Basic second",
+			want:          http.StatusUnauthorized,
+		},
+		{
+			name:          "valid token",
+			authorization: "Bearer second",
+			want:          http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/unknown", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.want, rec.Code)
+
+			if tc.want == http.StatusUnauthorized {
+				assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
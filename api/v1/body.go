@@ -0,0 +1,42 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/saschazar21/go-baas/errors"
+)
+
+const (
+	// MAX_BODY_SIZE limits the size of request bodies in bytes.
+	MAX_BODY_SIZE = 1 << 20
+
+	// MAX_IMPORT_BODY_SIZE limits the size of imports in bytes.
+	MAX_IMPORT_BODY_SIZE = 32 << 20
+)
+
+// bodyLimits lists the paths, whose bodies may exceed MAX_BODY_SIZE.
+var bodyLimits = map[string]int64{
+	"/api/v1/import": MAX_IMPORT_BODY_SIZE,
+}
+
+// limitBody limits the size of the request body, exceeding the limit while
+// reading the body is answered with 413 Request Entity Too Large by the
+// handlers, a Content-Length beyond the limit right away.
+func (h *handler) limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit, ok := bodyLimits[r.URL.Path]
+		if !ok {
+			limit = MAX_BODY_SIZE
+		}
+
+		if r.ContentLength > limit {
+			httpErr := errors.NewHTTPError(http.StatusRequestEntityTooLarge, &errors.REQUEST_ENTITY_TOO_LARGE_ERROR)
+			httpErr.Write(w)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+
+		next.ServeHTTP(w, r)
+	})
+}
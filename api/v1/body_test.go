@@ -0,0 +1,146 @@
+package v1_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestBody(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	c := test.Config(t)
+
+	router := v1.NewRouter(c, db.NewPool(c))
+
+	oversized := `{"label":"` + strings.Repeat("a", v1.MAX_BODY_SIZE) + `"}`
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        io.Reader
+		want        int
+	}{
+		{
+			name:        "json with charset",
+			path:        "/api/v1/booleans",
+			contentType: "application/json; charset=UTF-8",
+			body:        strings.NewReader(`{"label":"charset","value":true}`),
+			want:        http.StatusOK,
+		},
+		{
+			name:        "form with charset",
+			path:        "/api/v1/booleans",
+			contentType: "application/x-www-form-urlencoded; charset=utf-8",
+			body:        strings.NewReader("label=charset&value=true"),
+			want:        http.StatusOK,
+		},
+		{
+			name:        "unsupported charset",
+			path:        "/api/v1/booleans",
+			contentType: "application/json; charset=iso-8859-1",
+			body:        strings.NewReader(`{"label":"charset"}`),
+			want:        http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "malformed content type",
+			path:        "/api/v1/booleans",
+			contentType: "application/json; charset",
+			body:        strings.NewReader(`{"label":"charset"}`),
+			want:        http.StatusUnsupportedMediaType,
+		},
+		{
+			name:        "unknown field",
+			path:        "/api/v1/booleans",
+			contentType: "application/json",
+			body:        strings.NewReader(`{"label":"unknown","valu":true}`),
+			want:        http.StatusOK,
+		},
+		{
+			name:        "multiple values",
+			path:        "/api/v1/booleans",
+			contentType: "application/json",
+			body:        strings.NewReader(`{"label":"first"}{"label":"second"}`),
+			want:        http.StatusBadRequest,
+		},
+		{
+			name:        "trailing garbage",
+			path:        "/api/v1/booleans",
+			contentType: "application/json",
+			body:        strings.NewReader(`{"label":"garbage"} garbage`),
+			want:        http.StatusBadRequest,
+		},
+		{
+			name:        "label with line break",
+			path:        "/api/v1/booleans",
+			contentType: "application/json",
+			body:        strings.NewReader(`{"label":"first\nsecond"}`),
+			want:        http.StatusBadRequest,
+		},
+		{
+			name:        "oversized body",
+			path:        "/api/v1/booleans",
+			contentType: "application/json",
+			// hides the Content-Length, so that the body is read
+			body: io.MultiReader(strings.NewReader(oversized)),
+			want: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "oversized schedule",
+			path:        "/api/v1/booleans/" + BOOLEAN_TEST_ID + "/schedules",
+			contentType: "application/json",
+			body:        strings.NewReader(oversized),
+			want:        http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "import with charset",
+			path:        "/api/v1/import",
+			contentType: "application/x-ndjson; charset=utf-8",
+			body:        strings.NewReader(""),
+			want:        http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, tt.body)
+			req.Header.Set("Content-Type", tt.contentType)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+		})
+	}
+
+	t.Run("rejects unknown fields in strict mode", func(t *testing.T) {
+		strict := test.Config(t)
+		strict.StrictJSON = true
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/booleans", strings.NewReader(`{"label":"unknown","valu":true}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		rec := httptest.NewRecorder()
+		v1.NewRouter(strict, db.NewPool(strict)).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+	})
+}
@@ -3,15 +3,15 @@ package v1
 import (
 	"encoding/json"
 	"net/http"
-	"strings"
 
 	"github.com/saschazar21/go-baas/booleans"
-	"github.com/saschazar21/go-baas/db"
 	"github.com/saschazar21/go-baas/errors"
 )
 
-func handleDeleteBooleanById(w http.ResponseWriter, r *http.Request, id string) {
-	client, err := db.NewRedis()
+func (h *handler) handleDeleteBooleanById(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	client, err := h.pool.Client(r.Context())
 	if err != nil {
 		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
 		httpErr.Write(w)
@@ -32,16 +32,16 @@ func handleDeleteBooleanById(w http.ResponseWriter, r *http.Request, id string)
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func handleGetBooleanById(w http.ResponseWriter, r *http.Request, id string) {
-	client, err := db.NewRedis()
+func (h *handler) handleGetBooleanById(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	client, err := h.pool.Client(r.Context())
 	if err != nil {
 		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
 		httpErr.Write(w)
 		return
 	}
 
-	defer client.Close()
-
 	b, err := booleans.GetBoolean(client, r.Context(), id)
 	if err != nil {
 		httpErr, ok := err.(*errors.HTTPError)
@@ -67,16 +67,16 @@ func handleGetBooleanById(w http.ResponseWriter, r *http.Request, id string) {
 	w.WriteHeader(http.StatusOK)
 }
 
-func handleToggleBooleanById(w http.ResponseWriter, r *http.Request, id string) {
-	client, err := db.NewRedis()
+func (h *handler) handleToggleBooleanById(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	client, err := h.pool.Client(r.Context())
 	if err != nil {
 		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
 		httpErr.Write(w)
 		return
 	}
 
-	defer client.Close()
-
 	var b *booleans.Boolean
 	if b, err = booleans.ToggleBoolean(client, r.Context(), id); err != nil {
 		httpErr, ok := err.(*errors.HTTPError)
@@ -102,35 +102,13 @@ func handleToggleBooleanById(w http.ResponseWriter, r *http.Request, id string)
 	w.WriteHeader(http.StatusOK)
 }
 
-func HandleBooleanById(w http.ResponseWriter, r *http.Request) {
-	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-	id := segments[len(segments)-1]
-
-	if id == "" || id == "booleans" {
-		httpErr := errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
-		httpErr.Write(w)
-		return
-	}
-
+// handleUpdateBooleanById replaces the existing boolean with the ID given in
+// the path, responding with 404 Not Found for an unknown ID.
+func (h *handler) handleUpdateBooleanById(w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
-	params.Del("id")
-	params.Add("id", id)
+	params.Set("id", r.PathValue("id"))
 
 	r.URL.RawQuery = params.Encode()
 
-	switch r.Method {
-	case http.MethodGet:
-		handleGetBooleanById(w, r, id)
-	case http.MethodDelete:
-		handleDeleteBooleanById(w, r, id)
-	case http.MethodPatch:
-		handleToggleBooleanById(w, r, id)
-	case http.MethodPut:
-		handleCreateBoolean(w, r)
-	default:
-		w.Header().Add("Allow", "GET, DELETE, PATCH, PUT")
-
-		httpErr := errors.NewHTTPError(http.StatusMethodNotAllowed, &errors.METHOD_NOT_ALLOWED_ERROR)
-		httpErr.Write(w)
-	}
+	h.handleCreateBoolean(w, r)
 }
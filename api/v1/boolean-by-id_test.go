@@ -24,7 +24,7 @@ const (
 )
 
 func TestHandleBooleanById(t *testing.T) {
-	var client *rdb.Client
+	var client rdb.UniversalClient
 	var container *redis.RedisContainer
 	var err error
 	var server *httptest.Server
@@ -41,8 +41,10 @@ func TestHandleBooleanById(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	server = httptest.NewServer(http.HandlerFunc(v1.HandleBooleanById))
-	if client, err = db.NewRedis(); err != nil {
+	c := test.Config(t)
+
+	server = httptest.NewServer(v1.NewRouter(c, db.NewPool(c)))
+	if client, err = db.NewRedis(test.Config(t)); err != nil {
 		t.Fatal(err)
 	}
 
@@ -87,7 +89,7 @@ func TestHandleBooleanById(t *testing.T) {
 					t.Fatal(err)
 				}
 
-				req.URL.Path = "/api/v1/boolean/" + tt.id
+				req.URL.Path = "/api/v1/booleans/" + tt.id
 				req.URL.RawQuery = "id=" + tt.id
 
 				httpClient := server.Client()
@@ -156,7 +158,7 @@ func TestHandleBooleanById(t *testing.T) {
 					t.Fatal(err)
 				}
 
-				req.URL.Path = "/api/v1/boolean/" + tt.id
+				req.URL.Path = "/api/v1/booleans/" + tt.id
 				req.URL.RawQuery = "id=" + tt.id
 
 				httpClient := server.Client()
@@ -211,7 +213,7 @@ func TestHandleBooleanById(t *testing.T) {
 					t.Fatal(err)
 				}
 
-				req.URL.Path = "/api/v1/boolean/" + tt.id
+				req.URL.Path = "/api/v1/booleans/" + tt.id
 				req.URL.RawQuery = "id=" + tt.id
 
 				httpClient := server.Client()
@@ -300,7 +302,7 @@ func TestHandleBooleanById(t *testing.T) {
 					t.Fatal(err)
 				}
 
-				req.URL.Path = "/api/v1/boolean/" + tt.id
+				req.URL.Path = "/api/v1/booleans/" + tt.id
 				req.URL.RawQuery = "id=" + tt.id
 
 				req.Header.Add("Content-Type", "application/json")
@@ -364,7 +366,7 @@ func TestHandleBooleanById(t *testing.T) {
 					t.Fatal(err)
 				}
 
-				req.URL.Path = "/api/v1/boolean/" + tt.id
+				req.URL.Path = "/api/v1/booleans/" + tt.id
 
 				if tt.id != "" {
 					req.URL.RawQuery = "id=" + tt.id
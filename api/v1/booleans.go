@@ -6,13 +6,16 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	"github.com/saschazar21/go-baas/booleans"
-	"github.com/saschazar21/go-baas/db"
 	"github.com/saschazar21/go-baas/errors"
 )
 
-func handleCreateBoolean(w http.ResponseWriter, r *http.Request) {
+func (h *handler) handleCreateBoolean(w http.ResponseWriter, r *http.Request) {
 	b, err := booleans.ParseBoolean(r)
 
+	if err == nil {
+		err = b.LimitTTL(h.config.MaxTTL, h.config.DefaultTTL)
+	}
+
 	if err != nil {
 		httpErr, ok := err.(*errors.HTTPError)
 
@@ -24,15 +27,13 @@ func handleCreateBoolean(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var client *redis.Client
-	if client, err = db.NewRedis(); err != nil {
+	var client redis.UniversalClient
+	if client, err = h.pool.Client(r.Context()); err != nil {
 		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
 		httpErr.Write(w)
 		return
 	}
 
-	defer client.Close()
-
 	if err = b.Save(client, r.Context()); err != nil {
 		httpErr, ok := err.(*errors.HTTPError)
 
@@ -57,19 +58,56 @@ func handleCreateBoolean(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
-func HandleBooleans(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.Header().Set("Allow", http.MethodPost)
+func (h *handler) handleListBooleans(w http.ResponseWriter, r *http.Request) {
+	params, err := booleans.ParseListParams(r)
+
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	var client redis.UniversalClient
+	if client, err = h.pool.Client(r.Context()); err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+
+	bools, cursor, err := booleans.ListBooleans(client, r.Context(), params)
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
 
-		httpErr := errors.NewHTTPError(http.StatusMethodNotAllowed, &errors.METHOD_NOT_ALLOWED_ERROR)
+	res := booleans.CreateBooleansResponse(bools, cursor)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
 		httpErr.Write(w)
 		return
 	}
+}
 
+// handleNewBoolean creates a boolean with a generated ID.
+func (h *handler) handleNewBoolean(w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
 	params.Del("id")
 
 	r.URL.RawQuery = params.Encode()
 
-	handleCreateBoolean(w, r)
+	h.handleCreateBoolean(w, r)
 }
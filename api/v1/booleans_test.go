@@ -13,6 +13,7 @@ import (
 
 	v1 "github.com/saschazar21/go-baas/api/v1"
 	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/db"
 	"github.com/saschazar21/go-baas/errors"
 	"github.com/saschazar21/go-baas/test"
 	"github.com/stretchr/testify/assert"
@@ -114,7 +115,7 @@ func TestHandleBooleans(t *testing.T) {
 		},
 		{
 			name:       "invalid method",
-			method:     http.MethodGet,
+			method:     http.MethodDelete,
 			parameters: url.Values{},
 			data:       booleans.Boolean{},
 			wantErr:    true,
@@ -130,13 +131,16 @@ func TestHandleBooleans(t *testing.T) {
 				server.Close()
 			})
 
-			server = httptest.NewServer(http.HandlerFunc(v1.HandleBooleans))
+			c := test.Config(t)
+
+			server = httptest.NewServer(v1.NewRouter(c, db.NewPool(c)))
 
 			var u *url.URL
 			if u, err = url.Parse(server.URL); err != nil {
 				t.Fatal(err)
 			}
 
+			u.Path = "/api/v1/booleans"
 			u.RawQuery = tt.parameters.Encode()
 
 			var body []byte
@@ -183,3 +187,188 @@ func TestHandleBooleans(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleBooleanExpiry(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	c := test.Config(t)
+	c.MaxTTL = 2 * time.Hour
+	c.DefaultTTL = time.Hour
+
+	server := httptest.NewServer(v1.NewRouter(c, db.NewPool(c)))
+	t.Cleanup(server.Close)
+
+	future := time.Now().Add(90 * time.Minute).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name  string
+		query string
+		body  string
+		want  int
+	}{
+		{
+			name: "default ttl",
+			body: `{"value":true}`,
+			want: http.StatusOK,
+		},
+		{
+			name:  "duration in query",
+			query: "expires_in=1h30m",
+			body:  `{"value":true}`,
+			want:  http.StatusOK,
+		},
+		{
+			name:  "timestamp in query",
+			query: "expires_at=" + url.QueryEscape(future),
+			body:  `{"value":true}`,
+			want:  http.StatusOK,
+		},
+		{
+			name: "expiry in body",
+			body: `{"value":true,"expires_at":"` + future + `"}`,
+			want: http.StatusOK,
+		},
+		{
+			name:  "body replaces query",
+			query: "expires_in=3h",
+			body:  `{"value":true,"expires_in":"30m"}`,
+			want:  http.StatusOK,
+		},
+		{
+			name: "body beyond max ttl",
+			body: `{"value":true,"expires_in":"3h"}`,
+			want: http.StatusBadRequest,
+		},
+		{
+			name:  "invalid duration",
+			query: "expires_in=later",
+			body:  `{"value":true}`,
+			want:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, server.URL+"/api/v1/booleans?"+tt.query, bytes.NewBufferString(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.want, resp.StatusCode)
+		})
+	}
+}
+
+type booleansResponse struct {
+	Data []struct {
+		Id string `json:"id"`
+		*booleans.Boolean
+	} `json:"data"`
+	Cursor string `json:"cursor"`
+}
+
+func TestHandleListBooleans(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	client, err := db.NewRedis(test.Config(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		client.Close()
+	})
+
+	want := []string{}
+
+	for i := 0; i < 5; i++ {
+		b := booleans.Boolean{Label: fmt.Sprintf("boolean %d", i), Value: i%2 == 0}
+
+		if err = b.Save(client, ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		want = append(want, *b.Id)
+	}
+
+	schedule := booleans.Schedule{BooleanId: want[0], At: time.Now().Unix() + 3600, Toggle: true}
+	if err = schedule.Save(client, ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	c := test.Config(t)
+
+	server := httptest.NewServer(v1.NewRouter(c, db.NewPool(c)))
+
+	t.Cleanup(server.Close)
+
+	t.Run("lists all booleans", func(t *testing.T) {
+		got := []string{}
+		cursor := "0"
+
+		for i := 0; i == 0 || cursor != "0"; i++ {
+			resp, err := server.Client().Get(server.URL + "/api/v1/booleans?count=2&cursor=" + cursor)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var res booleansResponse
+			if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, b := range res.Data {
+				got = append(got, b.Id)
+			}
+
+			cursor = res.Cursor
+		}
+
+		assert.ElementsMatch(t, want, got)
+	})
+
+	t.Run("validates count", func(t *testing.T) {
+		resp, err := server.Client().Get(server.URL + "/api/v1/booleans?count=0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		if resp, err = server.Client().Get(server.URL + "/api/v1/booleans?count=1001"); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
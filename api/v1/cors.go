@@ -0,0 +1,116 @@
+package v1
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/idempotency"
+	"github.com/saschazar21/go-baas/logging"
+)
+
+// CORS_MAX_AGE is the time browsers may cache the result of a preflight
+// request.
+const CORS_MAX_AGE = 10 * time.Minute
+
+// corsHeaders are the request headers used by the API, which are allowed
+// unless configured otherwise.
+var corsHeaders = []string{
+	"Authorization",
+	"Content-Type",
+	logging.REQUEST_ID_HEADER,
+	booleans.LEASE_TOKEN_HEADER,
+	idempotency.HEADER,
+	"traceparent",
+	"tracestate",
+}
+
+// corsExposedHeaders are the response headers readable by browsers, besides
+// the CORS-safelisted ones.
+var corsExposedHeaders = []string{
+	logging.REQUEST_ID_HEADER,
+	RATE_LIMIT_LIMIT_HEADER,
+	RATE_LIMIT_REMAINING_HEADER,
+	RATE_LIMIT_RESET_HEADER,
+	"Retry-After",
+	idempotency.REPLAYED_HEADER,
+	"Content-Disposition",
+	"WWW-Authenticate",
+}
+
+// allowedOrigin reports whether the origin may use the API from browsers.
+func (h *handler) allowedOrigin(origin string) bool {
+	for _, allowed := range h.config.CORS.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// corsMethods returns the methods of the endpoint allowed from browsers.
+func (h *handler) corsMethods(e *endpoint) (methods []string) {
+	for _, method := range e.methods {
+		if len(h.config.CORS.AllowedMethods) == 0 || slices.Contains(h.config.CORS.AllowedMethods, method) {
+			methods = append(methods, method)
+		}
+	}
+
+	return
+}
+
+// cors adds the CORS headers to the responses to allowed origins and answers
+// preflight requests using the methods of the matching endpoint of the mux,
+// without passing them to next, as browsers send them without credentials.
+func (h *handler) cors(mux *http.ServeMux, next http.Handler) http.Handler {
+	if len(h.config.CORS.AllowedOrigins) == 0 {
+		return next
+	}
+
+	headers := corsHeaders
+	if len(h.config.CORS.AllowedHeaders) > 0 {
+		headers = h.config.CORS.AllowedHeaders
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Origin")
+
+		origin := r.Header.Get("Origin")
+		if origin == "" || !h.allowedOrigin(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+
+		if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		handler, pattern := mux.Handler(r)
+
+		e, ok := handler.(*endpoint)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// label the metrics of the preflight request with the route
+		r.Pattern = pattern
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		w.Header().Set("Allow", e.allow())
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(h.corsMethods(e), ", "))
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+		w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(CORS_MAX_AGE.Seconds())))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,86 @@
+package v1_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	c := config.Default()
+	c.Auth.APIKeys = []string{"secret"}
+	c.CORS.AllowedOrigins = []string{"https://example.com/"}
+	c.CORS.AllowedMethods = []string{http.MethodGet, http.MethodPatch}
+
+	router := v1.NewRouter(c, db.NewPool(c))
+
+	serve := func(method, path string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("answers preflight requests", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "/api/v1/booleans/"+BOOLEAN_TEST_ID, map[string]string{
+			"Origin":                         "https://example.com",
+			"Access-Control-Request-Method":  http.MethodPatch,
+			"Access-Control-Request-Headers": "authorization",
+		})
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "https://example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET, PATCH", rec.Header().Get("Access-Control-Allow-Methods"))
+		assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+		assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+		assert.Contains(t, rec.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("derives methods from the route", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "/api/v1/booleans/"+BOOLEAN_TEST_ID+"/evaluate", map[string]string{
+			"Origin":                        "https://example.com",
+			"Access-Control-Request-Method": http.MethodGet,
+		})
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "GET", rec.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("rejects unknown origins", func(t *testing.T) {
+		rec := serve(http.MethodOptions, "/api/v1/booleans", map[string]string{
+			"Origin":                        "https://evil.example.com",
+			"Access-Control-Request-Method": http.MethodGet,
+		})
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, rec.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("adds headers to responses", func(t *testing.T) {
+		rec := serve(http.MethodGet, v1.HEALTH_PATH, map[string]string{"Origin": "https://example.com"})
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "https://example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "X-Request-ID")
+		assert.Contains(t, rec.Header().Values("Vary"), "Origin")
+	})
+
+	t.Run("answers options without origin", func(t *testing.T) {
+		rec := serve(http.MethodOptions, v1.HEALTH_PATH, nil)
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "GET, HEAD, OPTIONS", rec.Header().Get("Allow"))
+		assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
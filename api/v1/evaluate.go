@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/errors"
+)
+
+func (h *handler) handleEvaluateBoolean(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	params, err := booleans.ParseEvaluationParams(r)
+
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	client, err := h.pool.Client(r.Context())
+	if err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+
+	e, err := booleans.EvaluateBoolean(client, r.Context(), id, params.Subject)
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	res := booleans.CreateEvaluationResponse(e)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+}
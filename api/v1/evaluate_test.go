@@ -0,0 +1,133 @@
+package v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	rdb "github.com/redis/go-redis/v9"
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+type evaluationResponse struct {
+	Data booleans.Evaluation `json:"data"`
+}
+
+func TestHandleEvaluate(t *testing.T) {
+	var client rdb.UniversalClient
+	var container *redis.RedisContainer
+	var err error
+	var server *httptest.Server
+
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+		test.TerminateContainer(container, t)
+	})
+
+	if container, err = test.CreateContainer(ctx, t); err != nil {
+		t.Fatal(err)
+	}
+
+	c := test.Config(t)
+
+	server = httptest.NewServer(v1.NewRouter(c, db.NewPool(c)))
+	if client, err = db.NewRedis(test.Config(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = client.HSet(ctx, BOOLEAN_TEST_ID, &booleans.Boolean{
+		Label: BOOLEAN_TEST_ID,
+		Value: true,
+		Rollout: &booleans.Rollout{
+			Allow: []string{"user-42"},
+		},
+	}).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		id     string
+		query  string
+		want   int
+		value  bool
+		reason string
+	}{
+		{
+			name:   "evaluate allow-listed subject",
+			method: http.MethodGet,
+			id:     BOOLEAN_TEST_ID,
+			query:  "subject=user-42",
+			want:   http.StatusOK,
+			value:  true,
+			reason: booleans.EVALUATION_REASON_ALLOWED,
+		},
+		{
+			name:   "evaluate other subject",
+			method: http.MethodGet,
+			id:     BOOLEAN_TEST_ID,
+			query:  "subject=user-1",
+			want:   http.StatusOK,
+			value:  false,
+			reason: booleans.EVALUATION_REASON_ROLLOUT,
+		},
+		{
+			name:   "evaluate without subject",
+			method: http.MethodGet,
+			id:     BOOLEAN_TEST_ID,
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:   "evaluate inexistent boolean",
+			method: http.MethodGet,
+			id:     "inexistentId",
+			query:  "subject=user-42",
+			want:   http.StatusNotFound,
+		},
+		{
+			name:   "unsupported method",
+			method: http.MethodPost,
+			id:     BOOLEAN_TEST_ID,
+			query:  "subject=user-42",
+			want:   http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+"/api/v1/booleans/"+tt.id+"/evaluate?"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, res.StatusCode)
+
+			if res.StatusCode == http.StatusOK {
+				var e evaluationResponse
+				if err = json.NewDecoder(res.Body).Decode(&e); err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, tt.id, e.Data.Id)
+				assert.Equal(t, tt.value, e.Data.Value)
+				assert.Equal(t, tt.reason, e.Data.Reason)
+			}
+		})
+	}
+}
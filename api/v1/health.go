@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/saschazar21/go-baas/errors"
+	"github.com/saschazar21/go-baas/version"
+)
+
+const (
+	HEALTH_PATH    = "/healthz"
+	READINESS_PATH = "/readyz"
+	VERSION_PATH   = "/version"
+)
+
+// READINESS_TIMEOUT limits the time to wait for the store to answer PING.
+const READINESS_TIMEOUT = 2 * time.Second
+
+const HEALTH_STATUS_OK = "ok"
+
+// publicPaths are served without authentication, so that load balancers and
+// uptime monitors may check the service.
+var publicPaths = map[string]bool{
+	HEALTH_PATH:    true,
+	READINESS_PATH: true,
+	VERSION_PATH:   true,
+}
+
+type Check struct {
+	Status     string `json:"status"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+type Health struct {
+	Status string            `json:"status"`
+	Checks map[string]*Check `json:"checks,omitempty"`
+}
+
+type healthResponse struct {
+	Data *Health `json:"data"`
+}
+
+type versionResponse struct {
+	Data *version.Info `json:"data"`
+}
+
+func writeJSON(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+	}
+}
+
+// handleHealth reports that the process is alive, without checking the
+// store.
+func (h *handler) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, &healthResponse{Data: &Health{Status: HEALTH_STATUS_OK}})
+}
+
+// handleReadiness reports whether the store answers PING within
+// READINESS_TIMEOUT, otherwise it responds with 503 Service Unavailable.
+func (h *handler) handleReadiness(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), READINESS_TIMEOUT)
+	defer cancel()
+
+	start := time.Now()
+
+	err := h.ping(ctx)
+
+	if err != nil {
+		slog.WarnContext(ctx, "readiness check failed", "error", err)
+
+		httpErr := errors.NewHTTPError(http.StatusServiceUnavailable, &[]errors.ErrorContent{
+			{
+				Status: http.StatusServiceUnavailable,
+				Title:  "Service Unavailable",
+				Detail: "The store is not reachable",
+			},
+		})
+		httpErr.Write(w)
+		return
+	}
+
+	writeJSON(w, &healthResponse{Data: &Health{
+		Status: HEALTH_STATUS_OK,
+		Checks: map[string]*Check{
+			"redis": {Status: HEALTH_STATUS_OK, DurationMs: time.Since(start).Milliseconds()},
+		},
+	}})
+}
+
+// ping checks the store, as the pool only checks its client periodically.
+func (h *handler) ping(ctx context.Context) error {
+	client, err := h.pool.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	return client.Ping(ctx).Err()
+}
+
+func (h *handler) handleVersion(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, &versionResponse{Data: version.Get()})
+}
@@ -0,0 +1,120 @@
+package v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/errors"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/saschazar21/go-baas/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+type healthResponse struct {
+	Data v1.Health `json:"data"`
+}
+
+type versionResponse struct {
+	Data version.Info `json:"data"`
+}
+
+func TestHealth(t *testing.T) {
+	c := config.Default()
+	c.Redis.URL = "redis://127.0.0.1:1"
+	c.Auth.APIKeys = []string{"secret"}
+
+	router := v1.NewRouter(c, db.NewPool(c))
+
+	t.Run("healthz", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, v1.HEALTH_PATH, nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var res healthResponse
+		if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, v1.HEALTH_STATUS_OK, res.Data.Status)
+	})
+
+	t.Run("readyz without redis", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, v1.READINESS_PATH, nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+		var res errors.HTTPError
+		if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		if assert.NotNil(t, res.Errors) && assert.Len(t, *res.Errors, 1) {
+			assert.Equal(t, "The store is not reachable", (*res.Errors)[0].Detail)
+		}
+	})
+
+	t.Run("version", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, v1.VERSION_PATH, nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var res versionResponse
+		if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, version.Version, res.Data.Version)
+		assert.NotEmpty(t, res.Data.Commit)
+		assert.NotEmpty(t, res.Data.GoVersion)
+	})
+
+	t.Run("method not allowed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, v1.HEALTH_PATH, nil))
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
+
+func TestReadiness(t *testing.T) {
+	var container *redis.RedisContainer
+	var err error
+
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	if container, err = test.CreateContainer(ctx, t); err != nil {
+		t.Fatal(err)
+	}
+
+	c := test.Config(t)
+
+	rec := httptest.NewRecorder()
+	v1.NewRouter(c, db.NewPool(c)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, v1.READINESS_PATH, nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var res healthResponse
+	if err = json.NewDecoder(rec.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, v1.HEALTH_STATUS_OK, res.Data.Status)
+
+	if assert.Contains(t, res.Data.Checks, "redis") {
+		assert.Equal(t, v1.HEALTH_STATUS_OK, res.Data.Checks["redis"].Status)
+	}
+}
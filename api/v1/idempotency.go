@@ -0,0 +1,151 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/saschazar21/go-baas/errors"
+	"github.com/saschazar21/go-baas/idempotency"
+)
+
+// responseRecorder records the status code and body written by a handler.
+type responseRecorder struct {
+	statusRecorder
+
+	body bytes.Buffer
+}
+
+func (rec *responseRecorder) Write(data []byte) (int, error) {
+	rec.body.Write(data)
+
+	return rec.statusRecorder.Write(data)
+}
+
+func idempotencyError(status int, detail string) *errors.HTTPError {
+	return errors.NewHTTPError(status, &[]errors.ErrorContent{
+		{
+			Status: status,
+			Title:  http.StatusText(status),
+			Detail: detail,
+		},
+	})
+}
+
+// idempotent stores the response to requests with an Idempotency-Key header
+// and replays it on retries, which carry the same key. Reusing a key for a
+// different request is rejected with 422 Unprocessable Entity, retrying
+// while the first request is in progress with 409 Conflict. Server errors are
+// not stored, so that the request may be retried.
+func (h *handler) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotency.HEADER)
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		ctx := r.Context()
+
+		if !idempotency.ValidKey(key) {
+			slog.DebugContext(ctx, "invalid idempotency key")
+
+			httpErr := idempotencyError(http.StatusBadRequest, "Idempotency-Key must be printable ASCII of up to "+strconv.Itoa(idempotency.MAX_KEY_LENGTH)+" characters")
+			httpErr.Write(w)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			slog.DebugContext(ctx, "failed to read body", "error", err)
+
+			httpErr := errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+			if errors.IsBodyTooLarge(err) {
+				httpErr = errors.NewHTTPError(http.StatusRequestEntityTooLarge, &errors.REQUEST_ENTITY_TOO_LARGE_ERROR)
+			}
+
+			httpErr.Write(w)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		client, err := h.pool.Client(ctx)
+		if err != nil {
+			httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+			httpErr.Write(w)
+			return
+		}
+
+		storeKey := idempotency.Key(h.clientId(r), key)
+		fingerprint := idempotency.Fingerprint(r, body)
+
+		stored, err := idempotency.Begin(client, ctx, storeKey, fingerprint)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to look up idempotency key", "error", err)
+
+			httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+			httpErr.Write(w)
+			return
+		}
+
+		switch {
+		case stored == nil:
+		case stored.Fingerprint != fingerprint:
+			slog.DebugContext(ctx, "idempotency key reused for a different request")
+
+			httpErr := idempotencyError(http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+			httpErr.Write(w)
+			return
+		case stored.Status == 0:
+			slog.DebugContext(ctx, "request with idempotency key in progress")
+
+			httpErr := idempotencyError(http.StatusConflict, "a request with the same Idempotency-Key is in progress")
+			httpErr.SetHeader("Retry-After", "1")
+			httpErr.Write(w)
+			return
+		default:
+			slog.DebugContext(ctx, "replaying response of idempotency key", "status", stored.Status)
+
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+
+			w.Header().Set(idempotency.REPLAYED_HEADER, "true")
+			w.WriteHeader(stored.Status)
+			w.Write(stored.Body)
+			return
+		}
+
+		rec := &responseRecorder{statusRecorder: statusRecorder{ResponseWriter: w}}
+
+		next(rec, r)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		// the response is stored, even if the client went away in the meantime
+		ctx = context.WithoutCancel(ctx)
+
+		if rec.status >= http.StatusInternalServerError {
+			if err = idempotency.Abort(client, ctx, storeKey); err != nil {
+				slog.ErrorContext(ctx, "failed to release idempotency key", "error", err)
+			}
+
+			return
+		}
+
+		if err = idempotency.Save(client, ctx, storeKey, &idempotency.Response{
+			Fingerprint: fingerprint,
+			Status:      rec.status,
+			ContentType: w.Header().Get("Content-Type"),
+			Body:        rec.body.Bytes(),
+		}, h.config.Idempotency.TTL); err != nil {
+			slog.ErrorContext(ctx, "failed to store response of idempotency key", "error", err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/idempotency"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	c := test.Config(t)
+
+	router := v1.NewRouter(c, db.NewPool(c))
+
+	create := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/booleans", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		if key != "" {
+			req.Header.Set(idempotency.HEADER, key)
+		}
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	id := func(rec *httptest.ResponseRecorder) string {
+		var res booleanResponse
+		if err := json.NewDecoder(rec.Body).Decode(&res); err != nil {
+			t.Fatal(err)
+		}
+
+		return res.Data.Id
+	}
+
+	t.Run("replays the first response", func(t *testing.T) {
+		first := create("deploy-42", `{"label":"deploy"}`)
+
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Empty(t, first.Header().Get(idempotency.REPLAYED_HEADER))
+
+		body := first.Body.String()
+
+		retry := create("deploy-42", `{"label":"deploy"}`)
+
+		assert.Equal(t, http.StatusOK, retry.Code)
+		assert.Equal(t, "true", retry.Header().Get(idempotency.REPLAYED_HEADER))
+		assert.Equal(t, "application/json", retry.Header().Get("Content-Type"))
+		assert.Equal(t, body, retry.Body.String())
+	})
+
+	t.Run("rejects a different request", func(t *testing.T) {
+		rec := create("deploy-42", `{"label":"rollback"}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	})
+
+	t.Run("rejects retries in progress", func(t *testing.T) {
+		client, err := db.NewRedis(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer client.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/booleans", strings.NewReader(`{"label":"deploy"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		if _, err = idempotency.Begin(client, ctx, idempotency.Key("ip:192.0.2.1", "deploy-43"), idempotency.Fingerprint(req, []byte(`{"label":"deploy"}`))); err != nil {
+			t.Fatal(err)
+		}
+
+		rec := create("deploy-43", `{"label":"deploy"}`)
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("rejects invalid keys", func(t *testing.T) {
+		rec := create(strings.Repeat("a", idempotency.MAX_KEY_LENGTH+1), `{"label":"deploy"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("creates without key", func(t *testing.T) {
+		assert.NotEqual(t, id(create("", `{"label":"deploy"}`)), id(create("", `{"label":"deploy"}`)))
+	})
+}
@@ -0,0 +1,146 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/errors"
+)
+
+func (h *handler) handleAcquireLease(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	params, err := booleans.ParseLeaseParams(r)
+
+	if err == nil {
+		err = params.LimitLeaseTTL(h.config.MaxTTL)
+	}
+
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	client, err := h.pool.Client(r.Context())
+	if err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+
+	l, err := booleans.AcquireLease(client, r.Context(), id, params)
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	res := booleans.CreateLeaseResponse(l)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+}
+
+func (h *handler) handleRenewLease(w http.ResponseWriter, r *http.Request) {
+	id, token := r.PathValue("id"), r.Header.Get(booleans.LEASE_TOKEN_HEADER)
+
+	if token == "" {
+		httpErr := errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+		httpErr.Write(w)
+		return
+	}
+
+	params, err := booleans.ParseLeaseParams(r)
+
+	if err == nil {
+		err = params.LimitLeaseTTL(h.config.MaxTTL)
+	}
+
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	client, err := h.pool.Client(r.Context())
+	if err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+
+	l, err := booleans.RenewLease(client, r.Context(), id, token, params)
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	res := booleans.CreateLeaseResponse(l)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+}
+
+func (h *handler) handleReleaseLease(w http.ResponseWriter, r *http.Request) {
+	id, token := r.PathValue("id"), r.Header.Get(booleans.LEASE_TOKEN_HEADER)
+
+	if token == "" {
+		httpErr := errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+		httpErr.Write(w)
+		return
+	}
+
+	client, err := h.pool.Client(r.Context())
+	if err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+
+	if err := booleans.ReleaseLease(client, r.Context(), id, token); err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
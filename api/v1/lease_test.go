@@ -0,0 +1,167 @@
+package v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	rdb "github.com/redis/go-redis/v9"
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+type leaseResponse struct {
+	Data booleans.Lease `json:"data"`
+}
+
+func TestHandleLease(t *testing.T) {
+	var client rdb.UniversalClient
+	var container *redis.RedisContainer
+	var err error
+	var server *httptest.Server
+
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+		test.TerminateContainer(container, t)
+	})
+
+	if container, err = test.CreateContainer(ctx, t); err != nil {
+		t.Fatal(err)
+	}
+
+	c := test.Config(t)
+	c.MaxTTL = time.Hour
+
+	server = httptest.NewServer(v1.NewRouter(c, db.NewPool(c)))
+	if client, err = db.NewRedis(test.Config(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = client.HSet(ctx, BOOLEAN_TEST_ID, &booleans.Boolean{
+		Label: BOOLEAN_TEST_ID,
+		Value: false,
+	}).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var token string
+
+	tests := []struct {
+		name   string
+		method string
+		id     string
+		query  string
+		token  func() string
+		want   int
+	}{
+		{
+			name:   "acquire lease beyond the maximum TTL",
+			method: http.MethodPost,
+			id:     BOOLEAN_TEST_ID,
+			query:  "expires_in=7200",
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:   "acquire lease",
+			method: http.MethodPost,
+			id:     BOOLEAN_TEST_ID,
+			query:  "expires_in=30",
+			want:   http.StatusCreated,
+		},
+		{
+			name:   "acquire held lease",
+			method: http.MethodPost,
+			id:     BOOLEAN_TEST_ID,
+			want:   http.StatusConflict,
+		},
+		{
+			name:   "acquire lease of inexistent boolean",
+			method: http.MethodPost,
+			id:     "inexistentId",
+			want:   http.StatusNotFound,
+		},
+		{
+			name:   "renew lease without token",
+			method: http.MethodPut,
+			id:     BOOLEAN_TEST_ID,
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:   "renew lease with foreign token",
+			method: http.MethodPut,
+			id:     BOOLEAN_TEST_ID,
+			token:  func() string { return "foreign" },
+			want:   http.StatusConflict,
+		},
+		{
+			name:   "renew lease",
+			method: http.MethodPut,
+			id:     BOOLEAN_TEST_ID,
+			query:  "expires_in=60",
+			token:  func() string { return token },
+			want:   http.StatusOK,
+		},
+		{
+			name:   "release lease",
+			method: http.MethodDelete,
+			id:     BOOLEAN_TEST_ID,
+			token:  func() string { return token },
+			want:   http.StatusNoContent,
+		},
+		{
+			name:   "release released lease",
+			method: http.MethodDelete,
+			id:     BOOLEAN_TEST_ID,
+			token:  func() string { return token },
+			want:   http.StatusConflict,
+		},
+		{
+			name:   "unsupported method",
+			method: http.MethodPatch,
+			id:     BOOLEAN_TEST_ID,
+			want:   http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+"/api/v1/booleans/"+tt.id+"/lease?"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.token != nil {
+				req.Header.Set(booleans.LEASE_TOKEN_HEADER, tt.token())
+			}
+
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.want, res.StatusCode)
+
+			if res.StatusCode == http.StatusCreated {
+				var l leaseResponse
+				if err = json.NewDecoder(res.Body).Decode(&l); err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, tt.id, l.Data.Id)
+				assert.NotEmpty(t, l.Data.Token)
+
+				token = l.Data.Token
+			}
+		})
+	}
+}
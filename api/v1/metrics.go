@@ -0,0 +1,159 @@
+package v1
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/metrics"
+)
+
+// STATS_TIMEOUT limits the time spent scanning the database per scrape.
+const STATS_TIMEOUT = 10 * time.Second
+
+// BOOLEANS_COUNT_INTERVAL is the time the amount of stored booleans is cached
+// for, as counting them scans the whole database.
+const BOOLEANS_COUNT_INTERVAL = time.Minute
+
+// UNMATCHED_ROUTE labels requests, which matched no route.
+const UNMATCHED_ROUTE = "unmatched"
+
+// knownMethods are used as labels as is, other methods are labelled OTHER to
+// bound the cardinality of the metrics.
+var knownMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true,
+	http.MethodPut: true, http.MethodPatch: true, http.MethodDelete: true,
+	http.MethodOptions: true,
+}
+
+// instrument counts the requests and observes their latency by route
+// pattern, method and status code.
+func (h *handler) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w}
+		start := time.Now()
+
+		next.ServeHTTP(rec, r)
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		// the pattern is set by the ServeMux on the request it receives
+		route := r.Pattern
+		if route == "" || route == "/" {
+			route = UNMATCHED_ROUTE
+		}
+
+		method := r.Method
+		if !knownMethods[method] {
+			method = "OTHER"
+		}
+
+		status := strconv.Itoa(rec.status)
+
+		metrics.Requests.WithLabelValues(route, method, status).Inc()
+		metrics.RequestDuration.WithLabelValues(route, method, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+// storeCollector reads the amount of stored and expired booleans from Redis.
+// The amount of stored booleans is counted at most once per
+// BOOLEANS_COUNT_INTERVAL.
+type storeCollector struct {
+	pool *db.Pool
+
+	booleans *prometheus.Desc
+	expired  *prometheus.Desc
+
+	mu        sync.Mutex
+	count     int64
+	countedAt time.Time
+}
+
+func newStoreCollector(pool *db.Pool) *storeCollector {
+	return &storeCollector{
+		pool:     pool,
+		booleans: prometheus.NewDesc(metrics.NAMESPACE+"_booleans", "Stored booleans.", nil, nil),
+		expired:  prometheus.NewDesc(metrics.NAMESPACE+"_booleans_expired_total", "Booleans reported as expired by the expiry-events worker.", nil, nil),
+	}
+}
+
+// countBooleans returns the cached amount of stored booleans, which is
+// counted again once it is older than BOOLEANS_COUNT_INTERVAL.
+func (c *storeCollector) countBooleans(client redis.UniversalClient, ctx context.Context) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.countedAt.IsZero() && time.Since(c.countedAt) < BOOLEANS_COUNT_INTERVAL {
+		return c.count, nil
+	}
+
+	count, err := booleans.CountBooleans(client, ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	c.count, c.countedAt = count, time.Now()
+
+	return count, nil
+}
+
+func (c *storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.booleans
+	ch <- c.expired
+}
+
+func (c *storeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), STATS_TIMEOUT)
+	defer cancel()
+
+	client, err := c.pool.Client(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.booleans, err)
+		ch <- prometheus.NewInvalidMetric(c.expired, err)
+		return
+	}
+
+	if count, err := c.countBooleans(client, ctx); err != nil {
+		slog.ErrorContext(ctx, "failed to count booleans", "error", err)
+
+		ch <- prometheus.NewInvalidMetric(c.booleans, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(c.booleans, prometheus.GaugeValue, float64(count))
+	}
+
+	if expired, err := booleans.ExpiredBooleans(client, ctx); err != nil {
+		slog.ErrorContext(ctx, "failed to read expired booleans", "error", err)
+
+		ch <- prometheus.NewInvalidMetric(c.expired, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(c.expired, prometheus.CounterValue, float64(expired))
+	}
+}
+
+// NewMetricsHandler returns the handler exposing the metrics in the
+// Prometheus text format, along with the runtime metrics of the process and
+// the amount of stored booleans, read using the client of the given pool.
+// Metrics failing to be collected are omitted.
+func NewMetricsHandler(pool *db.Pool) http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		newStoreCollector(pool),
+	)
+
+	return promhttp.HandlerFor(prometheus.Gatherers{metrics.Registry, registry}, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+	})
+}
@@ -0,0 +1,104 @@
+package v1_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	rdb "github.com/redis/go-redis/v9"
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/metrics"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+func TestMetrics(t *testing.T) {
+	var client rdb.UniversalClient
+	var container *redis.RedisContainer
+	var err error
+	var server *httptest.Server
+
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+		test.TerminateContainer(container, t)
+	})
+
+	if container, err = test.CreateContainer(ctx, t); err != nil {
+		t.Fatal(err)
+	}
+
+	c := test.Config(t)
+	pool := db.NewPool(c)
+
+	server = httptest.NewServer(v1.NewRouter(c, pool))
+	if client, err = db.NewRedis(test.Config(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	metrics.Reset()
+
+	for _, id := range []string{BOOLEAN_TEST_ID, "second"} {
+		if err = client.HSet(ctx, id, &booleans.Boolean{Value: true}).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, req := range []struct {
+		method string
+		path   string
+	}{
+		{http.MethodGet, "/api/v1/booleans/" + BOOLEAN_TEST_ID},
+		{http.MethodPatch, "/api/v1/booleans/" + BOOLEAN_TEST_ID},
+		{http.MethodGet, "/api/v1/unknown"},
+		{"PURGE", "/api/v1/unknown"},
+	} {
+		r, err := http.NewRequest(req.method, server.URL+req.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res.Body.Close()
+	}
+
+	// the strict context must not hide the route from the middlewares
+	strict := test.Config(t)
+	strict.StrictJSON = true
+
+	v1.NewRouter(strict, pool).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, v1.HEALTH_PATH, nil))
+
+	w := httptest.NewRecorder()
+	v1.NewMetricsHandler(pool).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	body, _ := io.ReadAll(w.Body)
+
+	for _, want := range []string{
+		`baas_http_requests_total{method="GET",route="/api/v1/booleans/{id}",status="200"} 1`,
+		`baas_http_requests_total{method="PATCH",route="/api/v1/booleans/{id}",status="200"} 1`,
+		`baas_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`baas_http_requests_total{method="OTHER",route="unmatched",status="404"} 1`,
+		`baas_http_requests_total{method="GET",route="` + v1.HEALTH_PATH + `",status="200"} 1`,
+		`baas_http_request_duration_seconds_count{method="GET",route="/api/v1/booleans/{id}",status="200"} 1`,
+		`baas_redis_command_duration_seconds_count{command="hgetall"}`,
+		`baas_boolean_operations_total{operation="toggle"} 1`,
+		"baas_booleans 2",
+		"baas_booleans_expired_total 0",
+		"go_goroutines",
+	} {
+		assert.Contains(t, string(body), want)
+	}
+}
@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/errors"
+	"github.com/saschazar21/go-baas/metrics"
+	"github.com/saschazar21/go-baas/ratelimit"
+)
+
+const (
+	RATE_LIMIT_LIMIT_HEADER     = "RateLimit-Limit"
+	RATE_LIMIT_REMAINING_HEADER = "RateLimit-Remaining"
+	RATE_LIMIT_RESET_HEADER     = "RateLimit-Reset"
+)
+
+// seconds rounds the duration up to whole seconds, as used by the headers.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// clientId identifies the client by its API key, once API keys are
+// configured, as the requests passed authentication, otherwise by its IP.
+func (h *handler) clientId(r *http.Request) string {
+	if len(h.config.Auth.APIKeys) > 0 {
+		return "key:" + bearerToken(r)
+	}
+
+	// the Lambda adapter sets the source IP without port
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	return "ip:" + ip
+}
+
+// take takes a token from the bucket at key and reports the closest limit in
+// the RateLimit headers. If the request is rejected, it responds with 429
+// Too Many Requests and returns false. Requests are allowed, if Redis
+// fails, as the subsequent handler fails as well.
+func (h *handler) take(w http.ResponseWriter, r *http.Request, scope, key string, limit config.Limit) bool {
+	ctx := r.Context()
+
+	client, err := h.pool.Client(ctx)
+	if err != nil {
+		return true
+	}
+
+	res, err := ratelimit.Take(client, ctx, key, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to apply rate limit", "scope", scope, "error", err)
+
+		return true
+	}
+
+	header := w.Header()
+
+	if remaining, err := strconv.Atoi(header.Get(RATE_LIMIT_REMAINING_HEADER)); err != nil || res.Remaining < remaining {
+		header.Set(RATE_LIMIT_LIMIT_HEADER, strconv.Itoa(res.Limit))
+		header.Set(RATE_LIMIT_REMAINING_HEADER, strconv.Itoa(res.Remaining))
+		header.Set(RATE_LIMIT_RESET_HEADER, seconds(res.Reset))
+	}
+
+	if res.Allowed {
+		return true
+	}
+
+	slog.DebugContext(ctx, "rate limit exceeded", "scope", scope, "retry_after", res.RetryAfter)
+
+	metrics.RateLimited.WithLabelValues(scope).Inc()
+
+	httpErr := errors.NewHTTPError(http.StatusTooManyRequests, &errors.TOO_MANY_REQUESTS_ERROR)
+	httpErr.SetHeader(RATE_LIMIT_LIMIT_HEADER, strconv.Itoa(res.Limit))
+	httpErr.SetHeader(RATE_LIMIT_REMAINING_HEADER, strconv.Itoa(res.Remaining))
+	httpErr.SetHeader(RATE_LIMIT_RESET_HEADER, seconds(res.Reset))
+	httpErr.SetHeader("Retry-After", seconds(res.RetryAfter))
+	httpErr.Write(w)
+
+	return false
+}
+
+// limitClient limits the requests of every client, except the health checks.
+func (h *handler) limitClient(next http.Handler) http.Handler {
+	if !h.config.RateLimit.Client.Enabled() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !publicPaths[r.URL.Path] && !h.take(w, r, ratelimit.SCOPE_CLIENT, ratelimit.ClientKey(h.clientId(r)), h.config.RateLimit.Client) {
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitBoolean limits the writes to the boolean given in the path, regardless
+// of the client.
+func (h *handler) limitBoolean(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.config.RateLimit.Boolean.Enabled() && !h.take(w, r, ratelimit.SCOPE_BOOLEAN, ratelimit.BooleanKey(r.PathValue("id")), h.config.RateLimit.Boolean) {
+			return
+		}
+
+		next(w, r)
+	}
+}
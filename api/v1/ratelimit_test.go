@@ -0,0 +1,105 @@
+package v1_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	rdb "github.com/redis/go-redis/v9"
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+func TestRateLimit(t *testing.T) {
+	var client rdb.UniversalClient
+	var container *redis.RedisContainer
+	var err error
+
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		client.Close()
+		test.TerminateContainer(container, t)
+	})
+
+	if container, err = test.CreateContainer(ctx, t); err != nil {
+		t.Fatal(err)
+	}
+
+	if client, err = db.NewRedis(test.Config(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"first", "second"} {
+		if err = client.HSet(ctx, id, &booleans.Boolean{Label: id, Value: true}).Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := test.Config(t)
+	c.RateLimit.Client = config.Limit{Rate: 3, Period: time.Hour}
+	c.RateLimit.Boolean = config.Limit{Rate: 1, Period: time.Hour}
+
+	router := v1.NewRouter(c, db.NewPool(c))
+
+	serve := func(method, path, remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = remoteAddr
+
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("limits writes per boolean", func(t *testing.T) {
+		rec := serve(http.MethodPatch, "/api/v1/booleans/first", "192.0.2.1:1234")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get(v1.RATE_LIMIT_LIMIT_HEADER))
+		assert.Equal(t, "0", rec.Header().Get(v1.RATE_LIMIT_REMAINING_HEADER))
+
+		rec = serve(http.MethodPatch, "/api/v1/booleans/first", "192.0.2.2:1234")
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "3600", rec.Header().Get("Retry-After"))
+		assert.Equal(t, "3600", rec.Header().Get(v1.RATE_LIMIT_RESET_HEADER))
+		assert.Contains(t, rec.Body.String(), "Too Many Requests")
+
+		rec = serve(http.MethodGet, "/api/v1/booleans/first", "192.0.2.2:1234")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("limits requests per client", func(t *testing.T) {
+		// the first request of the client was a write
+		for remaining := 1; remaining >= 0; remaining-- {
+			rec := serve(http.MethodGet, "/api/v1/booleans/second", "192.0.2.1:1234")
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "3", rec.Header().Get(v1.RATE_LIMIT_LIMIT_HEADER))
+			assert.Equal(t, strconv.Itoa(remaining), rec.Header().Get(v1.RATE_LIMIT_REMAINING_HEADER))
+		}
+
+		rec := serve(http.MethodGet, "/api/v1/booleans/second", "192.0.2.1:1234")
+
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+
+		rec = serve(http.MethodGet, "/api/v1/booleans/second", "192.0.2.3:1234")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = serve(http.MethodGet, v1.HEALTH_PATH, "192.0.2.1:1234")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -0,0 +1,80 @@
+package v1
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/logging"
+)
+
+// statusRecorder records the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(data []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+
+	return s.ResponseWriter.Write(data)
+}
+
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// identify adopts the X-Request-ID header of the request, or generates a new
+// ID, echoes it in the response, attaches it to the context for logging and
+// logs the outcome of the request. With StrictJSON configured, unknown fields
+// of JSON bodies are rejected as well. Middlewares between trace and the
+// ServeMux must not replace the request, as the route pattern is set on the
+// request the ServeMux receives.
+func (h *handler) identify(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(logging.REQUEST_ID_HEADER)
+		if !logging.ValidRequestID(id) {
+			id = logging.NewRequestID()
+		}
+
+		w.Header().Set(logging.REQUEST_ID_HEADER, id)
+
+		ctx := logging.WithRequestID(r.Context(), id)
+		if h.config.StrictJSON {
+			ctx = booleans.WithStrictJSON(ctx, true)
+		}
+
+		rec := &statusRecorder{ResponseWriter: w}
+		start := time.Now()
+
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		level := slog.LevelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		slog.Log(ctx, level, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+	})
+}
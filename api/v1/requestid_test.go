@@ -0,0 +1,97 @@
+package v1_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/errors"
+	"github.com/saschazar21/go-baas/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	c := config.Default()
+
+	router := v1.NewRouter(c, db.NewPool(c))
+
+	var buf bytes.Buffer
+
+	logger := slog.Default()
+	slog.SetDefault(logging.New(&buf, slog.LevelInfo))
+
+	t.Cleanup(func() {
+		slog.SetDefault(logger)
+	})
+
+	tests := []struct {
+		name      string
+		requestId string
+		generated bool
+	}{
+		{
+			name:      "generates missing request ID",
+			generated: true,
+		},
+		{
+			name:      "propagates request ID",
+			requestId: "client-supplied.ID_42",
+		},
+		{
+			name:      "replaces request ID with whitespace",
+			requestId: "client supplied",
+			generated: true,
+		},
+		{
+			name:      "replaces overlong request ID",
+			requestId: strings.Repeat("a", logging.MAX_REQUEST_ID_LENGTH+1),
+			generated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/unknown", nil)
+			if tt.requestId != "" {
+				req.Header.Set(logging.REQUEST_ID_HEADER, tt.requestId)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			id := w.Header().Get(logging.REQUEST_ID_HEADER)
+
+			if tt.generated {
+				assert.Len(t, id, 32)
+				assert.NotEqual(t, tt.requestId, id)
+			} else {
+				assert.Equal(t, tt.requestId, id)
+			}
+
+			var httpErr errors.HTTPError
+			if err := json.NewDecoder(w.Body).Decode(&httpErr); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, id, httpErr.RequestID)
+
+			var record map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, "request", record["msg"])
+			assert.Equal(t, id, record[logging.REQUEST_ID_KEY])
+			assert.EqualValues(t, http.StatusNotFound, record["status"])
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/errors"
+)
+
+// route binds a handler to a method and a http.ServeMux path pattern.
+type route struct {
+	method  string
+	pattern string
+	handler http.HandlerFunc
+}
+
+// handler holds the dependencies of the endpoints.
+type handler struct {
+	config *config.Config
+	pool   *db.Pool
+}
+
+// routes lists all endpoints of the API, path wildcards are accessed using
+// r.PathValue. Writes to a boolean are subject to its rate limit, creates
+// may be retried using an Idempotency-Key.
+func (h *handler) routes() []route {
+	return []route{
+		{http.MethodGet, "/api/v1/booleans", h.handleListBooleans},
+		{http.MethodPost, "/api/v1/booleans", h.idempotent(h.handleNewBoolean)},
+		{http.MethodGet, "/api/v1/booleans/{id}", h.handleGetBooleanById},
+		{http.MethodPut, "/api/v1/booleans/{id}", h.limitBoolean(h.handleUpdateBooleanById)},
+		{http.MethodPatch, "/api/v1/booleans/{id}", h.limitBoolean(h.handleToggleBooleanById)},
+		{http.MethodDelete, "/api/v1/booleans/{id}", h.limitBoolean(h.handleDeleteBooleanById)},
+		{http.MethodGet, "/api/v1/booleans/{id}/schedules", h.handleGetSchedules},
+		{http.MethodPost, "/api/v1/booleans/{id}/schedules", h.limitBoolean(h.idempotent(h.handleCreateSchedule))},
+		{http.MethodDelete, "/api/v1/booleans/{id}/schedules/{scheduleId}", h.limitBoolean(h.handleDeleteSchedule)},
+		{http.MethodGet, "/api/v1/booleans/{id}/evaluate", h.handleEvaluateBoolean},
+		{http.MethodPost, "/api/v1/booleans/{id}/lease", h.limitBoolean(h.handleAcquireLease)},
+		{http.MethodPut, "/api/v1/booleans/{id}/lease", h.limitBoolean(h.handleRenewLease)},
+		{http.MethodDelete, "/api/v1/booleans/{id}/lease", h.limitBoolean(h.handleReleaseLease)},
+		{http.MethodGet, "/api/v1/export", h.handleExport},
+		{http.MethodPost, "/api/v1/import", h.handleImport},
+		{http.MethodGet, HEALTH_PATH, h.handleHealth},
+		{http.MethodGet, READINESS_PATH, h.handleReadiness},
+		{http.MethodGet, VERSION_PATH, h.handleVersion},
+	}
+}
+
+// endpoint holds the handlers of a path pattern by method, as well as the
+// methods in order of registration for the Allow header.
+type endpoint struct {
+	methods  []string
+	handlers map[string]http.HandlerFunc
+}
+
+// allow returns the value of the Allow header, OPTIONS is supported by all
+// endpoints.
+func (e *endpoint) allow() string {
+	return strings.Join(append(slices.Clone(e.methods), http.MethodOptions), ", ")
+}
+
+func (e *endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", e.allow())
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	handler, ok := e.handlers[r.Method]
+
+	if !ok {
+		w.Header().Set("Allow", e.allow())
+
+		httpErr := errors.NewHTTPError(http.StatusMethodNotAllowed, &errors.METHOD_NOT_ALLOWED_ERROR)
+		httpErr.Write(w)
+		return
+	}
+
+	handler(w, r)
+}
+
+// NewRouter returns the handler serving all endpoints of the API, which use
+// the client of the given pool. Unknown paths are answered with 404 Not
+// Found, unsupported methods with 405 Method Not Allowed, including the Allow
+// header derived from the routes. HEAD is supported along with GET, OPTIONS
+// is answered by every endpoint and CORS preflight requests are answered
+// before authentication.
+func NewRouter(c *config.Config, pool *db.Pool) http.Handler {
+	mux := http.NewServeMux()
+
+	h := &handler{config: c, pool: pool}
+
+	endpoints := map[string]*endpoint{}
+
+	for _, rt := range h.routes() {
+		e, ok := endpoints[rt.pattern]
+
+		if !ok {
+			e = &endpoint{handlers: map[string]http.HandlerFunc{}}
+			endpoints[rt.pattern] = e
+
+			mux.Handle(rt.pattern, e)
+		}
+
+		e.methods = append(e.methods, rt.method)
+		e.handlers[rt.method] = rt.handler
+
+		// like the ServeMux, HEAD is answered by the handler of GET
+		if rt.method == http.MethodGet {
+			e.methods = append(e.methods, http.MethodHead)
+			e.handlers[http.MethodHead] = rt.handler
+		}
+	}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		httpErr := errors.NewHTTPError(http.StatusNotFound, &errors.NOT_FOUND_ERROR)
+		httpErr.Write(w)
+	})
+
+	return h.identify(h.trace(h.instrument(h.cors(mux, h.authenticate(h.limitClient(h.limitBody(mux)))))))
+}
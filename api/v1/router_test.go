@@ -0,0 +1,102 @@
+package v1_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouter(t *testing.T) {
+	c := config.Default()
+
+	router := v1.NewRouter(c, db.NewPool(c))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+		allow  string
+	}{
+		{
+			name:   "unknown path",
+			method: http.MethodGet,
+			path:   "/api/v1/unknown",
+			want:   http.StatusNotFound,
+		},
+		{
+			name:   "unknown nested path",
+			method: http.MethodGet,
+			path:   "/api/v1/booleans/" + BOOLEAN_TEST_ID + "/history",
+			want:   http.StatusNotFound,
+		},
+		{
+			name:   "missing id",
+			method: http.MethodGet,
+			path:   "/api/v1/booleans/",
+			want:   http.StatusNotFound,
+		},
+		{
+			name:   "unsupported method on collection",
+			method: http.MethodDelete,
+			path:   "/api/v1/booleans",
+			want:   http.StatusMethodNotAllowed,
+			allow:  "GET, HEAD, POST, OPTIONS",
+		},
+		{
+			name:   "unsupported method on boolean",
+			method: http.MethodPost,
+			path:   "/api/v1/booleans/" + BOOLEAN_TEST_ID,
+			want:   http.StatusMethodNotAllowed,
+			allow:  "GET, HEAD, PUT, PATCH, DELETE, OPTIONS",
+		},
+		{
+			name:   "unsupported method on schedule",
+			method: http.MethodGet,
+			path:   "/api/v1/booleans/" + BOOLEAN_TEST_ID + "/schedules/scheduleId",
+			want:   http.StatusMethodNotAllowed,
+			allow:  "DELETE, OPTIONS",
+		},
+		{
+			name:   "unsupported method on lease",
+			method: http.MethodGet,
+			path:   "/api/v1/booleans/" + BOOLEAN_TEST_ID + "/lease",
+			want:   http.StatusMethodNotAllowed,
+			allow:  "POST, PUT, DELETE, OPTIONS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			assert.Equal(t, tt.want, w.Code)
+			assert.Equal(t, tt.allow, w.Header().Get("Allow"))
+
+			var httpErr errors.HTTPError
+			if err := json.NewDecoder(w.Body).Decode(&httpErr); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.NotEmpty(t, httpErr.Errors)
+			assert.Equal(t, tt.want, (*httpErr.Errors)[0].Status)
+		})
+	}
+
+	t.Run("HEAD on GET endpoint", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodHead, v1.HEALTH_PATH, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
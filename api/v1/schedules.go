@@ -0,0 +1,144 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/errors"
+)
+
+func (h *handler) handleCreateSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	params, err := booleans.ParseScheduleParams(r)
+
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	s, err := booleans.ParseSchedule(r, id)
+
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	client, err := h.pool.Client(r.Context())
+	if err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+
+	if err = s.Save(client, r.Context()); err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	s.Preview(params.Next)
+
+	res := booleans.CreateScheduleResponse(s)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+}
+
+func (h *handler) handleGetSchedules(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	params, err := booleans.ParseScheduleParams(r)
+
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	client, err := h.pool.Client(r.Context())
+	if err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+
+	s, err := booleans.GetSchedules(client, r.Context(), id)
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	for _, schedule := range s {
+		schedule.Preview(params.Next)
+	}
+
+	res := booleans.CreateSchedulesResponse(s)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+}
+
+func (h *handler) handleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	id, scheduleId := r.PathValue("id"), r.PathValue("scheduleId")
+
+	client, err := h.pool.Client(r.Context())
+	if err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+
+	if err := booleans.DeleteSchedule(client, r.Context(), id, scheduleId); err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
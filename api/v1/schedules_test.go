@@ -0,0 +1,194 @@
+package v1_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	rdb "github.com/redis/go-redis/v9"
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/errors"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+type scheduleResponse struct {
+	Data booleans.Schedule `json:"data"`
+}
+
+type schedulesResponse struct {
+	Data []booleans.Schedule `json:"data"`
+}
+
+func TestHandleSchedules(t *testing.T) {
+	var client rdb.UniversalClient
+	var container *redis.RedisContainer
+	var err error
+	var server *httptest.Server
+
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+		test.TerminateContainer(container, t)
+	})
+
+	if container, err = test.CreateContainer(ctx, t); err != nil {
+		t.Fatal(err)
+	}
+
+	c := test.Config(t)
+
+	server = httptest.NewServer(v1.NewRouter(c, db.NewPool(c)))
+	if client, err = db.NewRedis(test.Config(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = client.HSet(ctx, BOOLEAN_TEST_ID, &booleans.Boolean{
+		Label: BOOLEAN_TEST_ID,
+		Value: false,
+	}).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var created booleans.Schedule
+
+	t.Run("create schedule", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			id      string
+			body    string
+			want    int
+			wantErr bool
+		}{
+			{
+				name: "create set schedule",
+				id:   BOOLEAN_TEST_ID,
+				body: fmt.Sprintf(`{"at":%d,"set":true}`, time.Now().Unix()+60),
+				want: http.StatusCreated,
+			},
+			{
+				name:    "create schedule without action",
+				id:      BOOLEAN_TEST_ID,
+				body:    fmt.Sprintf(`{"at":%d}`, time.Now().Unix()+60),
+				want:    http.StatusBadRequest,
+				wantErr: true,
+			},
+			{
+				name:    "create schedule for inexistent boolean",
+				id:      "inexistentId",
+				body:    fmt.Sprintf(`{"at":%d,"toggle":true}`, time.Now().Unix()+60),
+				want:    http.StatusNotFound,
+				wantErr: true,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req, err := http.NewRequest(http.MethodPost, server.URL+"/api/v1/booleans/"+tt.id+"/schedules", bytes.NewBufferString(tt.body))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				req.Header.Set("Content-Type", "application/json")
+
+				res, err := server.Client().Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, tt.want, res.StatusCode)
+
+				if !tt.wantErr {
+					var s scheduleResponse
+					if err = json.NewDecoder(res.Body).Decode(&s); err != nil {
+						t.Fatal(err)
+					}
+
+					assert.NotEmpty(t, s.Data.Id)
+					assert.Equal(t, tt.id, s.Data.BooleanId)
+
+					created = s.Data
+				} else {
+					var httpErr errors.HTTPError
+					if err = json.NewDecoder(res.Body).Decode(&httpErr); err != nil {
+						t.Fatal(err)
+					}
+
+					assert.NotEmpty(t, httpErr.Errors)
+					assert.Equal(t, (*httpErr.Errors)[0].Status, res.StatusCode)
+				}
+			})
+		}
+	})
+
+	t.Run("list schedules", func(t *testing.T) {
+		res, err := server.Client().Get(server.URL + "/api/v1/booleans/" + BOOLEAN_TEST_ID + "/schedules")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var s schedulesResponse
+		if err = json.NewDecoder(res.Body).Decode(&s); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Len(t, s.Data, 1)
+		assert.Equal(t, created.Id, s.Data[0].Id)
+	})
+
+	t.Run("delete schedule", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			method string
+			id     string
+			want   int
+		}{
+			{
+				name:   "delete schedule",
+				method: http.MethodDelete,
+				id:     created.Id,
+				want:   http.StatusNoContent,
+			},
+			{
+				name:   "delete inexistent schedule",
+				method: http.MethodDelete,
+				id:     created.Id,
+				want:   http.StatusNotFound,
+			},
+			{
+				name:   "unsupported method",
+				method: http.MethodPut,
+				id:     created.Id,
+				want:   http.StatusMethodNotAllowed,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req, err := http.NewRequest(tt.method, server.URL+"/api/v1/booleans/"+BOOLEAN_TEST_ID+"/schedules/"+tt.id, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				res, err := server.Client().Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, tt.want, res.StatusCode)
+			})
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// spanTree indexes the ended spans by their parent.
+type spanTree map[trace.SpanID][]tracetest.SpanStub
+
+func newSpanTree(spans tracetest.SpanStubs) spanTree {
+	tree := spanTree{}
+
+	for _, s := range spans {
+		tree[s.Parent.SpanID()] = append(tree[s.Parent.SpanID()], s)
+	}
+
+	return tree
+}
+
+// child returns the child span of the parent with the given name.
+func (tree spanTree) child(t *testing.T, parent trace.SpanID, name string) tracetest.SpanStub {
+	t.Helper()
+
+	names := []string{}
+
+	for _, s := range tree[parent] {
+		if s.Name == name {
+			return s
+		}
+
+		names = append(names, s.Name)
+	}
+
+	t.Fatalf("span %s has no child %q, got %v", parent, name, names)
+
+	return tracetest.SpanStub{}
+}
+
+func TestTracing(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exporter := tracetest.NewInMemoryExporter()
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+		test.TerminateContainer(container, t)
+	})
+
+	c := test.Config(t)
+
+	router := v1.NewRouter(c, db.NewPool(c))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/booleans", strings.NewReader(`{"label": "traced", "value": false}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if !assert.Equal(t, http.StatusOK, w.Code) {
+		t.FailNow()
+	}
+
+	var res booleanResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("create", func(t *testing.T) {
+		tree := newSpanTree(exporter.GetSpans())
+
+		root := tree.child(t, trace.SpanID{}, "POST /api/v1/booleans")
+		assert.Equal(t, trace.SpanKindServer, root.SpanKind)
+
+		save := tree.child(t, root.SpanContext.SpanID(), "booleans.Save")
+		assert.Contains(t, save.Attributes, attribute.String(booleans.ATTRIBUTE_BOOLEAN_ID, res.Data.Id))
+
+		tree.child(t, save.SpanContext.SpanID(), "hset")
+	})
+
+	exporter.Reset()
+
+	remote := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+
+	req = httptest.NewRequest(http.MethodPatch, "/api/v1/booleans/"+res.Data.Id, nil)
+	otel.GetTextMapPropagator().Inject(trace.ContextWithSpanContext(ctx, remote), propagation.HeaderCarrier(req.Header))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if !assert.Equal(t, http.StatusOK, w.Code) {
+		t.FailNow()
+	}
+
+	t.Run("toggle", func(t *testing.T) {
+		tree := newSpanTree(exporter.GetSpans())
+
+		root := tree.child(t, remote.SpanID(), "PATCH /api/v1/booleans/{id}")
+		assert.Equal(t, remote.TraceID(), root.SpanContext.TraceID())
+
+		toggle := tree.child(t, root.SpanContext.SpanID(), "booleans.ToggleBoolean")
+		get := tree.child(t, toggle.SpanContext.SpanID(), "booleans.GetBoolean")
+
+		tree.child(t, get.SpanContext.SpanID(), "exists")
+		tree.child(t, get.SpanContext.SpanID(), "hgetall")
+		tree.child(t, toggle.SpanContext.SpanID(), "hset")
+
+		for _, s := range exporter.GetSpans() {
+			assert.Equal(t, remote.TraceID(), s.SpanContext.TraceID(), s.Name)
+		}
+	})
+
+	exporter.Reset()
+
+	t.Run("strict JSON", func(t *testing.T) {
+		strict := test.Config(t)
+		strict.StrictJSON = true
+
+		v1.NewRouter(strict, db.NewPool(strict)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, v1.HEALTH_PATH, nil))
+
+		tree := newSpanTree(exporter.GetSpans())
+
+		root := tree.child(t, trace.SpanID{}, "GET "+v1.HEALTH_PATH)
+		assert.Contains(t, root.Attributes, semconv.HTTPRoute(v1.HEALTH_PATH))
+	})
+}
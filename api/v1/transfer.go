@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/errors"
+)
+
+// trackingWriter records whether the response was started, after which
+// errors can no longer be reported using the status code.
+type trackingWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (t *trackingWriter) Write(b []byte) (int, error) {
+	t.started = true
+
+	return t.ResponseWriter.Write(b)
+}
+
+// handleExport streams all booleans as NDJSON or CSV, according to the format
+// query parameter.
+func (h *handler) handleExport(w http.ResponseWriter, r *http.Request) {
+	params, err := booleans.ParseExportParams(r)
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	client, err := h.pool.Client(r.Context())
+	if err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+
+	tw := &trackingWriter{ResponseWriter: w}
+
+	w.Header().Set("Content-Type", booleans.ContentTypes[params.Format])
+	w.Header().Set("Content-Disposition", "attachment; filename=\"booleans."+params.Format+"\"")
+
+	if err = booleans.ExportBooleans(client, r.Context(), params.Format, tw); err != nil {
+		slog.ErrorContext(r.Context(), "failed to export booleans", "error", err)
+
+		if !tw.started {
+			httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+			httpErr.Write(w)
+		}
+	}
+}
+
+// handleImport imports NDJSON or CSV exports, according to the Content-Type
+// header.
+func (h *handler) handleImport(w http.ResponseWriter, r *http.Request) {
+	params, err := booleans.ParseImportParams(r)
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	params.MaxTTL, params.DefaultTTL = h.config.MaxTTL, h.config.DefaultTTL
+
+	client, err := h.pool.Client(r.Context())
+	if err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+
+	result, err := booleans.ImportBooleans(client, r.Context(), params, r.Body)
+	if err != nil {
+		httpErr, ok := err.(*errors.HTTPError)
+
+		if !ok {
+			httpErr = errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		httpErr.Write(w)
+		return
+	}
+
+	res := booleans.CreateImportResponse(result)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err = json.NewEncoder(w).Encode(res); err != nil {
+		httpErr := errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		httpErr.Write(w)
+		return
+	}
+}
@@ -0,0 +1,212 @@
+package v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	rdb "github.com/redis/go-redis/v9"
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+type importResponse struct {
+	Data booleans.ImportResult `json:"data"`
+}
+
+func TestHandleTransfer(t *testing.T) {
+	var client rdb.UniversalClient
+	var container *redis.RedisContainer
+	var err error
+	var server *httptest.Server
+
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+		test.TerminateContainer(container, t)
+	})
+
+	if container, err = test.CreateContainer(ctx, t); err != nil {
+		t.Fatal(err)
+	}
+
+	c := test.Config(t)
+
+	server = httptest.NewServer(v1.NewRouter(c, db.NewPool(c)))
+	if client, err = db.NewRedis(test.Config(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &booleans.Boolean{Label: BOOLEAN_TEST_ID, Value: true}
+	if err = b.Save(client, ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	exports := map[string]string{}
+
+	exportTests := []struct {
+		name        string
+		method      string
+		query       string
+		want        int
+		contentType string
+	}{
+		{
+			name:        "export NDJSON by default",
+			method:      http.MethodGet,
+			want:        http.StatusOK,
+			contentType: booleans.CONTENT_TYPE_NDJSON,
+		},
+		{
+			name:        "export CSV",
+			method:      http.MethodGet,
+			query:       "format=csv",
+			want:        http.StatusOK,
+			contentType: booleans.CONTENT_TYPE_CSV,
+		},
+		{
+			name:   "export unknown format",
+			method: http.MethodGet,
+			query:  "format=xml",
+			want:   http.StatusBadRequest,
+		},
+		{
+			name:   "unsupported method",
+			method: http.MethodPost,
+			want:   http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range exportTests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+"/api/v1/export?"+tt.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.want, res.StatusCode)
+
+			if res.StatusCode == http.StatusOK {
+				assert.Equal(t, tt.contentType, res.Header.Get("Content-Type"))
+
+				body, err := io.ReadAll(res.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Contains(t, string(body), *b.Id)
+
+				exports[tt.contentType] = string(body)
+			}
+		})
+	}
+
+	if err = client.Del(ctx, *b.Id).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	importTests := []struct {
+		name        string
+		method      string
+		query       string
+		contentType string
+		want        int
+		result      booleans.ImportResult
+	}{
+		{
+			name:        "import NDJSON",
+			method:      http.MethodPost,
+			contentType: booleans.CONTENT_TYPE_NDJSON,
+			want:        http.StatusOK,
+			result:      booleans.ImportResult{Imported: 1},
+		},
+		{
+			name:        "import CSV, skipping existing booleans",
+			method:      http.MethodPost,
+			contentType: booleans.CONTENT_TYPE_CSV,
+			want:        http.StatusOK,
+			result:      booleans.ImportResult{Skipped: 1},
+		},
+		{
+			name:        "import CSV, overwriting existing booleans",
+			method:      http.MethodPost,
+			query:       "mode=overwrite",
+			contentType: booleans.CONTENT_TYPE_CSV,
+			want:        http.StatusOK,
+			result:      booleans.ImportResult{Imported: 1},
+		},
+		{
+			name:        "import failing on existing booleans",
+			method:      http.MethodPost,
+			query:       "mode=fail",
+			contentType: booleans.CONTENT_TYPE_NDJSON,
+			want:        http.StatusConflict,
+		},
+		{
+			name:        "import unknown mode",
+			method:      http.MethodPost,
+			query:       "mode=merge",
+			contentType: booleans.CONTENT_TYPE_NDJSON,
+			want:        http.StatusBadRequest,
+		},
+		{
+			name:        "import unsupported media type",
+			method:      http.MethodPost,
+			contentType: "application/json",
+			want:        http.StatusUnsupportedMediaType,
+		},
+		{
+			name:   "unsupported method",
+			method: http.MethodGet,
+			want:   http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range importTests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, server.URL+"/api/v1/import?"+tt.query, strings.NewReader(exports[tt.contentType]))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set("Content-Type", tt.contentType)
+
+			res, err := server.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			defer res.Body.Close()
+
+			assert.Equal(t, tt.want, res.StatusCode)
+
+			if res.StatusCode == http.StatusOK {
+				var r importResponse
+				if err = json.NewDecoder(res.Body).Decode(&r); err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, tt.result, r.Data)
+			}
+		})
+	}
+
+	assert.Equal(t, BOOLEAN_TEST_ID, client.HGet(ctx, *b.Id, booleans.BOOLEAN_LABEL).Val())
+}
@@ -3,19 +3,32 @@ package booleans
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/saschazar21/go-baas/errors"
+	"github.com/saschazar21/go-baas/metrics"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
-	BOOLEAN_LABEL = "label"
-	BOOLEAN_VALUE = "value"
+	BOOLEAN_LABEL      = "label"
+	BOOLEAN_VALUE      = "value"
+	BOOLEAN_EXPRESSION = "expression"
+
+	BOOLEAN_CREATED_AT = "created_at"
+	BOOLEAN_UPDATED_AT = "updated_at"
+
+	BOOLEAN_LEASE_OWNER      = "lease_owner"
+	BOOLEAN_LEASE_EXPIRES_AT = "lease_expires_at"
 )
 
+// now is the clock used by the booleans package, replaceable in tests.
+var now = time.Now
+
 type booleanWithId struct {
 	Id string `json:"id"`
 	*Boolean
@@ -25,15 +38,70 @@ type booleanResponse struct {
 	Data *booleanWithId `json:"data"`
 }
 
+// booleanBody is the request body of a boolean, which may also carry its
+// expiry.
+type booleanBody struct {
+	*Boolean
+	ExpiresAt Expiry   `json:"expires_at,omitempty" schema:"expires_at"`
+	ExpiresIn Lifetime `json:"expires_in,omitempty" schema:"expires_in"`
+}
+
 type BooleanParams struct {
-	ExpiresAt int64   `schema:"expires_at" validate:"omitempty,epoch-gt-now"`
-	ExpiresIn int64   `schema:"expires_in" validate:"omitempty,gt=0"`
-	Id        *string `schema:"id"`
+	ExpiresAt Expiry   `schema:"expires_at" validate:"omitempty,epoch-gt-now"`
+	ExpiresIn Lifetime `schema:"expires_in" validate:"omitempty,gt=0"`
+	Id        *string  `schema:"id"`
+}
+
+// expiresAt returns the requested expiry as Unix epoch in seconds, or 0 if
+// none was requested. ExpiresAt takes precedence over ExpiresIn.
+func (b *BooleanParams) expiresAt() int64 {
+	if b.ExpiresAt > 0 {
+		return int64(b.ExpiresAt)
+	}
+
+	if b.ExpiresIn > 0 {
+		return int64(b.ExpiresIn) + now().Unix()
+	}
+
+	return 0
+}
+
+// LimitTTL rejects expiries beyond max from now. New booleans without
+// requested expiry expire after def, or after max, if def is 0. A max of 0
+// means unlimited.
+func (b *BooleanParams) LimitTTL(max, def time.Duration) error {
+	if def <= 0 {
+		def = max
+	}
+
+	expiry := b.expiresAt()
+
+	if expiry == 0 && b.Id == nil && def > 0 {
+		b.ExpiresAt = Expiry(now().Add(def).Unix())
+	}
+
+	if max <= 0 {
+		return nil
+	}
+
+	if limit := now().Add(max).Unix(); expiry > limit {
+		slog.Debug("expiry exceeds the maximum TTL", "expires_at", expiry, "max_ttl", max)
+
+		return errors.NewHTTPError(http.StatusBadRequest, &[]errors.ErrorContent{
+			{
+				Status: http.StatusBadRequest,
+				Title:  "Bad Request",
+				Detail: fmt.Sprintf("expiry exceeds the maximum TTL of %s", max),
+			},
+		})
+	}
+
+	return nil
 }
 
 func (b *BooleanParams) Validate() (err error) {
 	if err = CustomValidateStruct(b); err != nil {
-		log.Println(err)
+		slog.Debug("invalid boolean parameters", "error", err)
 
 		return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
 	}
@@ -42,8 +110,16 @@ func (b *BooleanParams) Validate() (err error) {
 }
 
 type Boolean struct {
-	Label string `json:"label,omitempty" redis:"label" schema:"label"`
-	Value bool   `json:"value" redis:"value" schema:"value"`
+	Label      string   `json:"label,omitempty" redis:"label" schema:"label" validate:"omitempty,boolean-label"`
+	Value      bool     `json:"value" redis:"value" schema:"value"`
+	Expression string   `json:"expression,omitempty" redis:"expression,omitempty" schema:"expression" validate:"omitempty,max=1024,boolean-expression"`
+	Rollout    *Rollout `json:"rollout,omitempty" redis:"rollout,omitempty" schema:"rollout" validate:"omitempty"`
+
+	CreatedAt int64 `json:"created_at,omitempty" redis:"created_at,omitempty" schema:"-"`
+	UpdatedAt int64 `json:"updated_at,omitempty" redis:"updated_at,omitempty" schema:"-"`
+
+	LeaseExpiresAt int64  `json:"lease_expires_at,omitempty" redis:"lease_expires_at,omitempty" schema:"-"`
+	LeaseOwner     string `json:"-" redis:"lease_owner,omitempty" schema:"-"`
 
 	*BooleanParams `json:"-" redis:"-" schema:"-" validate:"omitempty"`
 }
@@ -61,7 +137,16 @@ func (b Boolean) String() string {
 	return fmt.Sprintf("[%s]: %t, Label: \"%s\"", *id, b.Value, b.Label)
 }
 
-func (b *Boolean) Save(client *redis.Client, ctx context.Context) (err error) {
+func (b *Boolean) Save(client redis.UniversalClient, ctx context.Context) (err error) {
+	ctx, span := startSpan(ctx, "Save")
+	defer func() {
+		if b.BooleanParams != nil && b.Id != nil {
+			span.SetAttributes(attribute.String(ATTRIBUTE_BOOLEAN_ID, *b.Id))
+		}
+
+		endSpan(span, err)
+	}()
+
 	if err = b.Validate(); err != nil {
 		return
 	}
@@ -71,12 +156,36 @@ func (b *Boolean) Save(client *redis.Client, ctx context.Context) (err error) {
 	}
 
 	if b.Id != nil && client.Exists(ctx, *b.Id).Val() == 0 {
-		log.Printf("Boolean with ID %s not found", *b.Id)
+		slog.DebugContext(ctx, "boolean not found", "id", *b.Id)
 
 		return errors.NewHTTPError(http.StatusNotFound, &errors.NOT_FOUND_ERROR)
 	}
 
+	if b.Id != nil {
+		if err = ensureWritable(client, ctx, *b.Id); err != nil {
+			return
+		}
+	}
+
+	if b.Expression != "" {
+		if b.Value, err = evaluateExpression(client, ctx, b.Expression, b.Id); err != nil {
+			slog.WarnContext(ctx, "failed to evaluate expression", "error", err, "expression", b.Expression)
+
+			if _, ok := err.(*expressionError); ok {
+				return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+			}
+
+			return errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+	}
+
+	b.UpdatedAt = now().Unix()
+
+	operation := metrics.OPERATION_UPDATE
+
 	if b.Id == nil {
+		operation = metrics.OPERATION_CREATE
+
 		id := generateRandomId()
 
 		for client.Exists(ctx, id).Val() > 0 {
@@ -84,36 +193,44 @@ func (b *Boolean) Save(client *redis.Client, ctx context.Context) (err error) {
 		}
 
 		b.Id = &id
+		b.CreatedAt = b.UpdatedAt
+	} else {
+		// booleans created before timestamps were introduced have none
+		b.CreatedAt, _ = client.HGet(ctx, *b.Id, BOOLEAN_CREATED_AT).Int64()
 	}
 
 	if err = client.HSet(ctx, *b.Id, b).Err(); err != nil {
-		log.Println(err)
+		slog.ErrorContext(ctx, "failed to save boolean", "error", err, "id", *b.Id)
 
 		return errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
 	}
 
-	if b.ExpiresIn > 0 || b.ExpiresAt > 0 {
-		var ttl int64
+	if b.Rollout == nil {
+		if err = client.HDel(ctx, *b.Id, BOOLEAN_ROLLOUT).Err(); err != nil {
+			slog.ErrorContext(ctx, "failed to remove rollout", "error", err, "id", *b.Id)
 
-		if b.ExpiresAt > 0 {
-			ttl = b.ExpiresAt
-		} else {
-			ttl = b.ExpiresIn + time.Now().Unix()
+			return errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
 		}
+	}
 
+	if ttl := b.expiresAt(); ttl > 0 {
 		if err = client.ExpireAt(ctx, *b.Id, time.Unix(ttl, 0)).Err(); err != nil {
-			log.Println(err)
+			slog.ErrorContext(ctx, "failed to set expiry", "error", err, "id", *b.Id)
 
 			return errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
 		}
 	}
 
+	syncShadow(client, ctx, *b.Id)
+
+	metrics.BooleanOperations.WithLabelValues(operation).Inc()
+
 	return
 }
 
 func (b *Boolean) Validate() (err error) {
 	if err = CustomValidateStruct(b); err != nil {
-		log.Println(err)
+		slog.Debug("invalid boolean", "error", err)
 
 		return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
 	}
@@ -121,19 +238,57 @@ func (b *Boolean) Validate() (err error) {
 	return
 }
 
-func DeleteBoolean(client *redis.Client, ctx context.Context, id string) (err error) {
-	if err = client.Del(ctx, id).Err(); err != nil {
-		log.Println(err)
+// DeleteBoolean deletes the boolean along with its schedules, unless it has
+// an active lease.
+func DeleteBoolean(client redis.UniversalClient, ctx context.Context, id string) (err error) {
+	ctx, span := startSpan(ctx, "DeleteBoolean", attribute.String(ATTRIBUTE_BOOLEAN_ID, id))
+	defer func() { endSpan(span, err) }()
+
+	var ids []string
+	if ids, err = client.ZRange(ctx, booleanSchedulesKey(id), 0, -1).Result(); err != nil {
+		slog.ErrorContext(ctx, "failed to read schedules of boolean", "error", err, "id", id)
+
+		return errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+	}
+
+	// the boolean, its shadow copy and the index of its schedules share a
+	// cluster slot
+	var result int64
+	if result, err = deleteBooleanScript.Run(ctx, client, []string{id, shadowKey(id), booleanSchedulesKey(id)}, now().Unix()).Int64(); err != nil {
+		slog.ErrorContext(ctx, "failed to delete boolean", "error", err, "id", id)
 
 		return errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
 	}
 
+	if result == leaseRejected {
+		slog.DebugContext(ctx, "boolean is leased and cannot be deleted", "id", id)
+
+		return errors.NewHTTPError(http.StatusConflict, &errors.CONFLICT_ERROR)
+	}
+
+	if err = deleteSchedules(client, ctx, ids); err != nil {
+		slog.ErrorContext(ctx, "failed to delete schedules of boolean", "error", err, "id", id)
+
+		return errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+	}
+
+	if err = client.ZRem(ctx, LEASES_DUE_KEY, id).Err(); err != nil {
+		slog.WarnContext(ctx, "failed to remove lease from index", "error", err, "id", id)
+
+		err = nil
+	}
+
+	metrics.BooleanOperations.WithLabelValues(metrics.OPERATION_DELETE).Inc()
+
 	return
 }
 
-func GetBoolean(client *redis.Client, ctx context.Context, id string) (b *Boolean, err error) {
+func GetBoolean(client redis.UniversalClient, ctx context.Context, id string) (b *Boolean, err error) {
+	ctx, span := startSpan(ctx, "GetBoolean", attribute.String(ATTRIBUTE_BOOLEAN_ID, id))
+	defer func() { endSpan(span, err) }()
+
 	if client.Exists(ctx, id).Val() == 0 {
-		log.Printf("Boolean with ID %s not found", id)
+		slog.DebugContext(ctx, "boolean not found", "id", id)
 
 		return b, errors.NewHTTPError(http.StatusNotFound, &errors.NOT_FOUND_ERROR)
 	}
@@ -141,7 +296,7 @@ func GetBoolean(client *redis.Client, ctx context.Context, id string) (b *Boolea
 	b = new(Boolean)
 
 	if err = client.HGetAll(ctx, id).Scan(b); err != nil {
-		log.Println(err)
+		slog.ErrorContext(ctx, "failed to read boolean", "error", err, "id", id)
 
 		return b, errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
 	}
@@ -150,30 +305,107 @@ func GetBoolean(client *redis.Client, ctx context.Context, id string) (b *Boolea
 		Id: &id,
 	}
 
+	if b.LeaseExpiresAt > 0 && b.LeaseExpiresAt <= now().Unix() {
+		if err = revertExpiredLease(client, ctx, id); err != nil {
+			slog.ErrorContext(ctx, "failed to revert expired lease", "error", err, "id", id)
+
+			return b, errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		b.Value = false
+		b.LeaseExpiresAt = 0
+		b.LeaseOwner = ""
+	}
+
+	if b.Expression != "" {
+		if b.Value, err = evaluateExpression(client, ctx, b.Expression, &id); err != nil {
+			slog.WarnContext(ctx, "failed to evaluate expression", "error", err, "id", id)
+
+			if _, ok := err.(*expressionError); ok {
+				return b, errors.NewHTTPError(http.StatusConflict, &errors.CONFLICT_ERROR)
+			}
+
+			return b, errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+	}
+
 	return
 }
 
-func ToggleBoolean(client *redis.Client, ctx context.Context, id string) (b *Boolean, err error) {
+func ToggleBoolean(client redis.UniversalClient, ctx context.Context, id string) (b *Boolean, err error) {
+	ctx, span := startSpan(ctx, "ToggleBoolean", attribute.String(ATTRIBUTE_BOOLEAN_ID, id))
+	defer func() { endSpan(span, err) }()
+
 	if b, err = GetBoolean(client, ctx, id); err != nil {
 		return
 	}
 
+	if b.Expression != "" {
+		slog.DebugContext(ctx, "boolean is computed and cannot be toggled", "id", id)
+
+		return nil, errors.NewHTTPError(http.StatusConflict, &errors.CONFLICT_ERROR)
+	}
+
+	if b.LeaseExpiresAt > 0 {
+		slog.DebugContext(ctx, "boolean is leased and cannot be toggled", "id", id)
+
+		return nil, errors.NewHTTPError(http.StatusConflict, &errors.CONFLICT_ERROR)
+	}
+
 	b.Value = !b.Value
+	b.UpdatedAt = now().Unix()
 
-	if err = client.HSet(ctx, id, BOOLEAN_VALUE, b.Value).Err(); err != nil {
-		log.Println(err)
+	if err = client.HSet(ctx, id, BOOLEAN_VALUE, b.Value, BOOLEAN_UPDATED_AT, b.UpdatedAt).Err(); err != nil {
+		slog.ErrorContext(ctx, "failed to toggle boolean", "error", err, "id", id)
 
 		return nil, errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
 	}
 
+	syncShadow(client, ctx, id)
+
+	metrics.BooleanOperations.WithLabelValues(metrics.OPERATION_TOGGLE).Inc()
+
 	return
 }
 
+// ensureWritable rejects modifications of computed booleans and of booleans
+// with an active lease. Expired leases are reverted beforehand.
+func ensureWritable(client redis.UniversalClient, ctx context.Context, id string) (err error) {
+	var fields []interface{}
+	if fields, err = client.HMGet(ctx, id, BOOLEAN_EXPRESSION, BOOLEAN_LEASE_EXPIRES_AT).Result(); err != nil {
+		slog.ErrorContext(ctx, "failed to read boolean", "error", err, "id", id)
+
+		return errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+	}
+
+	if expression, ok := fields[0].(string); ok && expression != "" {
+		slog.DebugContext(ctx, "boolean is computed and read-only", "id", id)
+
+		return errors.NewHTTPError(http.StatusConflict, &errors.CONFLICT_ERROR)
+	}
+
+	if leaseExpiresAt, ok := fields[1].(string); ok && leaseExpiresAt != "" {
+		if expiresAt, _ := strconv.ParseInt(leaseExpiresAt, 10, 64); expiresAt > now().Unix() {
+			slog.DebugContext(ctx, "boolean is leased and read-only", "id", id)
+
+			return errors.NewHTTPError(http.StatusConflict, &errors.CONFLICT_ERROR)
+		}
+
+		if err = revertExpiredLease(client, ctx, id); err != nil {
+			slog.ErrorContext(ctx, "failed to revert expired lease", "error", err, "id", id)
+
+			return errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+	}
+
+	return nil
+}
+
 func ParseBoolean(r *http.Request) (b *Boolean, err error) {
 	var params BooleanParams
 
 	if err = decoder.Decode(&params, r.URL.Query()); err != nil {
-		log.Println(err)
+		slog.DebugContext(r.Context(), "failed to decode query", "error", err)
 
 		return b, errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
 	}
@@ -183,14 +415,20 @@ func ParseBoolean(r *http.Request) (b *Boolean, err error) {
 	}
 
 	b = new(Boolean)
+	body := &booleanBody{Boolean: b}
+
+	var mt string
+	if mt, err = mediaType(r); err != nil {
+		return
+	}
 
-	switch r.Header.Get("Content-Type") {
-	case "application/json":
-		if err = parseJsonEncodedBody(r, b); err != nil {
+	switch mt {
+	case CONTENT_TYPE_JSON:
+		if err = parseJsonEncodedBody(r, body); err != nil {
 			return
 		}
-	case "application/x-www-form-urlencoded":
-		if err = parseUrlEncodedBody(r, b); err != nil {
+	case CONTENT_TYPE_FORM:
+		if err = parseUrlEncodedBody(r, body); err != nil {
 			return
 		}
 	default:
@@ -198,7 +436,13 @@ func ParseBoolean(r *http.Request) (b *Boolean, err error) {
 		return
 	}
 
+	// an expiry in the body replaces the one in the query
+	if body.ExpiresAt != 0 || body.ExpiresIn != 0 {
+		params.ExpiresAt, params.ExpiresIn = body.ExpiresAt, body.ExpiresIn
+	}
+
 	b.BooleanParams = &params
+	b.LeaseExpiresAt = 0
 
 	if err = b.Validate(); err != nil {
 		return
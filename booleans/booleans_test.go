@@ -29,7 +29,7 @@ func TestValidateBooleanParams(t *testing.T) {
 		{
 			name: "valid boolean params",
 			data: BooleanParams{
-				ExpiresAt: time.Now().Unix() + 120,
+				ExpiresAt: Expiry(time.Now().Unix() + 120),
 				ExpiresIn: 120,
 				Id:        nil,
 			},
@@ -38,7 +38,7 @@ func TestValidateBooleanParams(t *testing.T) {
 		{
 			name: "invalid expires_at",
 			data: BooleanParams{
-				ExpiresAt: time.Now().Unix() - 1,
+				ExpiresAt: Expiry(time.Now().Unix() - 1),
 				ExpiresIn: 120,
 				Id:        nil,
 			},
@@ -47,7 +47,7 @@ func TestValidateBooleanParams(t *testing.T) {
 		{
 			name: "invalid expires_in",
 			data: BooleanParams{
-				ExpiresAt: time.Now().Unix() + 120,
+				ExpiresAt: Expiry(time.Now().Unix() + 120),
 				ExpiresIn: -20,
 				Id:        nil,
 			},
@@ -64,6 +64,97 @@ func TestValidateBooleanParams(t *testing.T) {
 	}
 }
 
+func TestLimitTTL(t *testing.T) {
+	clock := time.Unix(1700000000, 0)
+
+	now = func() time.Time { return clock }
+
+	t.Cleanup(func() {
+		now = time.Now
+	})
+
+	id := "existing"
+
+	tests := []struct {
+		name    string
+		data    BooleanParams
+		max     time.Duration
+		def     time.Duration
+		want    Expiry
+		wantErr bool
+	}{
+		{
+			name: "unlimited",
+			data: BooleanParams{},
+			max:  0,
+			want: 0,
+		},
+		{
+			name: "new boolean without expiry",
+			data: BooleanParams{},
+			max:  time.Hour,
+			want: Expiry(clock.Unix() + 3600),
+		},
+		{
+			name: "new boolean with default",
+			data: BooleanParams{},
+			max:  time.Hour,
+			def:  time.Minute,
+			want: Expiry(clock.Unix() + 60),
+		},
+		{
+			name: "new boolean with default and unlimited",
+			data: BooleanParams{},
+			def:  time.Minute,
+			want: Expiry(clock.Unix() + 60),
+		},
+		{
+			name: "existing boolean with default",
+			data: BooleanParams{Id: &id},
+			def:  time.Minute,
+			want: 0,
+		},
+		{
+			name: "existing boolean without expiry",
+			data: BooleanParams{Id: &id},
+			max:  time.Hour,
+			want: 0,
+		},
+		{
+			name: "expires_in within max",
+			data: BooleanParams{ExpiresIn: 60},
+			max:  time.Hour,
+			want: 0,
+		},
+		{
+			name:    "expires_in beyond max",
+			data:    BooleanParams{ExpiresIn: 3601},
+			max:     time.Hour,
+			wantErr: true,
+		},
+		{
+			name:    "expires_at beyond max",
+			data:    BooleanParams{ExpiresAt: Expiry(clock.Unix() + 7200)},
+			max:     time.Hour,
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.data.LimitTTL(tc.max, tc.def)
+
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("BooleanParams.LimitTTL() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if !tc.wantErr {
+				assert.Equal(t, tc.want, tc.data.ExpiresAt)
+			}
+		})
+	}
+}
+
 func TestValidateBoolean(t *testing.T) {
 	type test struct {
 		name    string
@@ -78,7 +169,7 @@ func TestValidateBoolean(t *testing.T) {
 				Label: "test",
 				Value: true,
 				BooleanParams: &BooleanParams{
-					ExpiresAt: time.Now().Unix() + 120,
+					ExpiresAt: Expiry(time.Now().Unix() + 120),
 					ExpiresIn: 120,
 					Id:        nil,
 				},
@@ -90,7 +181,7 @@ func TestValidateBoolean(t *testing.T) {
 			data: Boolean{
 				Label: "test",
 				BooleanParams: &BooleanParams{
-					ExpiresAt: time.Now().Unix() + 120,
+					ExpiresAt: Expiry(time.Now().Unix() + 120),
 					ExpiresIn: -1,
 					Id:        nil,
 				},
@@ -103,7 +194,7 @@ func TestValidateBoolean(t *testing.T) {
 				Label: "test",
 				Value: false,
 				BooleanParams: &BooleanParams{
-					ExpiresAt: time.Now().Unix() - 1,
+					ExpiresAt: Expiry(time.Now().Unix() - 1),
 					ExpiresIn: 120,
 					Id:        nil,
 				},
@@ -122,6 +213,8 @@ func TestValidateBoolean(t *testing.T) {
 }
 
 func TestParseBooleans(t *testing.T) {
+	future := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+
 	type testStruct struct {
 		name    string
 		headers http.Header
@@ -146,7 +239,7 @@ func TestParseBooleans(t *testing.T) {
 				Label: "test",
 				Value: true,
 				BooleanParams: &BooleanParams{
-					ExpiresAt: time.Now().Unix() + 120,
+					ExpiresAt: Expiry(time.Now().Unix() + 120),
 					ExpiresIn: 120,
 					Id:        nil,
 				},
@@ -167,13 +260,88 @@ func TestParseBooleans(t *testing.T) {
 				Label: "test",
 				Value: true,
 				BooleanParams: &BooleanParams{
-					ExpiresAt: time.Now().Unix() + 120,
+					ExpiresAt: Expiry(time.Now().Unix() + 120),
 					ExpiresIn: 120,
 					Id:        nil,
 				},
 			},
 			wantErr: false,
 		},
+		{
+			name: "duration and timestamp in query",
+			headers: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			params: url.Values{
+				"expires_at": []string{future.Format(time.RFC3339)},
+				"expires_in": []string{"1h30m"},
+			},
+			body: []byte(`{"label":"test","value":true}`),
+			cmp: Boolean{
+				Label: "test",
+				Value: true,
+				BooleanParams: &BooleanParams{
+					ExpiresAt: Expiry(future.Unix()),
+					ExpiresIn: 5400,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "expiry in json body",
+			headers: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			params: url.Values{
+				"expires_in": []string{"120"},
+			},
+			body: []byte(`{"label":"test","value":true,"expires_at":"` + future.Format(time.RFC3339) + `","expires_in":"1h"}`),
+			cmp: Boolean{
+				Label: "test",
+				Value: true,
+				BooleanParams: &BooleanParams{
+					ExpiresAt: Expiry(future.Unix()),
+					ExpiresIn: 3600,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "expiry in urlencoded body",
+			headers: http.Header{
+				"Content-Type": []string{"application/x-www-form-urlencoded"},
+			},
+			body: []byte(`label=test&value=true&expires_in=90s`),
+			cmp: Boolean{
+				Label: "test",
+				Value: true,
+				BooleanParams: &BooleanParams{
+					ExpiresIn: 90,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid duration",
+			headers: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			params: url.Values{
+				"expires_in": []string{"soon"},
+			},
+			body:    []byte(`{"label":"test","value":true}`),
+			cmp:     Boolean{},
+			wantErr: true,
+		},
+		{
+			name: "invalid expiry in json body",
+			headers: http.Header{
+				"Content-Type": []string{"application/json"},
+			},
+			body:    []byte(`{"label":"test","value":true,"expires_at":"tomorrow"}`),
+			cmp:     Boolean{},
+			wantErr: true,
+		},
 		{
 			name: "invalid content-type",
 			headers: http.Header{
@@ -254,7 +422,7 @@ func TestBooleans(t *testing.T) {
 				Label: "test",
 				Value: true,
 				BooleanParams: &BooleanParams{
-					ExpiresAt: time.Now().Unix() + 120,
+					ExpiresAt: Expiry(time.Now().Unix() + 120),
 					ExpiresIn: 120,
 					Id:        nil,
 				},
@@ -266,7 +434,7 @@ func TestBooleans(t *testing.T) {
 			data: Boolean{
 				Label: "test",
 				BooleanParams: &BooleanParams{
-					ExpiresAt: time.Now().Unix() + 120,
+					ExpiresAt: Expiry(time.Now().Unix() + 120),
 					ExpiresIn: -1,
 					Id:        nil,
 				},
@@ -279,7 +447,7 @@ func TestBooleans(t *testing.T) {
 				Label: "test",
 				Value: false,
 				BooleanParams: &BooleanParams{
-					ExpiresAt: time.Now().Unix() - 1,
+					ExpiresAt: Expiry(time.Now().Unix() - 1),
 					ExpiresIn: 120,
 					Id:        nil,
 				},
@@ -292,7 +460,7 @@ func TestBooleans(t *testing.T) {
 				Label: "test",
 				Value: false,
 				BooleanParams: &BooleanParams{
-					ExpiresAt: time.Now().Unix() + 120,
+					ExpiresAt: Expiry(time.Now().Unix() + 120),
 					ExpiresIn: 120,
 					Id:        &inexistentId,
 				},
@@ -360,3 +528,108 @@ func TestBooleans(t *testing.T) {
 	}
 
 }
+
+func TestComputedBooleans(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	opts, err := redis.ParseURL(os.Getenv(db.REDIS_URL_ENV))
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	rdb := redis.NewClient(opts)
+
+	operands := map[string]bool{
+		"qa_passed":           true,
+		"security_signed_off": true,
+		"freeze":              false,
+	}
+
+	for id, value := range operands {
+		if err = rdb.HSet(ctx, id, &Boolean{Label: id, Value: value}).Err(); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+
+	computed := Boolean{
+		Label:      "release_ready",
+		Expression: "qa_passed AND security_signed_off AND NOT freeze",
+	}
+
+	if err = computed.Save(rdb, ctx); err != nil {
+		t.Fatalf("Boolean.Save() error = %v", err)
+	}
+
+	assert.True(t, computed.Value)
+
+	t.Run("evaluates on get", func(t *testing.T) {
+		if _, err = ToggleBoolean(rdb, ctx, "freeze"); err != nil {
+			t.Fatalf("ToggleBoolean() error = %v", err)
+		}
+
+		b, err := GetBoolean(rdb, ctx, *computed.Id)
+		if err != nil {
+			t.Fatalf("GetBoolean() error = %v", err)
+		}
+
+		assert.False(t, b.Value)
+		assert.Equal(t, computed.Expression, b.Expression)
+	})
+
+	t.Run("is read-only", func(t *testing.T) {
+		if _, err := ToggleBoolean(rdb, ctx, *computed.Id); err == nil {
+			t.Errorf("ToggleBoolean() error = %v, wantErr %v", err, true)
+		}
+
+		update := Boolean{
+			Label:         "release_ready",
+			Value:         true,
+			BooleanParams: &BooleanParams{Id: computed.Id},
+		}
+
+		if err := update.Save(rdb, ctx); err == nil {
+			t.Errorf("Boolean.Save() error = %v, wantErr %v", err, true)
+		}
+	})
+
+	t.Run("rejects unknown references", func(t *testing.T) {
+		b := Boolean{Expression: "qa_passed AND inexistent"}
+
+		if err := b.Save(rdb, ctx); err == nil {
+			t.Errorf("Boolean.Save() error = %v, wantErr %v", err, true)
+		}
+	})
+
+	t.Run("rejects cycles", func(t *testing.T) {
+		id := "qa_passed"
+		b := Boolean{
+			Expression:    fmt.Sprintf("freeze OR %s", *computed.Id),
+			BooleanParams: &BooleanParams{Id: &id},
+		}
+
+		if err := b.Save(rdb, ctx); err == nil {
+			t.Errorf("Boolean.Save() error = %v, wantErr %v", err, true)
+		}
+	})
+
+	t.Run("reports missing references on get", func(t *testing.T) {
+		if err := DeleteBoolean(rdb, ctx, "security_signed_off"); err != nil {
+			t.Fatalf("DeleteBoolean() error = %v", err)
+		}
+
+		if _, err := GetBoolean(rdb, ctx, *computed.Id); err == nil {
+			t.Errorf("GetBoolean() error = %v, wantErr %v", err, true)
+		}
+	})
+}
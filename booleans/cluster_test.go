@@ -0,0 +1,155 @@
+package booleans
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCluster(t *testing.T) {
+	ctx := context.Background()
+
+	client, err := test.CreateCluster(ctx, t, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []string{}
+
+	for i := 0; i < 20; i++ {
+		b := &Boolean{Label: fmt.Sprintf("boolean %d", i), Value: i%2 == 0}
+
+		if err = b.Save(client, ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		ids = append(ids, *b.Id)
+	}
+
+	value := true
+
+	for _, id := range ids[:5] {
+		s := &Schedule{BooleanId: id, At: time.Now().Unix() + 3600, Set: &value}
+
+		if err = s.Save(client, ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("lists booleans of all masters", func(t *testing.T) {
+		got := []string{}
+		params := &ListParams{Count: 5}
+
+		for i := 0; i == 0 || params.Cursor != 0; i++ {
+			var bools []*Boolean
+			if bools, params.Cursor, err = ListBooleans(client, ctx, params); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, b := range bools {
+				got = append(got, *b.Id)
+			}
+
+			if i > 100 {
+				t.Fatal("listing did not finish")
+			}
+		}
+
+		assert.ElementsMatch(t, ids, got)
+	})
+
+	t.Run("verifies keys of all masters", func(t *testing.T) {
+		keys := 0
+
+		if err = ScanKeys(client, ctx, func(key string) error {
+			keys++
+
+			issues, err := VerifyKey(client, ctx, key)
+			assert.Empty(t, issues)
+
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		// booleans, schedules, their indexes and the due schedules
+		assert.Equal(t, 20+5+5+1, keys)
+	})
+
+	t.Run("leases and toggles", func(t *testing.T) {
+		lease, err := AcquireLease(client, ctx, ids[1], &BooleanParams{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.NoError(t, ReleaseLease(client, ctx, ids[1], lease.Token))
+
+		b, err := ToggleBoolean(client, ctx, ids[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.True(t, b.Value)
+	})
+
+	t.Run("runs due schedules", func(t *testing.T) {
+		applied, err := RunDueSchedules(client, ctx, time.Now().Add(2*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, 5, applied)
+
+		for _, id := range ids[:5] {
+			b, err := GetBoolean(client, ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.True(t, b.Value)
+		}
+	})
+
+	t.Run("deletes booleans with schedules", func(t *testing.T) {
+		s := &Schedule{BooleanId: ids[0], At: time.Now().Unix() + 3600, Toggle: true}
+
+		if err = s.Save(client, ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.NoError(t, DeleteBoolean(client, ctx, ids[0]))
+
+		assert.Equal(t, int64(0), client.Exists(ctx, ids[0]).Val())
+		assert.Equal(t, int64(0), client.Exists(ctx, booleanSchedulesKey(ids[0])).Val())
+		assert.Equal(t, int64(0), client.Exists(ctx, scheduleKey(s.Id)).Val())
+
+		orphans, err := FindOrphans(client, ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Empty(t, orphans)
+	})
+
+	t.Run("exports and imports booleans of all masters", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		if err = ExportBooleans(client, ctx, FORMAT_NDJSON, &buf); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, 19, bytes.Count(buf.Bytes(), []byte("\n")))
+
+		result, err := ImportBooleans(client, ctx, &ImportParams{Mode: IMPORT_MODE_OVERWRITE, Format: FORMAT_NDJSON}, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, 19, result.Imported)
+	})
+}
@@ -0,0 +1,339 @@
+package booleans
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	SHADOW_KEY_PREFIX = "shadow:"
+
+	// EXPIRED_EVENTS_CHANNEL receives an ExpiredEvent as JSON for every
+	// expired boolean. Like keys, it is prefixed with the key prefix.
+	EXPIRED_EVENTS_CHANNEL = "events:expired"
+
+	EVENT_EXPIRED = "expired"
+
+	// SHADOW_GRACE is the time the shadow copy outlives its boolean, so that
+	// the expiry is still reported, if Redis or the worker lag behind.
+	SHADOW_GRACE = time.Hour
+
+	// WATCH_NODES_INTERVAL is the interval, in which WatchExpired looks for
+	// new masters of a cluster.
+	WATCH_NODES_INTERVAL = time.Minute
+
+	// KEYSPACE_EVENTS is the notify-keyspace-events setting, which needs to
+	// include the keyevent (E) and expired (x) flags.
+	KEYSPACE_EVENTS = "notify-keyspace-events"
+)
+
+// ExpiredEvent reports a boolean, which expired, with its last known state.
+type ExpiredEvent struct {
+	Type      string   `json:"type"`
+	Id        string   `json:"id"`
+	ExpiredAt int64    `json:"expired_at"`
+	Boolean   *Boolean `json:"boolean"`
+}
+
+// syncShadowScript copies an expiring boolean to its shadow key, which
+// expires SHADOW_GRACE later, or deletes the shadow key, if the boolean does
+// not expire (anymore).
+var syncShadowScript = redis.NewScript(`
+local ttl = redis.call("PTTL", KEYS[1])
+
+if ttl < 0 then
+	return redis.call("DEL", KEYS[2])
+end
+
+local fields = redis.call("HGETALL", KEYS[1])
+
+redis.call("DEL", KEYS[2])
+redis.call("HSET", KEYS[2], unpack(fields))
+redis.call("PEXPIRE", KEYS[2], ttl + tonumber(ARGV[1]))
+return 1
+`)
+
+// claimShadowScript returns and deletes the shadow copy, so that an expiry is
+// reported once, even by multiple workers. It returns nil, if the boolean was
+// created again under the same ID in the meantime.
+var claimShadowScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[2]) == 1 then
+	return nil
+end
+
+local fields = redis.call("HGETALL", KEYS[1])
+
+redis.call("DEL", KEYS[1])
+return fields
+`)
+
+// shadowKey shares the cluster slot of the boolean, so that scripts can
+// access both.
+func shadowKey(id string) string {
+	return SHADOW_KEY_PREFIX + "{" + id + "}"
+}
+
+// syncShadow updates the shadow copy of the boolean after a write. Failures
+// are only logged, as the write itself succeeded.
+func syncShadow(client redis.Scripter, ctx context.Context, id string) {
+	if err := syncShadowScript.Run(ctx, client, []string{id, shadowKey(id)}, SHADOW_GRACE.Milliseconds()).Err(); err != nil {
+		slog.WarnContext(ctx, "failed to update shadow copy", "error", err, "id", id)
+	}
+}
+
+// expiredChannel returns the keyevent channel of expired keys of the
+// database of the client, which is always 0 on a cluster.
+func expiredChannel(client redis.UniversalClient) string {
+	db := 0
+
+	if c, ok := client.(*redis.Client); ok {
+		db = c.Options().DB
+	}
+
+	return fmt.Sprintf("__keyevent@%d__:expired", db)
+}
+
+// EnableExpiredNotifications adds the expired keyevents to the
+// notify-keyspace-events setting of Redis, on every master of a cluster.
+// Managed services may refuse CONFIG, then the setting has to be changed
+// beforehand.
+func EnableExpiredNotifications(client redis.UniversalClient, ctx context.Context) error {
+	cluster, ok := client.(*redis.ClusterClient)
+	if !ok {
+		return enableNodeExpiredNotifications(client, ctx)
+	}
+
+	return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		return enableNodeExpiredNotifications(node, ctx)
+	})
+}
+
+func enableNodeExpiredNotifications(client redis.Cmdable, ctx context.Context) error {
+	config, err := client.ConfigGet(ctx, KEYSPACE_EVENTS).Result()
+	if err != nil {
+		return err
+	}
+
+	flags := config[KEYSPACE_EVENTS]
+
+	if !strings.Contains(flags, "E") {
+		flags += "E"
+	}
+
+	if !strings.ContainsAny(flags, "xA") {
+		flags += "x"
+	}
+
+	if flags == config[KEYSPACE_EVENTS] {
+		return nil
+	}
+
+	return client.ConfigSet(ctx, KEYSPACE_EVENTS, flags).Err()
+}
+
+// WatchExpired subscribes to the expired keyevents, on every master of a
+// cluster, and reports every expired boolean using HandleExpired, until ctx
+// is done. Masters added to the cluster later are subscribed to within
+// WATCH_NODES_INTERVAL. Keys are received including the key prefix, keys of
+// other prefixes are ignored.
+func WatchExpired(client redis.UniversalClient, ctx context.Context, prefix string) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	// the forwarders are stopped, before they are waited for
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	channel := expiredChannel(client)
+	messages := make(chan *redis.Message)
+	subscriptions := make(map[string]*redis.PubSub)
+
+	defer func() {
+		for _, pubsub := range subscriptions {
+			pubsub.Close()
+		}
+	}()
+
+	// subscriptions are keyed by the address of the master, or empty
+	subscribe := func(addr string, node redis.UniversalClient) error {
+		pubsub := node.Subscribe(ctx, channel)
+
+		// wait for the confirmation, so that no expiry is missed afterwards
+		if _, err := pubsub.Receive(ctx); err != nil {
+			pubsub.Close()
+
+			return fmt.Errorf("failed to subscribe to %s: %w", channel, err)
+		}
+
+		subscriptions[addr] = pubsub
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for msg := range pubsub.Channel() {
+				select {
+				case messages <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		return nil
+	}
+
+	cluster, ok := client.(*redis.ClusterClient)
+	if !ok {
+		if err = subscribe("", client); err != nil {
+			return
+		}
+	} else if err = watchMasters(cluster, ctx, subscriptions, subscribe, false); err != nil {
+		return
+	}
+
+	var refresh <-chan time.Time
+
+	if cluster != nil {
+		ticker := time.NewTicker(WATCH_NODES_INTERVAL)
+		defer ticker.Stop()
+
+		refresh = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-refresh:
+			if err := watchMasters(cluster, ctx, subscriptions, subscribe, true); err != nil {
+				slog.WarnContext(ctx, "failed to subscribe to new masters", "error", err)
+			}
+		case msg := <-messages:
+			id, ok := strings.CutPrefix(msg.Payload, prefix)
+			if !ok || !isBooleanKey(id) {
+				continue
+			}
+
+			if _, err := HandleExpired(client, ctx, id); err != nil {
+				slog.ErrorContext(ctx, "failed to report expired boolean", "error", err, "id", id)
+			}
+		}
+	}
+}
+
+// watchMasters subscribes to the masters of the cluster, which are not
+// subscribed to yet, and unsubscribes from removed ones. Notifications are
+// enabled on masters found later, as EnableExpiredNotifications only covered
+// the masters at startup.
+func watchMasters(cluster *redis.ClusterClient, ctx context.Context, subscriptions map[string]*redis.PubSub, subscribe func(addr string, node redis.UniversalClient) error, enable bool) (err error) {
+	if enable {
+		cluster.ReloadState(ctx)
+	}
+
+	var masters []*redis.Client
+	if masters, err = clusterMasters(cluster, ctx); err != nil {
+		return
+	}
+
+	current := make(map[string]bool, len(masters))
+
+	for _, master := range masters {
+		addr := master.Options().Addr
+		current[addr] = true
+
+		if _, ok := subscriptions[addr]; ok {
+			continue
+		}
+
+		if enable {
+			if err = enableNodeExpiredNotifications(master, ctx); err != nil {
+				slog.WarnContext(ctx, "failed to enable keyspace notifications", "error", err, "addr", addr)
+			}
+
+			slog.InfoContext(ctx, "subscribing to new master", "addr", addr)
+		}
+
+		if err = subscribe(addr, master); err != nil {
+			return
+		}
+	}
+
+	for addr, pubsub := range subscriptions {
+		if !current[addr] {
+			pubsub.Close()
+			delete(subscriptions, addr)
+		}
+	}
+
+	return nil
+}
+
+// HandleExpired claims the shadow copy of the expired boolean, logs it, counts
+// it in STATS_EXPIRED_KEY and publishes it to EXPIRED_EVENTS_CHANNEL. Without shadow copy, e.g. as
+// another worker claimed it, no event is returned.
+func HandleExpired(client redis.UniversalClient, ctx context.Context, id string) (event *ExpiredEvent, err error) {
+	ctx, span := startSpan(ctx, "HandleExpired", attribute.String(ATTRIBUTE_BOOLEAN_ID, id))
+	defer func() { endSpan(span, err) }()
+
+	var fields []string
+	if fields, err = claimShadowScript.Run(ctx, client, []string{shadowKey(id), id}).StringSlice(); err == redis.Nil {
+		slog.DebugContext(ctx, "boolean was created again after expiry", "id", id)
+
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(fields) == 0 {
+		slog.DebugContext(ctx, "expired boolean has no shadow copy", "id", id)
+
+		return nil, nil
+	}
+
+	values := make(map[string]string, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		values[fields[i]] = fields[i+1]
+	}
+
+	cmd := redis.NewMapStringStringCmd(ctx)
+	cmd.SetVal(values)
+
+	b := new(Boolean)
+	if err = cmd.Scan(b); err != nil {
+		return nil, err
+	}
+
+	event = &ExpiredEvent{
+		Type:      EVENT_EXPIRED,
+		Id:        id,
+		ExpiredAt: now().Unix(),
+		Boolean:   b,
+	}
+
+	slog.InfoContext(ctx, "boolean expired", "event", event.Type, "id", id, "boolean", b)
+
+	var data []byte
+	if data, err = json.Marshal(event); err != nil {
+		return nil, err
+	}
+
+	if e := client.Incr(ctx, STATS_EXPIRED_KEY).Err(); e != nil {
+		slog.WarnContext(ctx, "failed to count expired boolean", "error", e, "id", id)
+	}
+
+	if err = client.Publish(ctx, EXPIRED_EVENTS_CHANNEL, data).Err(); err != nil {
+		return nil, err
+	}
+
+	return
+}
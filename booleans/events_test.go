@@ -0,0 +1,163 @@
+package booleans
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiredEvents(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	opts, err := redis.ParseURL(os.Getenv(db.REDIS_URL_ENV))
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	rdb := redis.NewClient(opts)
+
+	t.Run("keeps shadow copy in sync", func(t *testing.T) {
+		b := Boolean{Label: "maintenance window", BooleanParams: &BooleanParams{ExpiresIn: 60}}
+		if err := b.Save(rdb, ctx); err != nil {
+			t.Fatalf("Boolean.Save() error = %v", err)
+		}
+
+		ttl := rdb.PTTL(ctx, shadowKey(*b.Id)).Val()
+		assert.Greater(t, ttl, SHADOW_GRACE)
+		assert.LessOrEqual(t, ttl, SHADOW_GRACE+time.Minute)
+		assert.Equal(t, "0", rdb.HGet(ctx, shadowKey(*b.Id), BOOLEAN_VALUE).Val())
+
+		if _, err := ToggleBoolean(rdb, ctx, *b.Id); err != nil {
+			t.Fatalf("ToggleBoolean() error = %v", err)
+		}
+
+		assert.Equal(t, "1", rdb.HGet(ctx, shadowKey(*b.Id), BOOLEAN_VALUE).Val())
+
+		if err := ResetExpiry(rdb, ctx, *b.Id, 0, 0); err != nil {
+			t.Fatalf("ResetExpiry() error = %v", err)
+		}
+
+		assert.Zero(t, rdb.Exists(ctx, shadowKey(*b.Id)).Val())
+
+		if err := ResetExpiry(rdb, ctx, *b.Id, time.Minute, 0); err != nil {
+			t.Fatalf("ResetExpiry() error = %v", err)
+		}
+
+		assert.Equal(t, int64(1), rdb.Exists(ctx, shadowKey(*b.Id)).Val())
+
+		if err := DeleteBoolean(rdb, ctx, *b.Id); err != nil {
+			t.Fatalf("DeleteBoolean() error = %v", err)
+		}
+
+		assert.Zero(t, rdb.Exists(ctx, shadowKey(*b.Id)).Val())
+	})
+
+	t.Run("skips booleans without expiry", func(t *testing.T) {
+		b := Boolean{Label: "deploy freeze"}
+		if err := b.Save(rdb, ctx); err != nil {
+			t.Fatalf("Boolean.Save() error = %v", err)
+		}
+
+		assert.Zero(t, rdb.Exists(ctx, shadowKey(*b.Id)).Val())
+	})
+
+	t.Run("reports expired booleans once", func(t *testing.T) {
+		b := Boolean{Label: "canary", Value: true, BooleanParams: &BooleanParams{ExpiresIn: 60}}
+		if err := b.Save(rdb, ctx); err != nil {
+			t.Fatalf("Boolean.Save() error = %v", err)
+		}
+
+		expired, _ := ExpiredBooleans(rdb, ctx)
+
+		events := rdb.Subscribe(ctx, EXPIRED_EVENTS_CHANNEL)
+		defer events.Close()
+
+		if _, err := events.Receive(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		watchCtx, cancel := context.WithCancel(ctx)
+
+		done := make(chan error)
+		go func() {
+			done <- WatchExpired(rdb, watchCtx, "")
+		}()
+
+		defer func() {
+			cancel()
+
+			if err := <-done; err != nil {
+				t.Errorf("WatchExpired() error = %v", err)
+			}
+		}()
+
+		channel := expiredChannel(rdb)
+
+		assert.Eventually(t, func() bool {
+			return rdb.PubSubNumSub(ctx, channel).Val()[channel] > 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// simulate the expiry, as Redis would notify it
+		rdb.Del(ctx, *b.Id)
+		rdb.Publish(ctx, channel, *b.Id)
+		rdb.Publish(ctx, channel, shadowKey(*b.Id))
+
+		msgCtx, msgCancel := context.WithTimeout(ctx, 5*time.Second)
+		defer msgCancel()
+
+		msg, err := events.ReceiveMessage(msgCtx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var event ExpiredEvent
+		if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, EVENT_EXPIRED, event.Type)
+		assert.Equal(t, *b.Id, event.Id)
+		assert.NotZero(t, event.ExpiredAt)
+		assert.Equal(t, "canary", event.Boolean.Label)
+		assert.True(t, event.Boolean.Value)
+		assert.Zero(t, rdb.Exists(ctx, shadowKey(*b.Id)).Val())
+
+		count, err := ExpiredBooleans(rdb, ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, expired+1, count)
+
+		again, err := HandleExpired(rdb, ctx, *b.Id)
+		assert.NoError(t, err)
+		assert.Nil(t, again)
+	})
+
+	t.Run("skips booleans created again", func(t *testing.T) {
+		b := Boolean{Label: "rollout", BooleanParams: &BooleanParams{ExpiresIn: 60}}
+		if err := b.Save(rdb, ctx); err != nil {
+			t.Fatalf("Boolean.Save() error = %v", err)
+		}
+
+		event, err := HandleExpired(rdb, ctx, *b.Id)
+		assert.NoError(t, err)
+		assert.Nil(t, event)
+		assert.Equal(t, int64(1), rdb.Exists(ctx, shadowKey(*b.Id)).Val())
+	})
+}
@@ -0,0 +1,76 @@
+package booleans
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Expiry is a point in time as Unix epoch in seconds. It is parsed from
+// either the epoch or an RFC 3339 timestamp, e.g. 2026-12-31T23:00:00Z.
+type Expiry int64
+
+// Lifetime is a duration in seconds. It is parsed from either the number of
+// seconds or a Go duration, e.g. 1h30m, which is rounded down to seconds.
+type Lifetime int64
+
+func (e *Expiry) UnmarshalText(text []byte) error {
+	if epoch, err := strconv.ParseInt(string(text), 10, 64); err == nil {
+		*e = Expiry(epoch)
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, string(text))
+	if err != nil {
+		return fmt.Errorf("%q is neither a Unix epoch nor an RFC 3339 timestamp", text)
+	}
+
+	*e = Expiry(t.Unix())
+
+	return nil
+}
+
+func (e *Expiry) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONText(data, e.UnmarshalText)
+}
+
+func (t *Lifetime) UnmarshalText(text []byte) error {
+	if seconds, err := strconv.ParseInt(string(text), 10, 64); err == nil {
+		*t = Lifetime(seconds)
+		return nil
+	}
+
+	d, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("%q is neither a number of seconds nor a duration", text)
+	}
+
+	*t = Lifetime(d / time.Second)
+
+	return nil
+}
+
+func (t *Lifetime) UnmarshalJSON(data []byte) error {
+	return unmarshalJSONText(data, t.UnmarshalText)
+}
+
+// unmarshalJSONText passes JSON numbers and strings to unmarshal, null is
+// ignored.
+func unmarshalJSONText(data []byte, unmarshal func([]byte) error) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+
+		return unmarshal([]byte(s))
+	}
+
+	return unmarshal(data)
+}
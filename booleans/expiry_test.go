@@ -0,0 +1,85 @@
+package booleans
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    Expiry
+		wantErr bool
+	}{
+		{name: "epoch", text: "1798758000", want: 1798758000},
+		{name: "rfc 3339", text: "2026-12-31T23:00:00Z", want: 1798758000},
+		{name: "rfc 3339 with offset", text: "2027-01-01T00:00:00+01:00", want: 1798758000},
+		{name: "date only", text: "2026-12-31", wantErr: true},
+		{name: "empty", text: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var e Expiry
+
+			if err := e.UnmarshalText([]byte(tc.text)); (err != nil) != tc.wantErr {
+				t.Fatalf("Expiry.UnmarshalText() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			assert.Equal(t, tc.want, e)
+		})
+	}
+}
+
+func TestLifetime(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    Lifetime
+		wantErr bool
+	}{
+		{name: "seconds", text: "90", want: 90},
+		{name: "duration", text: "1h30m", want: 5400},
+		{name: "duration rounded down", text: "1500ms", want: 1},
+		{name: "missing unit", text: "1.5", wantErr: true},
+		{name: "empty", text: "", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var l Lifetime
+
+			if err := l.UnmarshalText([]byte(tc.text)); (err != nil) != tc.wantErr {
+				t.Fatalf("Lifetime.UnmarshalText() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			assert.Equal(t, tc.want, l)
+		})
+	}
+}
+
+func TestExpiryJSON(t *testing.T) {
+	var body struct {
+		ExpiresAt Expiry   `json:"expires_at"`
+		ExpiresIn Lifetime `json:"expires_in"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"expires_at":1798758000,"expires_in":"1h"}`), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, Expiry(1798758000), body.ExpiresAt)
+	assert.Equal(t, Lifetime(3600), body.ExpiresIn)
+
+	if err := json.Unmarshal([]byte(`{"expires_at":"2026-12-31T23:00:00Z","expires_in":120}`), &body); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, Expiry(1798758000), body.ExpiresAt)
+	assert.Equal(t, Lifetime(120), body.ExpiresIn)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"expires_in":true}`), &body))
+}
@@ -0,0 +1,310 @@
+package booleans
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	OPERATOR_AND = "AND"
+	OPERATOR_OR  = "OR"
+	OPERATOR_NOT = "NOT"
+	OPERATOR_XOR = "XOR"
+
+	MAX_EXPRESSION_DEPTH = 32
+)
+
+// expressionError marks failures caused by the contents of an expression,
+// e.g. unknown references or cycles, as opposed to storage failures.
+type expressionError struct {
+	message string
+}
+
+func (e *expressionError) Error() string {
+	return e.message
+}
+
+type expression interface {
+	evaluate(lookup func(id string) (bool, error)) (bool, error)
+}
+
+type identifierExpression struct {
+	id string
+}
+
+func (e *identifierExpression) evaluate(lookup func(id string) (bool, error)) (bool, error) {
+	return lookup(e.id)
+}
+
+type notExpression struct {
+	operand expression
+}
+
+func (e *notExpression) evaluate(lookup func(id string) (bool, error)) (value bool, err error) {
+	if value, err = e.operand.evaluate(lookup); err != nil {
+		return
+	}
+
+	return !value, nil
+}
+
+type binaryExpression struct {
+	operator    string
+	left, right expression
+}
+
+func (e *binaryExpression) evaluate(lookup func(id string) (bool, error)) (value bool, err error) {
+	var left, right bool
+
+	if left, err = e.left.evaluate(lookup); err != nil {
+		return
+	}
+
+	if right, err = e.right.evaluate(lookup); err != nil {
+		return
+	}
+
+	switch e.operator {
+	case OPERATOR_AND:
+		value = left && right
+	case OPERATOR_OR:
+		value = left || right
+	case OPERATOR_XOR:
+		value = left != right
+	default:
+		err = fmt.Errorf("unknown operator: %s", e.operator)
+	}
+
+	return
+}
+
+func tokenizeExpression(input string) (tokens []string, err error) {
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+		case isIdentifierRune(r):
+			start := i
+
+			for i < len(runes) && isIdentifierRune(runes[i]) {
+				i++
+			}
+
+			tokens = append(tokens, string(runes[start:i]))
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+		}
+	}
+
+	return
+}
+
+func isIdentifierRune(r rune) bool {
+	return r == '_' || r == '-' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// expressionParser is a recursive descent parser for boolean expressions,
+// binding NOT tighter than AND, AND tighter than XOR and XOR tighter than OR.
+type expressionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *expressionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return ""
+}
+
+func (p *expressionParser) next() string {
+	token := p.peek()
+	p.pos++
+
+	return token
+}
+
+func (p *expressionParser) parseBinary(operator string, operand func(depth int) (expression, error), depth int) (e expression, err error) {
+	if e, err = operand(depth); err != nil {
+		return
+	}
+
+	for strings.EqualFold(p.peek(), operator) {
+		p.next()
+
+		var right expression
+		if right, err = operand(depth); err != nil {
+			return
+		}
+
+		e = &binaryExpression{operator: operator, left: e, right: right}
+	}
+
+	return
+}
+
+func (p *expressionParser) parseOr(depth int) (expression, error) {
+	return p.parseBinary(OPERATOR_OR, p.parseXor, depth)
+}
+
+func (p *expressionParser) parseXor(depth int) (expression, error) {
+	return p.parseBinary(OPERATOR_XOR, p.parseAnd, depth)
+}
+
+func (p *expressionParser) parseAnd(depth int) (expression, error) {
+	return p.parseBinary(OPERATOR_AND, p.parseUnary, depth)
+}
+
+func (p *expressionParser) parseUnary(depth int) (e expression, err error) {
+	if depth > MAX_EXPRESSION_DEPTH {
+		return nil, fmt.Errorf("expression exceeds maximum nesting depth of %d", MAX_EXPRESSION_DEPTH)
+	}
+
+	token := p.next()
+
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of expression")
+	case strings.EqualFold(token, OPERATOR_NOT):
+		var operand expression
+		if operand, err = p.parseUnary(depth + 1); err != nil {
+			return
+		}
+
+		return &notExpression{operand: operand}, nil
+	case token == "(":
+		if e, err = p.parseOr(depth + 1); err != nil {
+			return
+		}
+
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+
+		return
+	case token == ")" || isOperator(token):
+		return nil, fmt.Errorf("unexpected token %q", token)
+	default:
+		return &identifierExpression{id: token}, nil
+	}
+}
+
+func isOperator(token string) bool {
+	for _, operator := range []string{OPERATOR_AND, OPERATOR_OR, OPERATOR_NOT, OPERATOR_XOR} {
+		if strings.EqualFold(token, operator) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseExpression(input string) (e expression, err error) {
+	var tokens []string
+	if tokens, err = tokenizeExpression(input); err != nil {
+		return
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	p := &expressionParser{tokens: tokens}
+
+	if e, err = p.parseOr(0); err != nil {
+		return
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %q", p.peek())
+	}
+
+	return
+}
+
+// evaluateExpression resolves all booleans referenced by input and returns the
+// resulting value. When self is set, any reference back to it is reported as
+// a cycle.
+func evaluateExpression(client redis.UniversalClient, ctx context.Context, input string, self *string) (value bool, err error) {
+	return resolveExpression(input, self, func(id string) (ref *Boolean, err error) {
+		cmd := client.HGetAll(ctx, id)
+		if err = cmd.Err(); err != nil {
+			return
+		}
+
+		if len(cmd.Val()) == 0 {
+			return nil, nil
+		}
+
+		ref = new(Boolean)
+		if err = cmd.Scan(ref); err != nil {
+			return nil, err
+		}
+
+		return
+	})
+}
+
+// resolveExpression evaluates input like evaluateExpression, but reads the
+// referenced booleans using read, which returns nil for unknown IDs.
+func resolveExpression(input string, self *string, read func(id string) (*Boolean, error)) (value bool, err error) {
+	var e expression
+	if e, err = parseExpression(input); err != nil {
+		return false, &expressionError{message: err.Error()}
+	}
+
+	stack := make(map[string]bool)
+
+	if self != nil {
+		stack[*self] = true
+	}
+
+	var lookup func(id string) (bool, error)
+
+	lookup = func(id string) (value bool, err error) {
+		if stack[id] {
+			return false, &expressionError{message: fmt.Sprintf("cycle detected at boolean with ID %s", id)}
+		}
+
+		var ref *Boolean
+		if ref, err = read(id); err != nil {
+			return
+		}
+
+		if ref == nil {
+			return false, &expressionError{message: fmt.Sprintf("referenced boolean with ID %s not found", id)}
+		}
+
+		if ref.Expression == "" {
+			if ref.LeaseExpiresAt > 0 && ref.LeaseExpiresAt <= now().Unix() {
+				return false, nil
+			}
+
+			return ref.Value, nil
+		}
+
+		var nested expression
+		if nested, err = parseExpression(ref.Expression); err != nil {
+			return false, &expressionError{message: fmt.Sprintf("boolean with ID %s: %s", id, err)}
+		}
+
+		stack[id] = true
+		defer delete(stack, id)
+
+		return nested.evaluate(lookup)
+	}
+
+	return e.evaluate(lookup)
+}
@@ -0,0 +1,130 @@
+package booleans
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpression(t *testing.T) {
+	values := map[string]bool{
+		"qa_passed":           true,
+		"security_signed_off": true,
+		"freeze":              false,
+	}
+
+	lookup := func(id string) (bool, error) {
+		value, ok := values[id]
+		if !ok {
+			return false, fmt.Errorf("unknown id: %s", id)
+		}
+
+		return value, nil
+	}
+
+	type test struct {
+		name    string
+		input   string
+		want    bool
+		wantErr bool
+	}
+
+	tests := []test{
+		{
+			name:  "single identifier",
+			input: "qa_passed",
+			want:  true,
+		},
+		{
+			name:  "and with not",
+			input: "qa_passed AND security_signed_off AND NOT freeze",
+			want:  true,
+		},
+		{
+			name:  "lowercase operators",
+			input: "qa_passed and not freeze",
+			want:  true,
+		},
+		{
+			name:  "or",
+			input: "freeze OR qa_passed",
+			want:  true,
+		},
+		{
+			name:  "xor",
+			input: "qa_passed XOR security_signed_off",
+			want:  false,
+		},
+		{
+			name:  "and binds tighter than or",
+			input: "freeze AND qa_passed OR security_signed_off",
+			want:  true,
+		},
+		{
+			name:  "parentheses override precedence",
+			input: "freeze AND (qa_passed OR security_signed_off)",
+			want:  false,
+		},
+		{
+			name:  "double negation",
+			input: "NOT NOT freeze",
+			want:  false,
+		},
+		{
+			name:    "empty expression",
+			input:   "   ",
+			wantErr: true,
+		},
+		{
+			name:    "dangling operator",
+			input:   "qa_passed AND",
+			wantErr: true,
+		},
+		{
+			name:    "missing closing parenthesis",
+			input:   "(qa_passed OR freeze",
+			wantErr: true,
+		},
+		{
+			name:    "unexpected closing parenthesis",
+			input:   "qa_passed)",
+			wantErr: true,
+		},
+		{
+			name:    "missing operator",
+			input:   "qa_passed freeze",
+			wantErr: true,
+		},
+		{
+			name:    "invalid character",
+			input:   "qa_passed && freeze",
+			wantErr: true,
+		},
+		{
+			name:    "exceeds maximum depth",
+			input:   strings.Repeat("(", MAX_EXPRESSION_DEPTH+2) + "freeze" + strings.Repeat(")", MAX_EXPRESSION_DEPTH+2),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := parseExpression(tc.input)
+
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("parseExpression() error = %v, wantErr %v", err, tc.wantErr)
+			}
+
+			if !tc.wantErr {
+				got, err := e.evaluate(lookup)
+				if err != nil {
+					t.Fatalf("expression.evaluate() error = %v", err)
+				}
+
+				assert.Equal(t, tc.want, got)
+			}
+		})
+	}
+}
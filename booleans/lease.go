@@ -0,0 +1,392 @@
+package booleans
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	DEFAULT_LEASE_TTL = 60
+
+	LEASE_TOKEN_HEADER = "X-Lease-Token"
+
+	// LEASES_DUE_KEY indexes the leased booleans by the expiry of their
+	// lease, so that RevertExpiredLeases finds them.
+	LEASES_DUE_KEY = "leases:due"
+
+	LEASES_BATCH_SIZE = 100
+)
+
+const (
+	leaseNotFound int64 = iota - 3
+	leaseComputed
+	leaseRejected
+)
+
+// acquireLeaseScript sets the boolean to true, if it is currently false or its
+// previous lease has expired, and records the lease owner and expiry. The
+// lease ends with the boolean at the latest, its expiry is returned.
+var acquireLeaseScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return -3
+end
+
+local expression = redis.call("HGET", KEYS[1], "expression")
+if expression and expression ~= "" then
+	return -2
+end
+
+local value = redis.call("HGET", KEYS[1], "value")
+local expires = tonumber(redis.call("HGET", KEYS[1], "lease_expires_at") or "0")
+
+if expires > 0 and expires <= tonumber(ARGV[3]) then
+	value = "0"
+end
+
+if value == "1" then
+	return -1
+end
+
+local lease = tonumber(ARGV[2])
+local expiry = redis.call("EXPIRETIME", KEYS[1])
+
+if expiry > 0 and expiry < lease then
+	lease = expiry
+end
+
+redis.call("HSET", KEYS[1], "value", "1", "lease_owner", ARGV[1], "lease_expires_at", lease)
+return lease
+`)
+
+// renewLeaseScript extends an active lease held by the given owner, up to the
+// expiry of the boolean, and returns the new expiry.
+var renewLeaseScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return -3
+end
+
+local expires = tonumber(redis.call("HGET", KEYS[1], "lease_expires_at") or "0")
+
+if redis.call("HGET", KEYS[1], "lease_owner") ~= ARGV[1] or expires <= tonumber(ARGV[3]) then
+	return -1
+end
+
+local lease = tonumber(ARGV[2])
+local expiry = redis.call("EXPIRETIME", KEYS[1])
+
+if expiry > 0 and expiry < lease then
+	lease = expiry
+end
+
+redis.call("HSET", KEYS[1], "lease_expires_at", lease)
+return lease
+`)
+
+// releaseLeaseScript reverts the boolean to false, if the lease is held by
+// the given owner.
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return -3
+end
+
+local expires = tonumber(redis.call("HGET", KEYS[1], "lease_expires_at") or "0")
+
+if redis.call("HGET", KEYS[1], "lease_owner") ~= ARGV[1] or expires <= tonumber(ARGV[2]) then
+	return -1
+end
+
+redis.call("HSET", KEYS[1], "value", "0")
+redis.call("HDEL", KEYS[1], "lease_owner", "lease_expires_at")
+return 0
+`)
+
+// revertExpiredLeaseScript reverts the boolean to false, if its lease has
+// expired in the meantime.
+var revertExpiredLeaseScript = redis.NewScript(`
+local expires = tonumber(redis.call("HGET", KEYS[1], "lease_expires_at") or "0")
+
+if expires == 0 or expires > tonumber(ARGV[1]) then
+	return -1
+end
+
+redis.call("HSET", KEYS[1], "value", "0")
+redis.call("HDEL", KEYS[1], "lease_owner", "lease_expires_at")
+return 0
+`)
+
+// deleteBooleanScript deletes the boolean along with the given keys, unless
+// it has an active lease.
+var deleteBooleanScript = redis.NewScript(`
+local expires = tonumber(redis.call("HGET", KEYS[1], "lease_expires_at") or "0")
+
+if expires > tonumber(ARGV[1]) then
+	return -1
+end
+
+redis.call("DEL", unpack(KEYS))
+return 0
+`)
+
+// removeDueLeaseScript removes the boolean from the lease index, unless its
+// lease was renewed in the meantime.
+var removeDueLeaseScript = redis.NewScript(`
+local expires = redis.call("ZSCORE", KEYS[1], ARGV[1])
+
+if expires and tonumber(expires) <= tonumber(ARGV[2]) then
+	return redis.call("ZREM", KEYS[1], ARGV[1])
+end
+
+return 0
+`)
+
+type leaseResponse struct {
+	Data *Lease `json:"data"`
+}
+
+type Lease struct {
+	Id        string `json:"id"`
+	Token     string `json:"token,omitempty"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+func generateLeaseToken() (token string, err error) {
+	data := make([]byte, 16)
+
+	if _, err = rand.Read(data); err != nil {
+		return
+	}
+
+	return hex.EncodeToString(data), nil
+}
+
+// LimitLeaseTTL rejects lease expiries beyond max from now, like LimitTTL
+// does for writes. Leases without requested expiry expire after
+// DEFAULT_LEASE_TTL seconds, or after max, if it is shorter.
+func (b *BooleanParams) LimitLeaseTTL(max time.Duration) error {
+	def := DEFAULT_LEASE_TTL * time.Second
+
+	if max > 0 && max < def {
+		def = max
+	}
+
+	return b.LimitTTL(max, def)
+}
+
+func leaseExpiresAt(params *BooleanParams) int64 {
+	if expiresAt := params.expiresAt(); expiresAt > 0 {
+		return expiresAt
+	}
+
+	return now().Unix() + DEFAULT_LEASE_TTL
+}
+
+func leaseResultError(ctx context.Context, result int64, id string) error {
+	switch result {
+	case leaseNotFound:
+		slog.DebugContext(ctx, "boolean not found", "id", id)
+
+		return errors.NewHTTPError(http.StatusNotFound, &errors.NOT_FOUND_ERROR)
+	case leaseComputed:
+		slog.DebugContext(ctx, "boolean is computed and cannot be leased", "id", id)
+
+		return errors.NewHTTPError(http.StatusConflict, &errors.CONFLICT_ERROR)
+	case leaseRejected:
+		slog.DebugContext(ctx, "lease rejected", "id", id)
+
+		return errors.NewHTTPError(http.StatusConflict, &errors.CONFLICT_ERROR)
+	}
+
+	return nil
+}
+
+// AcquireLease atomically sets the boolean to true, if it is false, and
+// returns the lease including the owner token needed to renew or release it.
+// The lease expires according to params, or after DEFAULT_LEASE_TTL seconds,
+// but not after the boolean.
+func AcquireLease(client redis.UniversalClient, ctx context.Context, id string, params *BooleanParams) (l *Lease, err error) {
+	ctx, span := startSpan(ctx, "AcquireLease", attribute.String(ATTRIBUTE_BOOLEAN_ID, id))
+	defer func() { endSpan(span, err) }()
+
+	var token string
+	if token, err = generateLeaseToken(); err != nil {
+		slog.ErrorContext(ctx, "failed to generate lease token", "error", err, "id", id)
+
+		return nil, errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+	}
+
+	l = &Lease{
+		Id:        id,
+		Token:     token,
+		ExpiresAt: leaseExpiresAt(params),
+	}
+
+	var result int64
+	if result, err = acquireLeaseScript.Run(ctx, client, []string{id}, l.Token, l.ExpiresAt, now().Unix()).Int64(); err != nil {
+		slog.ErrorContext(ctx, "failed to acquire lease", "error", err, "id", id)
+
+		return nil, errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+	}
+
+	if err = leaseResultError(ctx, result, id); err != nil {
+		return nil, err
+	}
+
+	l.ExpiresAt = result
+
+	indexLease(client, ctx, l)
+	syncShadow(client, ctx, id)
+
+	return
+}
+
+// RenewLease extends the active lease held by token according to params.
+func RenewLease(client redis.UniversalClient, ctx context.Context, id, token string, params *BooleanParams) (l *Lease, err error) {
+	ctx, span := startSpan(ctx, "RenewLease", attribute.String(ATTRIBUTE_BOOLEAN_ID, id))
+	defer func() { endSpan(span, err) }()
+
+	l = &Lease{
+		Id:        id,
+		ExpiresAt: leaseExpiresAt(params),
+	}
+
+	var result int64
+	if result, err = renewLeaseScript.Run(ctx, client, []string{id}, token, l.ExpiresAt, now().Unix()).Int64(); err != nil {
+		slog.ErrorContext(ctx, "failed to renew lease", "error", err, "id", id)
+
+		return nil, errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+	}
+
+	if err = leaseResultError(ctx, result, id); err != nil {
+		return nil, err
+	}
+
+	l.ExpiresAt = result
+
+	indexLease(client, ctx, l)
+	syncShadow(client, ctx, id)
+
+	return
+}
+
+// ReleaseLease reverts the boolean to false, if the lease is held by token.
+func ReleaseLease(client redis.UniversalClient, ctx context.Context, id, token string) (err error) {
+	ctx, span := startSpan(ctx, "ReleaseLease", attribute.String(ATTRIBUTE_BOOLEAN_ID, id))
+	defer func() { endSpan(span, err) }()
+
+	var result int64
+	if result, err = releaseLeaseScript.Run(ctx, client, []string{id}, token, now().Unix()).Int64(); err != nil {
+		slog.ErrorContext(ctx, "failed to release lease", "error", err, "id", id)
+
+		return errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+	}
+
+	if err = leaseResultError(ctx, result, id); err != nil {
+		return err
+	}
+
+	if err = client.ZRem(ctx, LEASES_DUE_KEY, id).Err(); err != nil {
+		slog.WarnContext(ctx, "failed to remove lease from index", "error", err, "id", id)
+	}
+
+	syncShadow(client, ctx, id)
+
+	return nil
+}
+
+// indexLease adds the lease to LEASES_DUE_KEY. Failures are only logged, as
+// the lease is reverted on the next read of the boolean as well.
+func indexLease(client redis.UniversalClient, ctx context.Context, l *Lease) {
+	if err := client.ZAdd(ctx, LEASES_DUE_KEY, redis.Z{Score: float64(l.ExpiresAt), Member: l.Id}).Err(); err != nil {
+		slog.WarnContext(ctx, "failed to index lease", "error", err, "id", l.Id)
+	}
+}
+
+// RevertExpiredLeases reverts all booleans, whose lease expired at the given
+// time, to false, so that lists, scans and shadow copies do not report them
+// as leased anymore. It is run along with the due schedules.
+func RevertExpiredLeases(client redis.UniversalClient, ctx context.Context, now time.Time) (reverted int, err error) {
+	ctx, span := startSpan(ctx, "RevertExpiredLeases")
+	defer func() {
+		span.SetAttributes(attribute.Int("baas.leases.reverted", reverted))
+
+		endSpan(span, err)
+	}()
+
+	for {
+		var ids []string
+		if ids, err = client.ZRangeByScore(ctx, LEASES_DUE_KEY, &redis.ZRangeBy{
+			Min:   "-inf",
+			Max:   strconv.FormatInt(now.Unix(), 10),
+			Count: LEASES_BATCH_SIZE,
+		}).Result(); err != nil {
+			return
+		}
+
+		if len(ids) == 0 {
+			return
+		}
+
+		for _, id := range ids {
+			var result int64
+			if result, err = revertExpiredLeaseScript.Run(ctx, client, []string{id}, now.Unix()).Int64(); err != nil {
+				return
+			}
+
+			if result == 0 {
+				syncShadow(client, ctx, id)
+
+				reverted++
+			}
+
+			if err = removeDueLeaseScript.Run(ctx, client, []string{LEASES_DUE_KEY}, id, now.Unix()).Err(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func revertExpiredLease(client redis.UniversalClient, ctx context.Context, id string) error {
+	if err := revertExpiredLeaseScript.Run(ctx, client, []string{id}, now().Unix()).Err(); err != nil {
+		return err
+	}
+
+	syncShadow(client, ctx, id)
+
+	return nil
+}
+
+func ParseLeaseParams(r *http.Request) (params *BooleanParams, err error) {
+	params = new(BooleanParams)
+
+	if err = decoder.Decode(params, r.URL.Query()); err != nil {
+		slog.DebugContext(r.Context(), "failed to decode query", "error", err)
+
+		return nil, errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+	}
+
+	// the ID is taken from the path
+	params.Id = nil
+
+	if err = params.Validate(); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func CreateLeaseResponse(l *Lease) (body *leaseResponse) {
+	body = &leaseResponse{
+		Data: l,
+	}
+
+	return
+}
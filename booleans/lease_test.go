@@ -0,0 +1,265 @@
+package booleans
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/errors"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeases(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	opts, err := redis.ParseURL(os.Getenv(db.REDIS_URL_ENV))
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	rdb := redis.NewClient(opts)
+
+	clock := time.Now()
+
+	now = func() time.Time { return clock }
+
+	t.Cleanup(func() {
+		now = time.Now
+	})
+
+	b := Boolean{Label: "deploy in progress"}
+
+	if err = b.Save(rdb, ctx); err != nil {
+		t.Fatalf("Boolean.Save() error = %v", err)
+	}
+
+	lease, err := AcquireLease(rdb, ctx, *b.Id, &BooleanParams{ExpiresIn: 30})
+	if err != nil {
+		t.Fatalf("AcquireLease() error = %v", err)
+	}
+
+	assert.NotEmpty(t, lease.Token)
+	assert.Equal(t, clock.Unix()+30, lease.ExpiresAt)
+
+	t.Run("sets the boolean to true", func(t *testing.T) {
+		got, err := GetBoolean(rdb, ctx, *b.Id)
+		if err != nil {
+			t.Fatalf("GetBoolean() error = %v", err)
+		}
+
+		assert.True(t, got.Value)
+		assert.Equal(t, lease.ExpiresAt, got.LeaseExpiresAt)
+	})
+
+	t.Run("rejects a second acquire", func(t *testing.T) {
+		if _, err := AcquireLease(rdb, ctx, *b.Id, &BooleanParams{}); err == nil {
+			t.Errorf("AcquireLease() error = %v, wantErr %v", err, true)
+		}
+	})
+
+	t.Run("rejects modifications while leased", func(t *testing.T) {
+		if _, err := ToggleBoolean(rdb, ctx, *b.Id); err == nil {
+			t.Errorf("ToggleBoolean() error = %v, wantErr %v", err, true)
+		}
+
+		update := Boolean{BooleanParams: &BooleanParams{Id: b.Id}}
+
+		if err := update.Save(rdb, ctx); err == nil {
+			t.Errorf("Boolean.Save() error = %v, wantErr %v", err, true)
+		}
+
+		err := DeleteBoolean(rdb, ctx, *b.Id)
+		if httpErr, ok := err.(*errors.HTTPError); !ok || httpErr.Status != http.StatusConflict {
+			t.Errorf("DeleteBoolean() error = %v, want %d", err, http.StatusConflict)
+		}
+
+		assert.Equal(t, int64(1), rdb.Exists(ctx, *b.Id).Val())
+	})
+
+	t.Run("renews and releases by the owner only", func(t *testing.T) {
+		if _, err := RenewLease(rdb, ctx, *b.Id, "not-the-owner", &BooleanParams{ExpiresIn: 60}); err == nil {
+			t.Errorf("RenewLease() error = %v, wantErr %v", err, true)
+		}
+
+		renewed, err := RenewLease(rdb, ctx, *b.Id, lease.Token, &BooleanParams{ExpiresIn: 60})
+		if err != nil {
+			t.Fatalf("RenewLease() error = %v", err)
+		}
+
+		assert.Equal(t, clock.Unix()+60, renewed.ExpiresAt)
+
+		if err := ReleaseLease(rdb, ctx, *b.Id, "not-the-owner"); err == nil {
+			t.Errorf("ReleaseLease() error = %v, wantErr %v", err, true)
+		}
+
+		if err := ReleaseLease(rdb, ctx, *b.Id, lease.Token); err != nil {
+			t.Fatalf("ReleaseLease() error = %v", err)
+		}
+
+		got, err := GetBoolean(rdb, ctx, *b.Id)
+		if err != nil {
+			t.Fatalf("GetBoolean() error = %v", err)
+		}
+
+		assert.False(t, got.Value)
+		assert.Zero(t, got.LeaseExpiresAt)
+	})
+
+	t.Run("reverts to false when the lease expires", func(t *testing.T) {
+		expiring, err := AcquireLease(rdb, ctx, *b.Id, &BooleanParams{ExpiresIn: 10})
+		if err != nil {
+			t.Fatalf("AcquireLease() error = %v", err)
+		}
+
+		clock = clock.Add(11 * time.Second)
+
+		got, err := GetBoolean(rdb, ctx, *b.Id)
+		if err != nil {
+			t.Fatalf("GetBoolean() error = %v", err)
+		}
+
+		assert.False(t, got.Value)
+
+		if _, err := RenewLease(rdb, ctx, *b.Id, expiring.Token, &BooleanParams{}); err == nil {
+			t.Errorf("RenewLease() error = %v, wantErr %v", err, true)
+		}
+
+		if _, err := AcquireLease(rdb, ctx, *b.Id, &BooleanParams{}); err != nil {
+			t.Errorf("AcquireLease() error = %v", err)
+		}
+	})
+
+	t.Run("reverts expired leases in the background", func(t *testing.T) {
+		e, err := exportBoolean(rdb, ctx, *b.Id)
+		if err != nil {
+			t.Fatalf("exportBoolean() error = %v", err)
+		}
+
+		assert.True(t, e.Value)
+
+		clock = clock.Add(time.Duration(DEFAULT_LEASE_TTL+1) * time.Second)
+
+		if e, err = exportBoolean(rdb, ctx, *b.Id); err != nil {
+			t.Fatalf("exportBoolean() error = %v", err)
+		}
+
+		assert.False(t, e.Value)
+
+		reverted, err := RevertExpiredLeases(rdb, ctx, clock)
+		if err != nil {
+			t.Fatalf("RevertExpiredLeases() error = %v", err)
+		}
+
+		assert.Equal(t, 1, reverted)
+		assert.Equal(t, "0", rdb.HGet(ctx, *b.Id, BOOLEAN_VALUE).Val())
+		assert.Zero(t, rdb.ZCard(ctx, LEASES_DUE_KEY).Val())
+	})
+
+	t.Run("keeps renewed leases indexed", func(t *testing.T) {
+		l, err := AcquireLease(rdb, ctx, *b.Id, &BooleanParams{ExpiresIn: 10})
+		if err != nil {
+			t.Fatalf("AcquireLease() error = %v", err)
+		}
+
+		if _, err = RenewLease(rdb, ctx, *b.Id, l.Token, &BooleanParams{ExpiresIn: 60}); err != nil {
+			t.Fatalf("RenewLease() error = %v", err)
+		}
+
+		reverted, err := RevertExpiredLeases(rdb, ctx, clock.Add(11*time.Second))
+		if err != nil {
+			t.Fatalf("RevertExpiredLeases() error = %v", err)
+		}
+
+		assert.Zero(t, reverted)
+		assert.Equal(t, "1", rdb.HGet(ctx, *b.Id, BOOLEAN_VALUE).Val())
+
+		if err = ReleaseLease(rdb, ctx, *b.Id, l.Token); err != nil {
+			t.Fatalf("ReleaseLease() error = %v", err)
+		}
+
+		assert.Zero(t, rdb.ZCard(ctx, LEASES_DUE_KEY).Val())
+	})
+
+	t.Run("deletes booleans once the lease expired", func(t *testing.T) {
+		d := Boolean{Label: "stale lock"}
+
+		if err := d.Save(rdb, ctx); err != nil {
+			t.Fatalf("Boolean.Save() error = %v", err)
+		}
+
+		if _, err := AcquireLease(rdb, ctx, *d.Id, &BooleanParams{ExpiresIn: 10}); err != nil {
+			t.Fatalf("AcquireLease() error = %v", err)
+		}
+
+		clock = clock.Add(11 * time.Second)
+
+		if err := DeleteBoolean(rdb, ctx, *d.Id); err != nil {
+			t.Fatalf("DeleteBoolean() error = %v", err)
+		}
+
+		assert.Zero(t, rdb.Exists(ctx, *d.Id).Val())
+		assert.Equal(t, redis.Nil, rdb.ZScore(ctx, LEASES_DUE_KEY, *d.Id).Err())
+	})
+
+	t.Run("ends leases with the boolean", func(t *testing.T) {
+		e := Boolean{Label: "expiring lock", BooleanParams: &BooleanParams{ExpiresIn: 20}}
+
+		if err := e.Save(rdb, ctx); err != nil {
+			t.Fatalf("Boolean.Save() error = %v", err)
+		}
+
+		expiresAt := rdb.ExpireTime(ctx, *e.Id).Val()
+
+		l, err := AcquireLease(rdb, ctx, *e.Id, &BooleanParams{ExpiresIn: 60})
+		if err != nil {
+			t.Fatalf("AcquireLease() error = %v", err)
+		}
+
+		assert.Equal(t, int64(expiresAt/time.Second), l.ExpiresAt)
+		assert.Equal(t, float64(l.ExpiresAt), rdb.ZScore(ctx, LEASES_DUE_KEY, *e.Id).Val())
+
+		if l, err = RenewLease(rdb, ctx, *e.Id, l.Token, &BooleanParams{ExpiresIn: 60}); err != nil {
+			t.Fatalf("RenewLease() error = %v", err)
+		}
+
+		assert.Equal(t, int64(expiresAt/time.Second), l.ExpiresAt)
+	})
+
+	t.Run("limits the TTL of leases", func(t *testing.T) {
+		params := &BooleanParams{}
+
+		if err := params.LimitLeaseTTL(30 * time.Second); err != nil {
+			t.Fatalf("LimitLeaseTTL() error = %v", err)
+		}
+
+		assert.Equal(t, Expiry(clock.Unix()+30), params.ExpiresAt)
+
+		params = &BooleanParams{ExpiresIn: 3600}
+
+		if err := params.LimitLeaseTTL(time.Minute); err == nil {
+			t.Errorf("LimitLeaseTTL() error = %v, wantErr %v", err, true)
+		}
+	})
+
+	t.Run("rejects inexistent booleans", func(t *testing.T) {
+		if _, err := AcquireLease(rdb, ctx, "inexistent", &BooleanParams{}); err == nil {
+			t.Errorf("AcquireLease() error = %v, wantErr %v", err, true)
+		}
+	})
+}
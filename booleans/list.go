@@ -0,0 +1,115 @@
+package booleans
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/errors"
+)
+
+const LIST_DEFAULT_COUNT = 100
+
+type booleansResponse struct {
+	Data   []*booleanWithId `json:"data"`
+	Cursor string           `json:"cursor"`
+}
+
+type ListParams struct {
+	Cursor uint64 `schema:"cursor"`
+	Count  int64  `schema:"count" validate:"omitempty,min=1,max=1000"`
+}
+
+func (l *ListParams) Validate() (err error) {
+	if err = CustomValidateStruct(l); err != nil {
+		slog.Debug("invalid list parameters", "error", err)
+
+		return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+	}
+
+	return
+}
+
+// isBooleanKey reports whether key holds a boolean, as auxiliary keys, e.g.
+// for schedules, contain a colon.
+func isBooleanKey(key string) bool {
+	return !strings.Contains(key, ":")
+}
+
+// ListBooleans returns a page of booleans starting at the given cursor, as
+// well as the cursor of the next page, which is 0 once all booleans were
+// returned. Pages may contain fewer booleans than requested. On a cluster,
+// the cursor scans the masters one after another.
+func ListBooleans(client redis.UniversalClient, ctx context.Context, params *ListParams) (bools []*Boolean, cursor uint64, err error) {
+	ctx, span := startSpan(ctx, "ListBooleans")
+	defer func() { endSpan(span, err) }()
+
+	count := params.Count
+	if count == 0 {
+		count = LIST_DEFAULT_COUNT
+	}
+
+	var keys []string
+	if keys, cursor, err = scanKeys(client, ctx, params.Cursor, count, "hash"); err != nil {
+		slog.ErrorContext(ctx, "failed to scan booleans", "error", err, "cursor", params.Cursor)
+
+		return nil, 0, errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+	}
+
+	bools = make([]*Boolean, 0, len(keys))
+
+	for _, key := range keys {
+		if !isBooleanKey(key) {
+			continue
+		}
+
+		var b *Boolean
+		if b, err = GetBoolean(client, ctx, key); err != nil {
+			// the boolean expired in the meantime
+			if httpErr, ok := err.(*errors.HTTPError); ok && httpErr.Status == http.StatusNotFound {
+				continue
+			}
+
+			return nil, 0, err
+		}
+
+		bools = append(bools, b)
+	}
+
+	return bools, cursor, nil
+}
+
+func ParseListParams(r *http.Request) (params *ListParams, err error) {
+	params = new(ListParams)
+
+	if err = decoder.Decode(params, r.URL.Query()); err != nil {
+		slog.DebugContext(r.Context(), "failed to decode query", "error", err)
+
+		return nil, errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+	}
+
+	if err = params.Validate(); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func CreateBooleansResponse(bools []*Boolean, cursor uint64) (body *booleansResponse) {
+	body = &booleansResponse{
+		Data:   make([]*booleanWithId, 0, len(bools)),
+		Cursor: strconv.FormatUint(cursor, 10),
+	}
+
+	for _, b := range bools {
+		body.Data = append(body.Data, &booleanWithId{
+			Id:      *b.Id,
+			Boolean: b,
+		})
+	}
+
+	return
+}
@@ -0,0 +1,427 @@
+package booleans
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/idempotency"
+	"github.com/saschazar21/go-baas/ratelimit"
+)
+
+const (
+	SCHEDULE_KEY_PREFIX  = "schedule:"
+	SCHEDULES_KEY_PREFIX = "schedules:"
+
+	MAINTENANCE_SCAN_COUNT = 1000
+)
+
+// Record is the raw content of a Redis key, as used for maintenance.
+type Record struct {
+	Key    string            `json:"key"`
+	Type   string            `json:"type"`
+	TTL    int64             `json:"ttl"`
+	Fields map[string]string `json:"fields,omitempty"`
+	Values []string          `json:"values,omitempty"`
+}
+
+// Issue describes an integrity problem of a Redis key.
+type Issue struct {
+	Key     string `json:"key"`
+	Member  string `json:"member,omitempty"`
+	Problem string `json:"problem"`
+}
+
+// Orphan is an auxiliary key, or a member of it, which refers to a boolean
+// or schedule that no longer exists, e.g. as the boolean expired.
+type Orphan struct {
+	Key    string `json:"key"`
+	Member string `json:"member,omitempty"`
+}
+
+// ScanKeys calls fn for every key in the database, on a cluster, for the keys
+// of every master.
+func ScanKeys(client redis.UniversalClient, ctx context.Context, fn func(key string) error) (err error) {
+	var cursor uint64
+
+	for {
+		var keys []string
+		if keys, cursor, err = scanKeys(client, ctx, cursor, MAINTENANCE_SCAN_COUNT, ""); err != nil {
+			return
+		}
+
+		for _, key := range keys {
+			if err = fn(key); err != nil {
+				return
+			}
+		}
+
+		if cursor == 0 {
+			return
+		}
+	}
+}
+
+// ScanBooleans calls fn for every boolean, whose label matches the glob
+// pattern, supporting * and ? as wildcards.
+func ScanBooleans(client redis.UniversalClient, ctx context.Context, pattern string, fn func(b *Boolean) error) (err error) {
+	var re *regexp.Regexp
+	if re, err = globToRegexp(pattern); err != nil {
+		return
+	}
+
+	return ScanKeys(client, ctx, func(key string) (err error) {
+		if !isBooleanKey(key) || client.Type(ctx, key).Val() != "hash" {
+			return
+		}
+
+		b := new(Boolean)
+		if err = client.HGetAll(ctx, key).Scan(b); err != nil {
+			return fmt.Errorf("failed to read boolean with ID %s: %w", key, err)
+		}
+
+		b.BooleanParams = &BooleanParams{Id: &key}
+
+		if !re.MatchString(b.Label) {
+			return
+		}
+
+		return fn(b)
+	})
+}
+
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("^")
+
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
+
+// DumpKey returns the raw content of the key.
+func DumpKey(client redis.UniversalClient, ctx context.Context, key string) (r *Record, err error) {
+	r = &Record{Key: key}
+
+	if r.Type, err = client.Type(ctx, key).Result(); err != nil {
+		return
+	}
+
+	var ttl time.Duration
+	if ttl, err = client.TTL(ctx, key).Result(); err != nil {
+		return
+	}
+
+	r.TTL = ttlSeconds(ttl)
+
+	switch r.Type {
+	case "hash":
+		r.Fields, err = client.HGetAll(ctx, key).Result()
+	case "zset":
+		var members []redis.Z
+		if members, err = client.ZRangeWithScores(ctx, key, 0, -1).Result(); err != nil {
+			return
+		}
+
+		for _, m := range members {
+			r.Values = append(r.Values, fmt.Sprintf("%v %v", m.Member, m.Score))
+		}
+	case "string":
+		var value string
+		if value, err = client.Get(ctx, key).Result(); err == nil {
+			r.Values = []string{value}
+		}
+	}
+
+	return
+}
+
+// ttlSeconds converts the TTL reported by Redis to seconds, -1 denotes keys
+// without expiry.
+func ttlSeconds(ttl time.Duration) int64 {
+	if ttl < 0 {
+		return -1
+	}
+
+	return int64(ttl / time.Second)
+}
+
+// TTL returns the remaining time to live of the boolean in seconds, or -1,
+// if it does not expire.
+func TTL(client redis.UniversalClient, ctx context.Context, id string) (int64, error) {
+	ttl, err := client.TTL(ctx, id).Result()
+
+	return ttlSeconds(ttl), err
+}
+
+// ResetExpiry sets the time to live of the boolean, a ttl of 0 removes its
+// expiry. A max above 0 limits the ttl, so that the expiry cannot be removed
+// either.
+func ResetExpiry(client redis.UniversalClient, ctx context.Context, id string, ttl, max time.Duration) (err error) {
+	if max > 0 && (ttl <= 0 || ttl > max) {
+		return fmt.Errorf("TTL %s exceeds the maximum TTL of %s", ttl, max)
+	}
+
+	if ttl <= 0 {
+		err = client.Persist(ctx, id).Err()
+	} else {
+		err = client.Expire(ctx, id, ttl).Err()
+	}
+
+	if err == nil {
+		syncShadow(client, ctx, id)
+	}
+
+	return
+}
+
+// VerifyKey checks the key for integrity problems according to the key
+// layout: booleans are hashes at their bare ID, auxiliary keys contain a
+// colon.
+func VerifyKey(client redis.UniversalClient, ctx context.Context, key string) (issues []Issue, err error) {
+	var t string
+	if t, err = client.Type(ctx, key).Result(); err != nil {
+		return
+	}
+
+	issue := func(problem string, args ...interface{}) {
+		issues = append(issues, Issue{Key: key, Problem: fmt.Sprintf(problem, args...)})
+	}
+
+	switch {
+	case isBooleanKey(key):
+		if t != "hash" {
+			issue("boolean has type %s instead of hash", t)
+			return
+		}
+
+		return verifyBoolean(client, ctx, key)
+	case key == LEASES_DUE_KEY:
+		if t != "zset" {
+			issue("due leases have type %s instead of zset", t)
+		}
+	case key == SCHEDULES_DUE_KEY:
+		if t != "zset" {
+			issue("due schedules have type %s instead of zset", t)
+			return
+		}
+
+		return verifyScheduleReferences(client, ctx, key)
+	case strings.HasPrefix(key, SCHEDULE_KEY_PREFIX):
+		if t != "hash" {
+			issue("schedule has type %s instead of hash", t)
+			return
+		}
+
+		s := &Schedule{Id: strings.TrimPrefix(key, SCHEDULE_KEY_PREFIX)}
+		if err = client.HGetAll(ctx, key).Scan(s); err != nil {
+			issue("schedule is unreadable: %v", err)
+			return issues, nil
+		}
+
+		if s.Action != SCHEDULE_ACTION_SET && s.Action != SCHEDULE_ACTION_TOGGLE {
+			issue("schedule has unknown action %q", s.Action)
+		}
+
+		if client.Exists(ctx, s.BooleanId).Val() == 0 {
+			issue("schedule refers to inexistent boolean %q", s.BooleanId)
+		}
+	case strings.HasPrefix(key, SCHEDULES_KEY_PREFIX):
+		if t != "zset" {
+			issue("schedules of boolean have type %s instead of zset", t)
+			return
+		}
+
+		booleanId, legacy := schedulesBooleanId(key)
+
+		if legacy {
+			issue("schedules of boolean use the key layout without hash tag, run baasctl migrate-keys")
+		}
+
+		if client.Exists(ctx, booleanId).Val() == 0 {
+			issue("schedules refer to inexistent boolean %q", booleanId)
+		}
+
+		var references []Issue
+		if references, err = verifyScheduleReferences(client, ctx, key); err != nil {
+			return
+		}
+
+		issues = append(issues, references...)
+	case strings.HasPrefix(key, SHADOW_KEY_PREFIX):
+		if t != "hash" {
+			issue("shadow copy has type %s instead of hash", t)
+		}
+	case key == STATS_EXPIRED_KEY:
+		if t != "string" {
+			issue("expired booleans counter has type %s instead of string", t)
+		}
+	case strings.HasPrefix(key, ratelimit.KEY_PREFIX):
+		if t != "hash" {
+			issue("rate limit has type %s instead of hash", t)
+		}
+	case strings.HasPrefix(key, idempotency.KEY_PREFIX):
+		if t != "hash" {
+			issue("idempotent response has type %s instead of hash", t)
+		}
+	default:
+		issue("unknown key")
+	}
+
+	return
+}
+
+func verifyBoolean(client redis.UniversalClient, ctx context.Context, id string) (issues []Issue, err error) {
+	cmd := client.HGetAll(ctx, id)
+	if err = cmd.Err(); err != nil {
+		return
+	}
+
+	fields := cmd.Val()
+
+	issue := func(problem string, args ...interface{}) {
+		issues = append(issues, Issue{Key: id, Problem: fmt.Sprintf(problem, args...)})
+	}
+
+	if value, ok := fields[BOOLEAN_VALUE]; !ok {
+		issue("boolean is missing field %q", BOOLEAN_VALUE)
+	} else if value != "0" && value != "1" {
+		issue("boolean has invalid value %q", value)
+	}
+
+	if _, ok := fields[BOOLEAN_LEASE_OWNER]; ok != (fields[BOOLEAN_LEASE_EXPIRES_AT] != "") {
+		issue("boolean has incomplete lease")
+	}
+
+	b := new(Boolean)
+	if err = cmd.Scan(b); err != nil {
+		issue("boolean is unreadable: %v", err)
+		return issues, nil
+	}
+
+	if err = CustomValidateStruct(b); err != nil {
+		issue("boolean is invalid: %v", err)
+	}
+
+	return issues, nil
+}
+
+func verifyScheduleReferences(client redis.UniversalClient, ctx context.Context, key string) (issues []Issue, err error) {
+	var ids []string
+	if ids, err = client.ZRange(ctx, key, 0, -1).Result(); err != nil {
+		return
+	}
+
+	for _, id := range ids {
+		if client.Exists(ctx, scheduleKey(id)).Val() == 0 {
+			issues = append(issues, Issue{Key: key, Member: id, Problem: "refers to inexistent schedule"})
+		}
+	}
+
+	return
+}
+
+// FindOrphans returns schedules and schedule references, which refer to a
+// boolean or schedule that no longer exists.
+func FindOrphans(client redis.UniversalClient, ctx context.Context) (orphans []Orphan, err error) {
+	err = ScanKeys(client, ctx, func(key string) (err error) {
+		switch {
+		case key == SCHEDULES_DUE_KEY:
+			var ids []string
+			if ids, err = client.ZRange(ctx, key, 0, -1).Result(); err != nil {
+				return
+			}
+
+			for _, id := range ids {
+				if client.Exists(ctx, scheduleKey(id)).Val() == 0 {
+					orphans = append(orphans, Orphan{Key: key, Member: id})
+				}
+			}
+		case strings.HasPrefix(key, SCHEDULE_KEY_PREFIX):
+			booleanId := client.HGet(ctx, key, "boolean_id").Val()
+
+			if client.Exists(ctx, booleanId).Val() == 0 {
+				orphans = append(orphans, Orphan{Key: key})
+			}
+		case strings.HasPrefix(key, SCHEDULES_KEY_PREFIX):
+			if booleanId, _ := schedulesBooleanId(key); client.Exists(ctx, booleanId).Val() == 0 {
+				orphans = append(orphans, Orphan{Key: key})
+			}
+		}
+
+		return
+	})
+
+	return
+}
+
+// PurgeOrphan deletes the orphaned key, or removes the orphaned member from
+// it.
+func PurgeOrphan(client redis.UniversalClient, ctx context.Context, o Orphan) error {
+	if o.Member != "" {
+		return client.ZRem(ctx, o.Key, o.Member).Err()
+	}
+
+	if strings.HasPrefix(o.Key, SCHEDULE_KEY_PREFIX) {
+		if err := client.ZRem(ctx, SCHEDULES_DUE_KEY, strings.TrimPrefix(o.Key, SCHEDULE_KEY_PREFIX)).Err(); err != nil {
+			return err
+		}
+	}
+
+	return client.Del(ctx, o.Key).Err()
+}
+
+// IsLegacyKey reports whether the key uses a key layout of earlier versions,
+// which has to be migrated using MigrateKey for cluster deployments.
+func IsLegacyKey(key string) bool {
+	if key == SCHEDULES_DUE_KEY || !strings.HasPrefix(key, SCHEDULES_KEY_PREFIX) {
+		return false
+	}
+
+	_, legacy := schedulesBooleanId(key)
+
+	return legacy
+}
+
+// MigrateKey moves the content of a legacy key to its current key, merging it
+// with existing content. The keys may reside in different cluster slots, so
+// the content is copied instead of renaming the key.
+func MigrateKey(client redis.UniversalClient, ctx context.Context, key string) (err error) {
+	if !IsLegacyKey(key) {
+		return
+	}
+
+	booleanId, _ := schedulesBooleanId(key)
+
+	var members []redis.Z
+	if members, err = client.ZRangeWithScores(ctx, key, 0, -1).Result(); err != nil {
+		return
+	}
+
+	if len(members) > 0 {
+		if err = client.ZAdd(ctx, booleanSchedulesKey(booleanId), members...).Err(); err != nil {
+			return
+		}
+	}
+
+	return client.Del(ctx, key).Err()
+}
@@ -0,0 +1,169 @@
+package booleans
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/ratelimit"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		label   string
+		want    bool
+	}{
+		{pattern: "*", label: "anything/at all", want: true},
+		{pattern: "", label: "", want: true},
+		{pattern: "deploy *", label: "deploy freeze", want: true},
+		{pattern: "deploy *", label: "release freeze", want: false},
+		{pattern: "v?", label: "v1", want: true},
+		{pattern: "v?", label: "v10", want: false},
+		{pattern: "a.b", label: "axb", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			re, err := globToRegexp(tt.pattern)
+			if err != nil {
+				t.Fatalf("globToRegexp() error = %v", err)
+			}
+
+			assert.Equal(t, tt.want, re.MatchString(tt.label))
+		})
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	opts, err := redis.ParseURL(os.Getenv(db.REDIS_URL_ENV))
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	rdb := redis.NewClient(opts)
+
+	valid := Boolean{Label: "deploy freeze", Value: true}
+	if err = valid.Save(rdb, ctx); err != nil {
+		t.Fatalf("Boolean.Save() error = %v", err)
+	}
+
+	expiring := Boolean{Label: "maintenance window"}
+	if err = expiring.Save(rdb, ctx); err != nil {
+		t.Fatalf("Boolean.Save() error = %v", err)
+	}
+
+	schedule := Schedule{BooleanId: *expiring.Id, At: time.Now().Unix() + 3600, Toggle: true}
+	if err = schedule.Save(rdb, ctx); err != nil {
+		t.Fatalf("Schedule.Save() error = %v", err)
+	}
+
+	// simulate the boolean expiring, which leaves its schedules behind
+	if err = rdb.Del(ctx, *expiring.Id).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	rdb.HSet(ctx, "missing-value", BOOLEAN_LABEL, "broken")
+	rdb.Set(ctx, "wrong-type", "1", 0)
+	rdb.HSet(ctx, ratelimit.BooleanKey(*valid.Id), "tokens", "1")
+
+	t.Run("scans booleans by label", func(t *testing.T) {
+		got := []string{}
+
+		if err := ScanBooleans(rdb, ctx, "deploy *", func(b *Boolean) error {
+			got = append(got, *b.Id)
+			return nil
+		}); err != nil {
+			t.Fatalf("ScanBooleans() error = %v", err)
+		}
+
+		assert.Equal(t, []string{*valid.Id}, got)
+	})
+
+	t.Run("verifies keys", func(t *testing.T) {
+		tests := []struct {
+			key    string
+			issues int
+		}{
+			{key: *valid.Id, issues: 0},
+			{key: "missing-value", issues: 1},
+			{key: "wrong-type", issues: 1},
+			{key: scheduleKey(schedule.Id), issues: 1},
+			{key: booleanSchedulesKey(*expiring.Id), issues: 1},
+			{key: SCHEDULES_DUE_KEY, issues: 0},
+			{key: ratelimit.BooleanKey(*valid.Id), issues: 0},
+		}
+
+		for _, tt := range tests {
+			issues, err := VerifyKey(rdb, ctx, tt.key)
+			if err != nil {
+				t.Fatalf("VerifyKey() error = %v", err)
+			}
+
+			assert.Len(t, issues, tt.issues, tt.key)
+		}
+	})
+
+	t.Run("resets expiry", func(t *testing.T) {
+		if err := ResetExpiry(rdb, ctx, *valid.Id, time.Hour, 0); err != nil {
+			t.Fatalf("ResetExpiry() error = %v", err)
+		}
+
+		ttl, err := TTL(rdb, ctx, *valid.Id)
+		if err != nil {
+			t.Fatalf("TTL() error = %v", err)
+		}
+
+		assert.InDelta(t, 3600, ttl, 1)
+
+		if err := ResetExpiry(rdb, ctx, *valid.Id, 0, 0); err != nil {
+			t.Fatalf("ResetExpiry() error = %v", err)
+		}
+
+		ttl, _ = TTL(rdb, ctx, *valid.Id)
+		assert.Equal(t, int64(-1), ttl)
+	})
+
+	t.Run("purges orphans", func(t *testing.T) {
+		orphans, err := FindOrphans(rdb, ctx)
+		if err != nil {
+			t.Fatalf("FindOrphans() error = %v", err)
+		}
+
+		assert.ElementsMatch(t, []Orphan{
+			{Key: scheduleKey(schedule.Id)},
+			{Key: booleanSchedulesKey(*expiring.Id)},
+		}, orphans)
+
+		for _, o := range orphans {
+			if err := PurgeOrphan(rdb, ctx, o); err != nil {
+				t.Fatalf("PurgeOrphan() error = %v", err)
+			}
+		}
+
+		if orphans, err = FindOrphans(rdb, ctx); err != nil {
+			t.Fatalf("FindOrphans() error = %v", err)
+		}
+
+		assert.Empty(t, orphans)
+		assert.Zero(t, rdb.ZCard(ctx, SCHEDULES_DUE_KEY).Val())
+	})
+}
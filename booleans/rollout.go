@@ -0,0 +1,149 @@
+package booleans
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/errors"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	BOOLEAN_ROLLOUT = "rollout"
+
+	// ROLLOUT_BUCKETS is the amount of buckets subjects are hashed into,
+	// allowing percentages with a precision of two decimal places.
+	ROLLOUT_BUCKETS = 10000
+)
+
+const (
+	EVALUATION_REASON_DISABLED = "disabled"
+	EVALUATION_REASON_DEFAULT  = "default"
+	EVALUATION_REASON_DENIED   = "denied"
+	EVALUATION_REASON_ALLOWED  = "allowed"
+	EVALUATION_REASON_ROLLOUT  = "rollout"
+)
+
+// Rollout restricts a boolean, which is true, to a subset of subjects. Deny
+// takes precedence over Allow, all other subjects are enabled according to
+// Percentage.
+type Rollout struct {
+	Percentage float64  `json:"percentage" schema:"percentage" validate:"gte=0,lte=100"`
+	Allow      []string `json:"allow,omitempty" schema:"allow" validate:"omitempty,max=1000,dive,required,max=256"`
+	Deny       []string `json:"deny,omitempty" schema:"deny" validate:"omitempty,max=1000,dive,required,max=256"`
+}
+
+// MarshalBinary stores the rollout as JSON in the boolean hash.
+func (r *Rollout) MarshalBinary() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+// ScanRedis restores the rollout from the boolean hash.
+func (r *Rollout) ScanRedis(value string) error {
+	return json.Unmarshal([]byte(value), r)
+}
+
+// bucket deterministically assigns the subject to one of ROLLOUT_BUCKETS
+// buckets. The boolean ID is part of the hash, so that the same subject is not
+// always part of the same percentage across all booleans.
+func bucket(id, subject string) uint64 {
+	sum := sha256.Sum256([]byte(id + ":" + subject))
+
+	return binary.BigEndian.Uint64(sum[:8]) % ROLLOUT_BUCKETS
+}
+
+func (r *Rollout) evaluate(id, subject string) (value bool, reason string) {
+	if slices.Contains(r.Deny, subject) {
+		return false, EVALUATION_REASON_DENIED
+	}
+
+	if slices.Contains(r.Allow, subject) {
+		return true, EVALUATION_REASON_ALLOWED
+	}
+
+	return float64(bucket(id, subject)) < r.Percentage*ROLLOUT_BUCKETS/100, EVALUATION_REASON_ROLLOUT
+}
+
+type evaluationResponse struct {
+	Data *Evaluation `json:"data"`
+}
+
+type EvaluationParams struct {
+	Subject string `schema:"subject" validate:"required,max=256"`
+}
+
+func (e *EvaluationParams) Validate() (err error) {
+	if err = CustomValidateStruct(e); err != nil {
+		slog.Debug("invalid evaluation parameters", "error", err)
+
+		return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+	}
+
+	return
+}
+
+type Evaluation struct {
+	Id      string `json:"id"`
+	Subject string `json:"subject"`
+	Value   bool   `json:"value"`
+	Reason  string `json:"reason"`
+}
+
+// EvaluateBoolean resolves the value of the boolean for the given subject.
+// A false value acts as kill switch and disables the boolean for every
+// subject, otherwise the rollout configuration is applied, if present.
+func EvaluateBoolean(client redis.UniversalClient, ctx context.Context, id, subject string) (e *Evaluation, err error) {
+	ctx, span := startSpan(ctx, "EvaluateBoolean", attribute.String(ATTRIBUTE_BOOLEAN_ID, id))
+	defer func() { endSpan(span, err) }()
+
+	var b *Boolean
+	if b, err = GetBoolean(client, ctx, id); err != nil {
+		return
+	}
+
+	e = &Evaluation{
+		Id:      id,
+		Subject: subject,
+	}
+
+	switch {
+	case !b.Value:
+		e.Reason = EVALUATION_REASON_DISABLED
+	case b.Rollout == nil:
+		e.Value, e.Reason = true, EVALUATION_REASON_DEFAULT
+	default:
+		e.Value, e.Reason = b.Rollout.evaluate(id, subject)
+	}
+
+	return
+}
+
+func ParseEvaluationParams(r *http.Request) (params *EvaluationParams, err error) {
+	params = new(EvaluationParams)
+
+	if err = decoder.Decode(params, r.URL.Query()); err != nil {
+		slog.DebugContext(r.Context(), "failed to decode query", "error", err)
+
+		return nil, errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+	}
+
+	if err = params.Validate(); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func CreateEvaluationResponse(e *Evaluation) (body *evaluationResponse) {
+	body = &evaluationResponse{
+		Data: e,
+	}
+
+	return
+}
@@ -0,0 +1,198 @@
+package booleans
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolloutEvaluate(t *testing.T) {
+	t.Run("is sticky per subject", func(t *testing.T) {
+		r := &Rollout{Percentage: 50}
+
+		for i := 0; i < 100; i++ {
+			subject := fmt.Sprintf("user-%d", i)
+
+			first, _ := r.evaluate("flag", subject)
+			second, _ := r.evaluate("flag", subject)
+
+			assert.Equal(t, first, second)
+		}
+	})
+
+	t.Run("is approximately distributed by percentage", func(t *testing.T) {
+		r := &Rollout{Percentage: 25}
+
+		enabled := 0
+
+		for i := 0; i < 10000; i++ {
+			if value, _ := r.evaluate("flag", fmt.Sprintf("user-%d", i)); value {
+				enabled++
+			}
+		}
+
+		assert.InDelta(t, 2500, enabled, 200)
+	})
+
+	t.Run("keeps enabled subjects enabled when increasing the percentage", func(t *testing.T) {
+		for i := 0; i < 1000; i++ {
+			subject := fmt.Sprintf("user-%d", i)
+
+			if value, _ := (&Rollout{Percentage: 10}).evaluate("flag", subject); value {
+				increased, _ := (&Rollout{Percentage: 20}).evaluate("flag", subject)
+
+				assert.True(t, increased, subject)
+			}
+		}
+	})
+
+	tests := []struct {
+		name    string
+		rollout Rollout
+		subject string
+		want    bool
+		reason  string
+	}{
+		{
+			name:    "no percentage",
+			rollout: Rollout{},
+			subject: "user-42",
+			want:    false,
+			reason:  EVALUATION_REASON_ROLLOUT,
+		},
+		{
+			name:    "full percentage",
+			rollout: Rollout{Percentage: 100},
+			subject: "user-42",
+			want:    true,
+			reason:  EVALUATION_REASON_ROLLOUT,
+		},
+		{
+			name:    "allow-listed subject",
+			rollout: Rollout{Allow: []string{"user-42"}},
+			subject: "user-42",
+			want:    true,
+			reason:  EVALUATION_REASON_ALLOWED,
+		},
+		{
+			name:    "deny-listed subject",
+			rollout: Rollout{Percentage: 100, Allow: []string{"user-42"}, Deny: []string{"user-42"}},
+			subject: "user-42",
+			want:    false,
+			reason:  EVALUATION_REASON_DENIED,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := tt.rollout.evaluate("flag", tt.subject)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestEvaluateBoolean(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	opts, err := redis.ParseURL(os.Getenv(db.REDIS_URL_ENV))
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	rdb := redis.NewClient(opts)
+
+	b := Boolean{
+		Label: "new checkout",
+		Value: true,
+		Rollout: &Rollout{
+			Percentage: 0,
+			Allow:      []string{"user-42"},
+			Deny:       []string{"user-13"},
+		},
+	}
+
+	if err = b.Save(rdb, ctx); err != nil {
+		t.Fatalf("Boolean.Save() error = %v", err)
+	}
+
+	t.Run("stores the rollout", func(t *testing.T) {
+		got, err := GetBoolean(rdb, ctx, *b.Id)
+		if err != nil {
+			t.Fatalf("GetBoolean() error = %v", err)
+		}
+
+		assert.Equal(t, b.Rollout, got.Rollout)
+	})
+
+	t.Run("evaluates the rollout", func(t *testing.T) {
+		got, err := EvaluateBoolean(rdb, ctx, *b.Id, "user-42")
+		if err != nil {
+			t.Fatalf("EvaluateBoolean() error = %v", err)
+		}
+
+		assert.True(t, got.Value)
+		assert.Equal(t, EVALUATION_REASON_ALLOWED, got.Reason)
+
+		if got, err = EvaluateBoolean(rdb, ctx, *b.Id, "user-1"); err != nil {
+			t.Fatalf("EvaluateBoolean() error = %v", err)
+		}
+
+		assert.False(t, got.Value)
+		assert.Equal(t, EVALUATION_REASON_ROLLOUT, got.Reason)
+	})
+
+	t.Run("disables every subject by the kill switch", func(t *testing.T) {
+		if _, err := ToggleBoolean(rdb, ctx, *b.Id); err != nil {
+			t.Fatalf("ToggleBoolean() error = %v", err)
+		}
+
+		got, err := EvaluateBoolean(rdb, ctx, *b.Id, "user-42")
+		if err != nil {
+			t.Fatalf("EvaluateBoolean() error = %v", err)
+		}
+
+		assert.False(t, got.Value)
+		assert.Equal(t, EVALUATION_REASON_DISABLED, got.Reason)
+	})
+
+	t.Run("removes the rollout on update", func(t *testing.T) {
+		update := Boolean{Label: "new checkout", Value: true, BooleanParams: &BooleanParams{Id: b.Id}}
+
+		if err := update.Save(rdb, ctx); err != nil {
+			t.Fatalf("Boolean.Save() error = %v", err)
+		}
+
+		got, err := EvaluateBoolean(rdb, ctx, *b.Id, "user-13")
+		if err != nil {
+			t.Fatalf("EvaluateBoolean() error = %v", err)
+		}
+
+		assert.True(t, got.Value)
+		assert.Equal(t, EVALUATION_REASON_DEFAULT, got.Reason)
+	})
+
+	t.Run("rejects inexistent booleans", func(t *testing.T) {
+		if _, err := EvaluateBoolean(rdb, ctx, "inexistent", "user-42"); err == nil {
+			t.Errorf("EvaluateBoolean() error = %v, wantErr %v", err, true)
+		}
+	})
+}
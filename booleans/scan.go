@@ -0,0 +1,80 @@
+package booleans
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// SCAN_NODE_SHIFT is the bit offset of the node index in the cursors of
+// cluster scans, the lower bits hold the cursor of the node, which is bounded
+// by the size of its hash table.
+const SCAN_NODE_SHIFT = 48
+
+// scanKeys returns a page of keys of the given type, or any type, if empty,
+// starting at the cursor. The masters of a cluster are scanned one after
+// another, ordered by address.
+func scanKeys(client redis.UniversalClient, ctx context.Context, cursor uint64, count int64, keyType string) (keys []string, next uint64, err error) {
+	cluster, ok := client.(*redis.ClusterClient)
+	if !ok {
+		return scanNode(client, ctx, cursor, count, keyType)
+	}
+
+	var masters []*redis.Client
+	if masters, err = clusterMasters(cluster, ctx); err != nil {
+		return
+	}
+
+	index := int(cursor >> SCAN_NODE_SHIFT)
+
+	// the cluster shrank in the meantime
+	if index >= len(masters) {
+		return nil, 0, nil
+	}
+
+	if keys, next, err = scanNode(masters[index], ctx, cursor&(1<<SCAN_NODE_SHIFT-1), count, keyType); err != nil {
+		return
+	}
+
+	switch {
+	case next != 0:
+		next |= uint64(index) << SCAN_NODE_SHIFT
+	case index+1 < len(masters):
+		next = uint64(index+1) << SCAN_NODE_SHIFT
+	}
+
+	return
+}
+
+func scanNode(client redis.Cmdable, ctx context.Context, cursor uint64, count int64, keyType string) ([]string, uint64, error) {
+	if keyType == "" {
+		return client.Scan(ctx, cursor, "*", count).Result()
+	}
+
+	return client.ScanType(ctx, cursor, "*", count, keyType).Result()
+}
+
+// clusterMasters returns the master nodes of the cluster ordered by address.
+func clusterMasters(cluster *redis.ClusterClient, ctx context.Context) (masters []*redis.Client, err error) {
+	var mu sync.Mutex
+
+	if err = cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		masters = append(masters, node)
+
+		return nil
+	}); err != nil {
+		return
+	}
+
+	slices.SortFunc(masters, func(a, b *redis.Client) int {
+		return strings.Compare(a.Options().Addr, b.Options().Addr)
+	})
+
+	return
+}
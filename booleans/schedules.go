@@ -0,0 +1,698 @@
+package booleans
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/cron"
+	"github.com/saschazar21/go-baas/errors"
+	"github.com/saschazar21/go-baas/metrics"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	SCHEDULE_ACTION_SET    = "set"
+	SCHEDULE_ACTION_TOGGLE = "toggle"
+
+	SCHEDULES_DUE_KEY = "schedules:due"
+
+	SCHEDULES_BATCH_SIZE = 100
+
+	SCHEDULE_PREVIEW_DEFAULT = 5
+
+	// SCHEDULE_LEASE is the visibility timeout of a claimed schedule. It is
+	// due again afterwards, if the worker failed to apply or remove it.
+	SCHEDULE_LEASE = 5 * time.Minute
+
+	// SCHEDULE_RETRY_DELAY postpones a schedule, which failed to apply.
+	SCHEDULE_RETRY_DELAY = time.Minute
+
+	// SCHEDULE_MAX_ATTEMPTS limits the attempts to apply a run of a schedule,
+	// e.g. while the boolean is leased, the run is skipped afterwards.
+	SCHEDULE_MAX_ATTEMPTS = 10
+)
+
+const (
+	applyScheduleNotFound int64 = iota - 3
+	applyScheduleComputed
+	applyScheduleLeased
+	applyScheduleApplied
+	applyScheduleDone
+)
+
+// claimScheduleScript postpones a due schedule by the lease, so that no other
+// worker picks it up, while it is applied.
+var claimScheduleScript = redis.NewScript(`
+local at = redis.call("ZSCORE", KEYS[1], ARGV[1])
+
+if not at or tonumber(at) > tonumber(ARGV[2]) then
+	return 0
+end
+
+redis.call("ZADD", KEYS[1], ARGV[3], ARGV[1])
+return 1
+`)
+
+// applyScheduleScript applies the run of a schedule at ARGV[2] to the
+// boolean, if it still exists and is writable, so that an expiry in the
+// meantime does not recreate it. The index of schedules of the boolean marks
+// the run as done: it is applied only while the schedule is indexed at the
+// run, and moved to the next run at ARGV[6] or removed along with the change.
+// An expired lease is dropped along the way.
+var applyScheduleScript = redis.NewScript(`
+local at = redis.call("ZSCORE", KEYS[2], ARGV[1])
+
+if not at or tonumber(at) ~= tonumber(ARGV[2]) then
+	return 1
+end
+
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return -3
+end
+
+local expression = redis.call("HGET", KEYS[1], "expression")
+if expression and expression ~= "" then
+	return -2
+end
+
+local expires = tonumber(redis.call("HGET", KEYS[1], "lease_expires_at") or "0")
+
+if expires > tonumber(ARGV[5]) then
+	return -1
+end
+
+if expires > 0 then
+	redis.call("HDEL", KEYS[1], "lease_owner", "lease_expires_at")
+end
+
+local value = ARGV[4]
+
+if ARGV[3] == "toggle" then
+	value = redis.call("HGET", KEYS[1], "value") == "1" and "0" or "1"
+end
+
+redis.call("HSET", KEYS[1], "value", value, "updated_at", ARGV[5])
+
+if tonumber(ARGV[6]) > 0 then
+	redis.call("ZADD", KEYS[2], ARGV[6], ARGV[1])
+else
+	redis.call("ZREM", KEYS[2], ARGV[1])
+end
+
+return 0
+`)
+
+// countAttemptScript counts a failed attempt to apply the schedule, unless
+// it was deleted in the meantime.
+var countAttemptScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+
+return redis.call("HINCRBY", KEYS[1], "attempts", 1)
+`)
+
+type scheduleResponse struct {
+	Data *Schedule `json:"data"`
+}
+
+type schedulesResponse struct {
+	Data []*Schedule `json:"data"`
+}
+
+type ScheduleParams struct {
+	Next int `schema:"next" validate:"omitempty,min=1,max=100"`
+}
+
+func (p *ScheduleParams) Validate() (err error) {
+	if err = CustomValidateStruct(p); err != nil {
+		slog.Debug("invalid schedule parameters", "error", err)
+
+		return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+	}
+
+	return
+}
+
+type Schedule struct {
+	Id        string   `json:"id" redis:"-" schema:"-"`
+	BooleanId string   `json:"boolean_id" redis:"boolean_id" schema:"-"`
+	At        int64    `json:"at" redis:"at" schema:"at" validate:"required_without=Cron,excluded_with=Cron,omitempty,epoch-gt-now"`
+	Cron      string   `json:"cron,omitempty" redis:"cron,omitempty" schema:"cron" validate:"omitempty,max=256"`
+	Timezone  string   `json:"timezone,omitempty" redis:"timezone,omitempty" schema:"timezone" validate:"omitempty,timezone"`
+	Set       *bool    `json:"set,omitempty" redis:"-" schema:"set" validate:"required_without=Toggle,excluded_with=Toggle"`
+	Toggle    bool     `json:"toggle,omitempty" redis:"-" schema:"toggle"`
+	Next      []string `json:"next,omitempty" redis:"-" schema:"-"`
+
+	Action string `json:"-" redis:"action" schema:"-"`
+	Value  bool   `json:"-" redis:"value" schema:"-"`
+}
+
+func scheduleKey(id string) string {
+	return SCHEDULE_KEY_PREFIX + id
+}
+
+// booleanSchedulesKey returns the key of the index of the schedules of the
+// boolean, its hash tag places it in the cluster slot of the boolean.
+func booleanSchedulesKey(booleanId string) string {
+	return SCHEDULES_KEY_PREFIX + "{" + booleanId + "}"
+}
+
+// schedulesBooleanId returns the boolean ID of the index of schedules, legacy
+// reports whether the key lacks the hash tag, as written by earlier versions.
+func schedulesBooleanId(key string) (id string, legacy bool) {
+	id = strings.TrimPrefix(key, SCHEDULES_KEY_PREFIX)
+
+	if strings.HasPrefix(id, "{") && strings.HasSuffix(id, "}") {
+		return id[1 : len(id)-1], false
+	}
+
+	return id, true
+}
+
+func (s *Schedule) Validate() (err error) {
+	if err = CustomValidateStruct(s); err != nil {
+		slog.Debug("invalid schedule", "error", err)
+
+		return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+	}
+
+	if s.Cron != "" {
+		if _, err = cron.Parse(s.Cron, s.Timezone); err != nil {
+			slog.Debug("invalid cron expression", "error", err, "cron", s.Cron)
+
+			return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+		}
+	}
+
+	return
+}
+
+// Preview fills in the next n fire times of the schedule, formatted in its
+// time zone.
+func (s *Schedule) Preview(n int) {
+	if n <= 0 {
+		n = SCHEDULE_PREVIEW_DEFAULT
+	}
+
+	s.Next = nil
+
+	if s.Cron == "" {
+		s.Next = []string{time.Unix(s.At, 0).UTC().Format(time.RFC3339)}
+		return
+	}
+
+	schedule, err := cron.Parse(s.Cron, s.Timezone)
+	if err != nil {
+		slog.Warn("invalid cron expression", "error", err, "schedule_id", s.Id)
+		return
+	}
+
+	at := time.Unix(s.At, 0).In(schedule.Location())
+
+	for _, t := range append([]time.Time{at}, schedule.NextN(at, n-1)...) {
+		s.Next = append(s.Next, t.Format(time.RFC3339))
+	}
+}
+
+// nextAt returns the next run of a recurring schedule after t, or 0, if
+// there is none.
+func (s *Schedule) nextAt(t time.Time) (next int64, err error) {
+	if s.Cron == "" {
+		return
+	}
+
+	r := *s
+
+	var ok bool
+	if ok, err = r.nextRun(t); err != nil || !ok {
+		return
+	}
+
+	return r.At, nil
+}
+
+// nextRun moves a recurring schedule to its next fire time after t.
+func (s *Schedule) nextRun(t time.Time) (ok bool, err error) {
+	var schedule *cron.Schedule
+	if schedule, err = cron.Parse(s.Cron, s.Timezone); err != nil {
+		return
+	}
+
+	next := schedule.Next(t)
+
+	if next.IsZero() {
+		return false, nil
+	}
+
+	s.At = next.Unix()
+
+	return true, nil
+}
+
+// normalize translates between the request representation (set/toggle) and
+// the stored representation (action/value) of a schedule.
+func (s *Schedule) normalize() {
+	switch {
+	case s.Set != nil:
+		s.Action = SCHEDULE_ACTION_SET
+		s.Value = *s.Set
+	case s.Toggle:
+		s.Action = SCHEDULE_ACTION_TOGGLE
+		s.Value = false
+	case s.Action == SCHEDULE_ACTION_SET:
+		value := s.Value
+		s.Set = &value
+	case s.Action == SCHEDULE_ACTION_TOGGLE:
+		s.Toggle = true
+	}
+}
+
+func (s *Schedule) Save(client redis.UniversalClient, ctx context.Context) (err error) {
+	ctx, span := startSpan(ctx, "SaveSchedule", attribute.String(ATTRIBUTE_BOOLEAN_ID, s.BooleanId))
+	defer func() {
+		if s.Id != "" {
+			span.SetAttributes(attribute.String(ATTRIBUTE_SCHEDULE_ID, s.Id))
+		}
+
+		endSpan(span, err)
+	}()
+
+	if err = s.Validate(); err != nil {
+		return
+	}
+
+	if client.Exists(ctx, s.BooleanId).Val() == 0 {
+		slog.DebugContext(ctx, "boolean not found", "id", s.BooleanId)
+
+		return errors.NewHTTPError(http.StatusNotFound, &errors.NOT_FOUND_ERROR)
+	}
+
+	if err = ensureWritable(client, ctx, s.BooleanId); err != nil {
+		return
+	}
+
+	s.normalize()
+
+	if s.Cron != "" {
+		var ok bool
+		if ok, err = s.nextRun(now()); err != nil || !ok {
+			slog.DebugContext(ctx, "cron expression never fires", "cron", s.Cron)
+
+			return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+		}
+	}
+
+	id := generateRandomId()
+
+	for client.Exists(ctx, scheduleKey(id)).Val() > 0 {
+		id = generateRandomId()
+	}
+
+	s.Id = id
+
+	if _, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, scheduleKey(s.Id), s)
+		pipe.ZAdd(ctx, booleanSchedulesKey(s.BooleanId), redis.Z{Score: float64(s.At), Member: s.Id})
+		pipe.ZAdd(ctx, SCHEDULES_DUE_KEY, redis.Z{Score: float64(s.At), Member: s.Id})
+
+		return nil
+	}); err != nil {
+		slog.ErrorContext(ctx, "failed to save schedule", "error", err, "id", s.BooleanId, "schedule_id", s.Id)
+
+		return errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+	}
+
+	return
+}
+
+func getSchedule(client redis.UniversalClient, ctx context.Context, id string) (s *Schedule, err error) {
+	cmd := client.HGetAll(ctx, scheduleKey(id))
+
+	if err = cmd.Err(); err != nil {
+		return
+	}
+
+	if len(cmd.Val()) == 0 {
+		return
+	}
+
+	s = &Schedule{Id: id}
+
+	if err = cmd.Scan(s); err != nil {
+		return nil, err
+	}
+
+	s.normalize()
+
+	return
+}
+
+func GetSchedules(client redis.UniversalClient, ctx context.Context, booleanId string) (schedules []*Schedule, err error) {
+	ctx, span := startSpan(ctx, "GetSchedules", attribute.String(ATTRIBUTE_BOOLEAN_ID, booleanId))
+	defer func() { endSpan(span, err) }()
+
+	if client.Exists(ctx, booleanId).Val() == 0 {
+		slog.DebugContext(ctx, "boolean not found", "id", booleanId)
+
+		return nil, errors.NewHTTPError(http.StatusNotFound, &errors.NOT_FOUND_ERROR)
+	}
+
+	var ids []string
+	if ids, err = client.ZRange(ctx, booleanSchedulesKey(booleanId), 0, -1).Result(); err != nil {
+		slog.ErrorContext(ctx, "failed to read schedules of boolean", "error", err, "id", booleanId)
+
+		return nil, errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+	}
+
+	schedules = make([]*Schedule, 0, len(ids))
+
+	for _, id := range ids {
+		var s *Schedule
+		if s, err = getSchedule(client, ctx, id); err != nil {
+			slog.ErrorContext(ctx, "failed to read schedule", "error", err, "id", booleanId, "schedule_id", id)
+
+			return nil, errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		if s != nil {
+			schedules = append(schedules, s)
+		}
+	}
+
+	return
+}
+
+func DeleteSchedule(client redis.UniversalClient, ctx context.Context, booleanId, id string) (err error) {
+	ctx, span := startSpan(ctx, "DeleteSchedule", attribute.String(ATTRIBUTE_BOOLEAN_ID, booleanId), attribute.String(ATTRIBUTE_SCHEDULE_ID, id))
+	defer func() { endSpan(span, err) }()
+
+	var s *Schedule
+	if s, err = getSchedule(client, ctx, id); err != nil {
+		slog.ErrorContext(ctx, "failed to read schedule", "error", err, "id", booleanId, "schedule_id", id)
+
+		return errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+	}
+
+	if s == nil || s.BooleanId != booleanId {
+		slog.DebugContext(ctx, "schedule not found", "id", booleanId, "schedule_id", id)
+
+		return errors.NewHTTPError(http.StatusNotFound, &errors.NOT_FOUND_ERROR)
+	}
+
+	if err = removeSchedule(client, ctx, s); err != nil {
+		slog.ErrorContext(ctx, "failed to delete schedule", "error", err, "id", booleanId, "schedule_id", id)
+
+		return errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+	}
+
+	return
+}
+
+func removeSchedule(client redis.UniversalClient, ctx context.Context, s *Schedule) (err error) {
+	_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, SCHEDULES_DUE_KEY, s.Id)
+		pipe.ZRem(ctx, booleanSchedulesKey(s.BooleanId), s.Id)
+		pipe.Del(ctx, scheduleKey(s.Id))
+
+		return nil
+	})
+
+	return
+}
+
+// deleteSchedules deletes the schedules, once their index was deleted along
+// with the boolean.
+func deleteSchedules(client redis.UniversalClient, ctx context.Context, ids []string) (err error) {
+	if len(ids) == 0 {
+		return
+	}
+
+	_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, id := range ids {
+			pipe.ZRem(ctx, SCHEDULES_DUE_KEY, id)
+			pipe.Del(ctx, scheduleKey(id))
+		}
+
+		return nil
+	})
+
+	return
+}
+
+// RunDueSchedules applies all schedules due at the given time. A schedule is
+// claimed by postponing it by SCHEDULE_LEASE first, so that concurrent workers
+// skip it. Each run is applied at most once, a worker failing in between
+// leaves the schedule due again once the lease has passed, which only
+// finishes the run. A run failing to apply is retried after
+// SCHEDULE_RETRY_DELAY, up to SCHEDULE_MAX_ATTEMPTS times.
+func RunDueSchedules(client redis.UniversalClient, ctx context.Context, now time.Time) (applied int, err error) {
+	ctx, span := startSpan(ctx, "RunDueSchedules")
+	defer func() {
+		span.SetAttributes(attribute.Int("baas.schedules.applied", applied))
+
+		endSpan(span, err)
+	}()
+
+	for {
+		var ids []string
+		if ids, err = client.ZRangeByScore(ctx, SCHEDULES_DUE_KEY, &redis.ZRangeBy{
+			Min:   "-inf",
+			Max:   strconv.FormatInt(now.Unix(), 10),
+			Count: SCHEDULES_BATCH_SIZE,
+		}).Result(); err != nil {
+			return
+		}
+
+		if len(ids) == 0 {
+			return
+		}
+
+		for _, id := range ids {
+			var claimed int64
+			if claimed, err = claimScheduleScript.Run(ctx, client, []string{SCHEDULES_DUE_KEY}, id, now.Unix(), now.Add(SCHEDULE_LEASE).Unix()).Int64(); err != nil {
+				return
+			}
+
+			if claimed == 0 {
+				continue
+			}
+
+			if err = runSchedule(client, ctx, id, now); err != nil {
+				slog.ErrorContext(ctx, "failed to run schedule", "error", err, "schedule_id", id)
+
+				continue
+			}
+
+			applied++
+		}
+	}
+}
+
+// runSchedule applies the claimed schedule and removes it or moves it to its
+// next run. Schedules of inexistent or computed booleans are dropped.
+func runSchedule(client redis.UniversalClient, ctx context.Context, id string, now time.Time) (err error) {
+	var s *Schedule
+	if s, err = getSchedule(client, ctx, id); err != nil {
+		return
+	}
+
+	if s == nil {
+		if e := client.ZRem(ctx, SCHEDULES_DUE_KEY, id).Err(); e != nil {
+			slog.ErrorContext(ctx, "failed to remove schedule", "error", e, "schedule_id", id)
+		}
+
+		return fmt.Errorf("schedule with ID %s not found", id)
+	}
+
+	var next int64
+	if next, err = s.nextAt(now); err != nil || (s.Action != SCHEDULE_ACTION_SET && s.Action != SCHEDULE_ACTION_TOGGLE) {
+		if e := removeSchedule(client, ctx, s); e != nil {
+			slog.ErrorContext(ctx, "failed to remove schedule", "error", e, "id", s.BooleanId, "schedule_id", s.Id)
+		}
+
+		return fmt.Errorf("invalid schedule with ID %s dropped", s.Id)
+	}
+
+	// the run is marked in the index of schedules of the boolean, which
+	// earlier versions wrote without hash tag
+	if legacy := SCHEDULES_KEY_PREFIX + s.BooleanId; client.ZScore(ctx, legacy, s.Id).Err() == nil {
+		if err = MigrateKey(client, ctx, legacy); err != nil {
+			return
+		}
+	}
+
+	var result int64
+	if result, err = applySchedule(client, ctx, s, next); err != nil {
+		return retrySchedule(client, ctx, s, now, next, err)
+	}
+
+	switch result {
+	case applyScheduleNotFound, applyScheduleComputed:
+		if e := removeSchedule(client, ctx, s); e != nil {
+			slog.ErrorContext(ctx, "failed to remove schedule", "error", e, "id", s.BooleanId, "schedule_id", s.Id)
+		}
+
+		if result == applyScheduleNotFound {
+			return fmt.Errorf("boolean with ID %s not found, schedule with ID %s dropped", s.BooleanId, s.Id)
+		}
+
+		return fmt.Errorf("boolean with ID %s is computed, schedule with ID %s dropped", s.BooleanId, s.Id)
+	case applyScheduleLeased:
+		return retrySchedule(client, ctx, s, now, next, fmt.Errorf("boolean with ID %s is leased", s.BooleanId))
+	case applyScheduleDone:
+		// the run was applied by a worker, which failed to finish it, or the
+		// schedule was deleted in the meantime
+		var at float64
+		if at, err = client.ZScore(ctx, booleanSchedulesKey(s.BooleanId), s.Id).Result(); err == redis.Nil {
+			next, err = 0, nil
+		} else if err != nil {
+			return
+		} else {
+			next = int64(at)
+		}
+	}
+
+	if e := finishSchedule(client, ctx, s, next); e != nil {
+		slog.ErrorContext(ctx, "failed to finish schedule", "error", e, "id", s.BooleanId, "schedule_id", s.Id)
+	}
+
+	return
+}
+
+// applySchedule applies the current run of the schedule, see
+// applyScheduleScript.
+func applySchedule(client redis.UniversalClient, ctx context.Context, s *Schedule, next int64) (result int64, err error) {
+	keys := []string{s.BooleanId, booleanSchedulesKey(s.BooleanId)}
+
+	if result, err = applyScheduleScript.Run(ctx, client, keys, s.Id, s.At, s.Action, s.Value, now().Unix(), next).Int64(); err != nil {
+		return
+	}
+
+	if result == applyScheduleApplied {
+		syncShadow(client, ctx, s.BooleanId)
+
+		if s.Action == SCHEDULE_ACTION_TOGGLE {
+			metrics.BooleanOperations.WithLabelValues(metrics.OPERATION_TOGGLE).Inc()
+		}
+	}
+
+	return
+}
+
+// retrySchedule postpones the schedule by SCHEDULE_RETRY_DELAY. Once it failed
+// SCHEDULE_MAX_ATTEMPTS times, the run is skipped instead.
+func retrySchedule(client redis.UniversalClient, ctx context.Context, s *Schedule, now time.Time, next int64, cause error) (err error) {
+	var attempts int64
+	if attempts, err = countAttemptScript.Run(ctx, client, []string{scheduleKey(s.Id)}).Int64(); err != nil || attempts == 0 {
+		return cause
+	}
+
+	if attempts < SCHEDULE_MAX_ATTEMPTS {
+		// the schedule may have been deleted in the meantime, XX keeps it so
+		if e := client.ZAddXX(ctx, SCHEDULES_DUE_KEY, redis.Z{Score: float64(now.Add(SCHEDULE_RETRY_DELAY).Unix()), Member: s.Id}).Err(); e != nil {
+			slog.ErrorContext(ctx, "failed to retry schedule", "error", e, "id", s.BooleanId, "schedule_id", s.Id)
+		}
+
+		return cause
+	}
+
+	slog.WarnContext(ctx, "giving up schedule run", "error", cause, "id", s.BooleanId, "schedule_id", s.Id, "attempts", attempts)
+
+	if e := finishSchedule(client, ctx, s, next); e != nil {
+		slog.ErrorContext(ctx, "failed to finish schedule", "error", e, "id", s.BooleanId, "schedule_id", s.Id)
+	}
+
+	return cause
+}
+
+// finishSchedule moves the schedule to its next run at next, or removes it,
+// if there is none.
+func finishSchedule(client redis.UniversalClient, ctx context.Context, s *Schedule, next int64) (err error) {
+	if next == 0 {
+		return removeSchedule(client, ctx, s)
+	}
+
+	s.At = next
+
+	_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, scheduleKey(s.Id), "at", s.At)
+		pipe.HDel(ctx, scheduleKey(s.Id), "attempts")
+		pipe.ZAdd(ctx, booleanSchedulesKey(s.BooleanId), redis.Z{Score: float64(s.At), Member: s.Id})
+		pipe.ZAdd(ctx, SCHEDULES_DUE_KEY, redis.Z{Score: float64(s.At), Member: s.Id})
+
+		return nil
+	})
+
+	return
+}
+
+func ParseScheduleParams(r *http.Request) (p *ScheduleParams, err error) {
+	p = new(ScheduleParams)
+
+	if err = decoder.Decode(p, r.URL.Query()); err != nil {
+		slog.DebugContext(r.Context(), "failed to decode query", "error", err)
+
+		return nil, errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+	}
+
+	if err = p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+func ParseSchedule(r *http.Request, booleanId string) (s *Schedule, err error) {
+	s = &Schedule{BooleanId: booleanId}
+
+	var mt string
+	if mt, err = mediaType(r); err != nil {
+		return
+	}
+
+	switch mt {
+	case CONTENT_TYPE_JSON:
+		if err = parseJsonEncodedBody(r, s); err != nil {
+			return
+		}
+	case CONTENT_TYPE_FORM:
+		if err = parseUrlEncodedBody(r, s); err != nil {
+			return
+		}
+	default:
+		err = errors.NewHTTPError(http.StatusUnsupportedMediaType, &errors.UNSUPPORTED_MEDIA_TYPE_ERROR)
+		return
+	}
+
+	s.Id = ""
+	s.BooleanId = booleanId
+
+	if err = s.Validate(); err != nil {
+		return
+	}
+
+	return
+}
+
+func CreateScheduleResponse(s *Schedule) (body *scheduleResponse) {
+	body = &scheduleResponse{
+		Data: s,
+	}
+
+	return
+}
+
+func CreateSchedulesResponse(s []*Schedule) (body *schedulesResponse) {
+	body = &schedulesResponse{
+		Data: s,
+	}
+
+	return
+}
@@ -0,0 +1,441 @@
+package booleans
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSchedule(t *testing.T) {
+	value := true
+
+	type testStruct struct {
+		name    string
+		data    Schedule
+		wantErr bool
+	}
+
+	tests := []testStruct{
+		{
+			name: "valid set schedule",
+			data: Schedule{
+				At:  time.Now().Unix() + 120,
+				Set: &value,
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid toggle schedule",
+			data: Schedule{
+				At:     time.Now().Unix() + 120,
+				Toggle: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "missing action",
+			data: Schedule{
+				At: time.Now().Unix() + 120,
+			},
+			wantErr: true,
+		},
+		{
+			name: "both set and toggle",
+			data: Schedule{
+				At:     time.Now().Unix() + 120,
+				Set:    &value,
+				Toggle: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid recurring schedule",
+			data: Schedule{
+				Cron:     "0 9 * * MON-FRI",
+				Timezone: "Europe/Vienna",
+				Set:      &value,
+			},
+			wantErr: false,
+		},
+		{
+			name: "recurring schedule with time zone in expression",
+			data: Schedule{
+				Cron: "0 18 * * MON-FRI Europe/Vienna",
+				Set:  &value,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid cron expression",
+			data: Schedule{
+				Cron: "0 25 * * *",
+				Set:  &value,
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid time zone",
+			data: Schedule{
+				Cron:     "0 9 * * *",
+				Timezone: "Mars/Olympus_Mons",
+				Set:      &value,
+			},
+			wantErr: true,
+		},
+		{
+			name: "both at and cron",
+			data: Schedule{
+				At:   time.Now().Unix() + 120,
+				Cron: "0 9 * * *",
+				Set:  &value,
+			},
+			wantErr: true,
+		},
+		{
+			name: "at in the past",
+			data: Schedule{
+				At:  time.Now().Unix() - 1,
+				Set: &value,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.data.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("Schedule.Validate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchedules(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	opts, err := redis.ParseURL(os.Getenv(db.REDIS_URL_ENV))
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	rdb := redis.NewClient(opts)
+
+	b := Boolean{Label: "maintenance"}
+
+	if err = b.Save(rdb, ctx); err != nil {
+		t.Fatalf("Boolean.Save() error = %v", err)
+	}
+
+	value := true
+	at := time.Now().Unix() + 60
+
+	on := Schedule{BooleanId: *b.Id, At: at, Set: &value}
+	toggle := Schedule{BooleanId: *b.Id, At: at + 60, Toggle: true}
+
+	for _, s := range []*Schedule{&on, &toggle} {
+		if err = s.Save(rdb, ctx); err != nil {
+			t.Fatalf("Schedule.Save() error = %v", err)
+		}
+	}
+
+	schedules, err := GetSchedules(rdb, ctx, *b.Id)
+	if err != nil {
+		t.Fatalf("GetSchedules() error = %v", err)
+	}
+
+	assert.Len(t, schedules, 2)
+	assert.Equal(t, on.Id, schedules[0].Id)
+	assert.Equal(t, &value, schedules[0].Set)
+	assert.True(t, schedules[1].Toggle)
+
+	t.Run("applies due schedules exactly once", func(t *testing.T) {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+
+		total := 0
+
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				applied, err := RunDueSchedules(rdb, ctx, time.Unix(at, 0))
+				if err != nil {
+					t.Errorf("RunDueSchedules() error = %v", err)
+				}
+
+				mu.Lock()
+				total += applied
+				mu.Unlock()
+			}()
+		}
+
+		wg.Wait()
+
+		assert.Equal(t, 1, total)
+
+		got, err := GetBoolean(rdb, ctx, *b.Id)
+		if err != nil {
+			t.Fatalf("GetBoolean() error = %v", err)
+		}
+
+		assert.True(t, got.Value)
+
+		if schedules, err = GetSchedules(rdb, ctx, *b.Id); err != nil {
+			t.Fatalf("GetSchedules() error = %v", err)
+		}
+
+		assert.Len(t, schedules, 1)
+	})
+
+	t.Run("deletes schedules", func(t *testing.T) {
+		if err := DeleteSchedule(rdb, ctx, *b.Id, toggle.Id); err != nil {
+			t.Fatalf("DeleteSchedule() error = %v", err)
+		}
+
+		applied, err := RunDueSchedules(rdb, ctx, time.Unix(at+60, 0))
+		if err != nil {
+			t.Fatalf("RunDueSchedules() error = %v", err)
+		}
+
+		assert.Equal(t, 0, applied)
+	})
+
+	t.Run("retries schedules, which failed to apply", func(t *testing.T) {
+		s := Schedule{BooleanId: *b.Id, At: at + 120, Set: &value}
+
+		if err := s.Save(rdb, ctx); err != nil {
+			t.Fatalf("Schedule.Save() error = %v", err)
+		}
+
+		leaseExpiresAt := time.Now().Add(time.Hour).Unix()
+
+		if err := rdb.HSet(ctx, *b.Id, BOOLEAN_VALUE, false, BOOLEAN_LEASE_EXPIRES_AT, leaseExpiresAt).Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		applied, err := RunDueSchedules(rdb, ctx, time.Unix(at+120, 0))
+		if err != nil {
+			t.Fatalf("RunDueSchedules() error = %v", err)
+		}
+
+		assert.Equal(t, 0, applied)
+		assert.Equal(t, float64(time.Unix(at+120, 0).Add(SCHEDULE_RETRY_DELAY).Unix()), rdb.ZScore(ctx, SCHEDULES_DUE_KEY, s.Id).Val())
+
+		if err := rdb.HDel(ctx, *b.Id, BOOLEAN_LEASE_EXPIRES_AT).Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		if applied, err = RunDueSchedules(rdb, ctx, time.Unix(at+120, 0).Add(SCHEDULE_RETRY_DELAY)); err != nil {
+			t.Fatalf("RunDueSchedules() error = %v", err)
+		}
+
+		assert.Equal(t, 1, applied)
+		assert.Equal(t, "1", rdb.HGet(ctx, *b.Id, BOOLEAN_VALUE).Val())
+		assert.Zero(t, rdb.Exists(ctx, scheduleKey(s.Id)).Val())
+	})
+
+	t.Run("skips claimed schedules until their lease passes", func(t *testing.T) {
+		s := Schedule{BooleanId: *b.Id, At: at + 180, Toggle: true}
+
+		if err := s.Save(rdb, ctx); err != nil {
+			t.Fatalf("Schedule.Save() error = %v", err)
+		}
+
+		claimed := time.Unix(at+180, 0)
+
+		// claim the schedule as a worker would, which fails before applying it
+		if err := claimScheduleScript.Run(ctx, rdb, []string{SCHEDULES_DUE_KEY}, s.Id, claimed.Unix(), claimed.Add(SCHEDULE_LEASE).Unix()).Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		applied, err := RunDueSchedules(rdb, ctx, claimed)
+		if err != nil {
+			t.Fatalf("RunDueSchedules() error = %v", err)
+		}
+
+		assert.Equal(t, 0, applied)
+
+		if applied, err = RunDueSchedules(rdb, ctx, claimed.Add(SCHEDULE_LEASE)); err != nil {
+			t.Fatalf("RunDueSchedules() error = %v", err)
+		}
+
+		assert.Equal(t, 1, applied)
+		assert.Equal(t, "0", rdb.HGet(ctx, *b.Id, BOOLEAN_VALUE).Val())
+	})
+
+	t.Run("finishes runs applied by a failed worker without applying them again", func(t *testing.T) {
+		s := Schedule{BooleanId: *b.Id, At: at + 300, Toggle: true}
+
+		if err := s.Save(rdb, ctx); err != nil {
+			t.Fatalf("Schedule.Save() error = %v", err)
+		}
+
+		claimed := time.Unix(at+300, 0)
+
+		// claim and apply the schedule as a worker would, which fails before
+		// removing it
+		if err := claimScheduleScript.Run(ctx, rdb, []string{SCHEDULES_DUE_KEY}, s.Id, claimed.Unix(), claimed.Add(SCHEDULE_LEASE).Unix()).Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		if result, err := applySchedule(rdb, ctx, &s, 0); err != nil || result != applyScheduleApplied {
+			t.Fatalf("applySchedule() = %d, error = %v", result, err)
+		}
+
+		assert.Equal(t, "1", rdb.HGet(ctx, *b.Id, BOOLEAN_VALUE).Val())
+
+		applied, err := RunDueSchedules(rdb, ctx, claimed.Add(SCHEDULE_LEASE))
+		if err != nil {
+			t.Fatalf("RunDueSchedules() error = %v", err)
+		}
+
+		assert.Equal(t, 1, applied)
+		assert.Equal(t, "1", rdb.HGet(ctx, *b.Id, BOOLEAN_VALUE).Val())
+		assert.Zero(t, rdb.Exists(ctx, scheduleKey(s.Id)).Val())
+		assert.Zero(t, rdb.ZScore(ctx, SCHEDULES_DUE_KEY, s.Id).Val())
+	})
+
+	t.Run("gives up runs after the maximum of attempts", func(t *testing.T) {
+		off := false
+		s := Schedule{BooleanId: *b.Id, At: at + 360, Set: &off}
+
+		if err := s.Save(rdb, ctx); err != nil {
+			t.Fatalf("Schedule.Save() error = %v", err)
+		}
+
+		if err := rdb.HSet(ctx, *b.Id, BOOLEAN_LEASE_EXPIRES_AT, time.Now().Add(time.Hour).Unix()).Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() {
+			rdb.HDel(ctx, *b.Id, BOOLEAN_LEASE_EXPIRES_AT)
+		})
+
+		for i := 0; i < SCHEDULE_MAX_ATTEMPTS; i++ {
+			assert.Equal(t, int64(1), rdb.Exists(ctx, scheduleKey(s.Id)).Val(), "attempt %d", i)
+
+			applied, err := RunDueSchedules(rdb, ctx, time.Unix(at+360, 0).Add(time.Duration(i)*SCHEDULE_RETRY_DELAY))
+			if err != nil {
+				t.Fatalf("RunDueSchedules() error = %v", err)
+			}
+
+			assert.Equal(t, 0, applied)
+		}
+
+		assert.Equal(t, "1", rdb.HGet(ctx, *b.Id, BOOLEAN_VALUE).Val())
+		assert.Zero(t, rdb.Exists(ctx, scheduleKey(s.Id)).Val())
+		assert.Zero(t, rdb.ZScore(ctx, SCHEDULES_DUE_KEY, s.Id).Val())
+	})
+
+	t.Run("does not recreate expired booleans", func(t *testing.T) {
+		s := Schedule{BooleanId: *b.Id, At: at + 240, Set: &value}
+
+		if err := s.Save(rdb, ctx); err != nil {
+			t.Fatalf("Schedule.Save() error = %v", err)
+		}
+
+		// simulate the expiry, which keeps the schedule until it is due
+		if err := rdb.Rename(ctx, *b.Id, "expired").Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() {
+			rdb.Rename(ctx, "expired", *b.Id)
+		})
+
+		applied, err := RunDueSchedules(rdb, ctx, time.Unix(at+240, 0))
+		if err != nil {
+			t.Fatalf("RunDueSchedules() error = %v", err)
+		}
+
+		assert.Equal(t, 0, applied)
+		assert.Zero(t, rdb.Exists(ctx, *b.Id).Val())
+		assert.Zero(t, rdb.Exists(ctx, scheduleKey(s.Id)).Val())
+	})
+
+	t.Run("reschedules recurring schedules across DST", func(t *testing.T) {
+		vienna, err := time.LoadLocation("Europe/Vienna")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Friday before the switch to CEST
+		clock := time.Date(2026, 3, 27, 12, 0, 0, 0, vienna)
+
+		now = func() time.Time { return clock }
+
+		t.Cleanup(func() {
+			now = time.Now
+		})
+
+		off := false
+		s := Schedule{BooleanId: *b.Id, Cron: "0 9 * * * Europe/Vienna", Set: &off}
+
+		if err := s.Save(rdb, ctx); err != nil {
+			t.Fatalf("Schedule.Save() error = %v", err)
+		}
+
+		s.Preview(3)
+
+		assert.Equal(t, []string{
+			"2026-03-28T09:00:00+01:00",
+			"2026-03-29T09:00:00+02:00",
+			"2026-03-30T09:00:00+02:00",
+		}, s.Next)
+
+		for _, want := range []time.Time{
+			time.Date(2026, 3, 28, 8, 0, 0, 0, time.UTC),
+			time.Date(2026, 3, 29, 7, 0, 0, 0, time.UTC),
+		} {
+			assert.Equal(t, want.Unix(), s.At)
+
+			applied, err := RunDueSchedules(rdb, ctx, want)
+			if err != nil {
+				t.Fatalf("RunDueSchedules() error = %v", err)
+			}
+
+			assert.Equal(t, 1, applied)
+
+			got, err := getSchedule(rdb, ctx, s.Id)
+			if err != nil || got == nil {
+				t.Fatalf("getSchedule() = %v, error = %v", got, err)
+			}
+
+			s = *got
+		}
+
+		assert.Equal(t, time.Date(2026, 3, 30, 7, 0, 0, 0, time.UTC).Unix(), s.At)
+
+		if err := DeleteSchedule(rdb, ctx, *b.Id, s.Id); err != nil {
+			t.Fatalf("DeleteSchedule() error = %v", err)
+		}
+	})
+
+	t.Run("rejects schedules for inexistent booleans", func(t *testing.T) {
+		s := Schedule{BooleanId: "inexistent", At: at, Set: &value}
+
+		if err := s.Save(rdb, ctx); err == nil {
+			t.Errorf("Schedule.Save() error = %v, wantErr %v", err, true)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package booleans
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// STATS_EXPIRED_KEY counts the booleans reported as expired.
+const STATS_EXPIRED_KEY = "stats:expired"
+
+// CountBooleans counts the stored booleans by scanning the whole database, it
+// should be called sparingly, e.g. once per metrics scrape.
+func CountBooleans(client redis.UniversalClient, ctx context.Context) (count int64, err error) {
+	var cursor uint64
+
+	for {
+		var keys []string
+		if keys, cursor, err = scanKeys(client, ctx, cursor, MAINTENANCE_SCAN_COUNT, "hash"); err != nil {
+			return
+		}
+
+		for _, key := range keys {
+			if isBooleanKey(key) {
+				count++
+			}
+		}
+
+		if cursor == 0 {
+			return
+		}
+	}
+}
+
+// ExpiredBooleans returns the amount of booleans reported as expired by
+// HandleExpired, which requires a running expiry-events worker.
+func ExpiredBooleans(client redis.UniversalClient, ctx context.Context) (count int64, err error) {
+	if count, err = client.Get(ctx, STATS_EXPIRED_KEY).Int64(); err == redis.Nil {
+		return 0, nil
+	}
+
+	return
+}
@@ -0,0 +1,68 @@
+package booleans
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	opts, err := redis.ParseURL(os.Getenv(db.REDIS_URL_ENV))
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	rdb := redis.NewClient(opts)
+
+	for _, label := range []string{"first", "second"} {
+		b := Boolean{Label: label, Value: true}
+		if err = b.Save(rdb, ctx); err != nil {
+			t.Fatalf("Boolean.Save() error = %v", err)
+		}
+
+		s := Schedule{BooleanId: *b.Id, At: time.Now().Unix() + 3600, Toggle: true}
+		if err = s.Save(rdb, ctx); err != nil {
+			t.Fatalf("Schedule.Save() error = %v", err)
+		}
+	}
+
+	t.Run("counts booleans", func(t *testing.T) {
+		count, err := CountBooleans(rdb, ctx)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, 2, count)
+	})
+
+	t.Run("reads expired booleans", func(t *testing.T) {
+		count, err := ExpiredBooleans(rdb, ctx)
+
+		assert.NoError(t, err)
+		assert.Zero(t, count)
+
+		rdb.IncrBy(ctx, STATS_EXPIRED_KEY, 3)
+
+		count, err = ExpiredBooleans(rdb, ctx)
+
+		assert.NoError(t, err)
+		assert.EqualValues(t, 3, count)
+	})
+}
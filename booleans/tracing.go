@@ -0,0 +1,41 @@
+package booleans
+
+import (
+	"context"
+
+	"github.com/saschazar21/go-baas/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	ATTRIBUTE_BOOLEAN_ID  = "baas.boolean.id"
+	ATTRIBUTE_SCHEDULE_ID = "baas.schedule.id"
+)
+
+var tracer = otel.Tracer("github.com/saschazar21/go-baas/booleans")
+
+// startSpan starts the span of an operation as child of the span in ctx.
+func startSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "booleans."+operation, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends the span, recording err. Client errors, e.g. 404 Not Found,
+// do not mark the span as failed.
+func endSpan(span trace.Span, err error) {
+	defer span.End()
+
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+
+	if httpErr, ok := err.(*errors.HTTPError); ok && httpErr.Status < 500 {
+		return
+	}
+
+	span.SetStatus(codes.Error, err.Error())
+}
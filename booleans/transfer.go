@@ -0,0 +1,652 @@
+package booleans
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/errors"
+)
+
+const (
+	FORMAT_NDJSON = "ndjson"
+	FORMAT_CSV    = "csv"
+
+	CONTENT_TYPE_NDJSON = "application/x-ndjson"
+	CONTENT_TYPE_CSV    = "text/csv"
+
+	IMPORT_MODE_SKIP      = "skip"
+	IMPORT_MODE_OVERWRITE = "overwrite"
+	IMPORT_MODE_FAIL      = "fail"
+
+	// MAX_IMPORT_LINE_SIZE limits the size of a single NDJSON record.
+	MAX_IMPORT_LINE_SIZE = 64 * 1024
+)
+
+// csvHeader lists the columns of CSV exports, the rollout is JSON encoded.
+var csvHeader = []string{"id", "label", "value", "expression", "rollout", "ttl", "expires_at", "created_at", "updated_at"}
+
+// ContentTypes maps the transfer formats to their media type.
+var ContentTypes = map[string]string{
+	FORMAT_NDJSON: CONTENT_TYPE_NDJSON,
+	FORMAT_CSV:    CONTENT_TYPE_CSV,
+}
+
+type importResponse struct {
+	Data *ImportResult `json:"data"`
+}
+
+type ExportParams struct {
+	Format string `schema:"format" validate:"omitempty,oneof=ndjson csv"`
+}
+
+func (e *ExportParams) Validate() (err error) {
+	if err = CustomValidateStruct(e); err != nil {
+		slog.Debug("invalid export parameters", "error", err)
+
+		return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+	}
+
+	return
+}
+
+type ImportParams struct {
+	Mode   string `schema:"mode" validate:"omitempty,oneof=skip overwrite fail"`
+	Format string `schema:"-"`
+
+	// MaxTTL and DefaultTTL limit the expiry of imported booleans like
+	// BooleanParams.LimitTTL.
+	MaxTTL     time.Duration `schema:"-"`
+	DefaultTTL time.Duration `schema:"-"`
+}
+
+func (i *ImportParams) Validate() (err error) {
+	if err = CustomValidateStruct(i); err != nil {
+		slog.Debug("invalid import parameters", "error", err)
+
+		return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+	}
+
+	return
+}
+
+// ExportedBoolean is the representation of a boolean in exports. The expiry
+// is exported both as remaining TTL in seconds, -1 if the boolean does not
+// expire, and as absolute Unix epoch in seconds, which takes precedence on
+// import.
+type ExportedBoolean struct {
+	Id         string   `json:"id" validate:"required,max=256,boolean-id"`
+	Label      string   `json:"label,omitempty"`
+	Value      bool     `json:"value"`
+	Expression string   `json:"expression,omitempty"`
+	Rollout    *Rollout `json:"rollout,omitempty"`
+	TTL        int64    `json:"ttl"`
+	ExpiresAt  int64    `json:"expires_at,omitempty"`
+	CreatedAt  int64    `json:"created_at,omitempty"`
+	UpdatedAt  int64    `json:"updated_at,omitempty"`
+}
+
+func (e *ExportedBoolean) boolean() *Boolean {
+	return &Boolean{
+		Label:      e.Label,
+		Value:      e.Value,
+		Expression: e.Expression,
+		Rollout:    e.Rollout,
+		CreatedAt:  e.CreatedAt,
+		UpdatedAt:  e.UpdatedAt,
+	}
+}
+
+// Validate validates the exported boolean using Boolean.Validate.
+func (e *ExportedBoolean) Validate() (err error) {
+	if err = CustomValidateStruct(e); err != nil {
+		return
+	}
+
+	return e.boolean().Validate()
+}
+
+// expiry returns the absolute expiry of the exported boolean, or 0 if it
+// does not expire.
+func (e *ExportedBoolean) expiry() int64 {
+	if e.ExpiresAt > 0 {
+		return e.ExpiresAt
+	}
+
+	if e.TTL > 0 {
+		return now().Unix() + e.TTL
+	}
+
+	return 0
+}
+
+func (e *ExportedBoolean) csvRecord() (record []string, err error) {
+	var rollout []byte
+	if e.Rollout != nil {
+		if rollout, err = json.Marshal(e.Rollout); err != nil {
+			return
+		}
+	}
+
+	return []string{
+		e.Id,
+		e.Label,
+		strconv.FormatBool(e.Value),
+		e.Expression,
+		string(rollout),
+		strconv.FormatInt(e.TTL, 10),
+		formatEpoch(e.ExpiresAt),
+		formatEpoch(e.CreatedAt),
+		formatEpoch(e.UpdatedAt),
+	}, nil
+}
+
+func formatEpoch(epoch int64) string {
+	if epoch == 0 {
+		return ""
+	}
+
+	return strconv.FormatInt(epoch, 10)
+}
+
+func parseEpoch(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func parseCSVRecord(record []string) (e *ExportedBoolean, err error) {
+	if len(record) != len(csvHeader) {
+		return nil, fmt.Errorf("expected %d columns, got %d", len(csvHeader), len(record))
+	}
+
+	e = &ExportedBoolean{
+		Id:         record[0],
+		Label:      record[1],
+		Expression: record[3],
+	}
+
+	if e.Value, err = strconv.ParseBool(record[2]); err != nil {
+		return nil, fmt.Errorf("invalid value %q", record[2])
+	}
+
+	if record[4] != "" {
+		e.Rollout = new(Rollout)
+
+		if err = json.Unmarshal([]byte(record[4]), e.Rollout); err != nil {
+			return nil, fmt.Errorf("invalid rollout %q", record[4])
+		}
+	}
+
+	if e.TTL, err = strconv.ParseInt(record[5], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid ttl %q", record[5])
+	}
+
+	for i, field := range []*int64{&e.ExpiresAt, &e.CreatedAt, &e.UpdatedAt} {
+		if *field, err = parseEpoch(record[6+i]); err != nil {
+			return nil, fmt.Errorf("invalid %s %q", csvHeader[6+i], record[6+i])
+		}
+	}
+
+	return
+}
+
+// ExportBooleans streams all booleans to w in the given format, with their
+// value as reported by reads. Leases themselves are not exported, as they are
+// transient.
+func ExportBooleans(client redis.UniversalClient, ctx context.Context, format string, w io.Writer) (err error) {
+	ctx, span := startSpan(ctx, "ExportBooleans")
+	defer func() { endSpan(span, err) }()
+
+	var write func(e *ExportedBoolean) error
+	var flush func() error
+
+	switch format {
+	case FORMAT_CSV:
+		writer := csv.NewWriter(w)
+
+		if err = writer.Write(csvHeader); err != nil {
+			return
+		}
+
+		write = func(e *ExportedBoolean) (err error) {
+			var record []string
+			if record, err = e.csvRecord(); err != nil {
+				return
+			}
+
+			return writer.Write(record)
+		}
+
+		flush = func() error {
+			writer.Flush()
+
+			return writer.Error()
+		}
+	default:
+		encoder := json.NewEncoder(w)
+
+		write = func(e *ExportedBoolean) error {
+			return encoder.Encode(e)
+		}
+
+		flush = func() error {
+			return nil
+		}
+	}
+
+	var cursor uint64
+
+	for {
+		var keys []string
+		if keys, cursor, err = scanKeys(client, ctx, cursor, MAINTENANCE_SCAN_COUNT, "hash"); err != nil {
+			return
+		}
+
+		for _, key := range keys {
+			if !isBooleanKey(key) {
+				continue
+			}
+
+			var e *ExportedBoolean
+			if e, err = exportBoolean(client, ctx, key); err != nil {
+				return
+			}
+
+			if e == nil {
+				continue
+			}
+
+			if err = write(e); err != nil {
+				return
+			}
+		}
+
+		if cursor == 0 {
+			return flush()
+		}
+	}
+}
+
+func exportBoolean(client redis.UniversalClient, ctx context.Context, id string) (e *ExportedBoolean, err error) {
+	cmd := client.HGetAll(ctx, id)
+	if err = cmd.Err(); err != nil {
+		return
+	}
+
+	// the boolean expired in the meantime
+	if len(cmd.Val()) == 0 {
+		return nil, nil
+	}
+
+	b := new(Boolean)
+	if err = cmd.Scan(b); err != nil {
+		return nil, fmt.Errorf("failed to read boolean with ID %s: %w", id, err)
+	}
+
+	// like on reads, an expired lease, which was not reverted yet, is
+	// exported as released
+	if b.LeaseExpiresAt > 0 && b.LeaseExpiresAt <= now().Unix() {
+		b.Value = false
+	}
+
+	e = &ExportedBoolean{
+		Id:         id,
+		Label:      b.Label,
+		Value:      b.Value,
+		Expression: b.Expression,
+		Rollout:    b.Rollout,
+		CreatedAt:  b.CreatedAt,
+		UpdatedAt:  b.UpdatedAt,
+	}
+
+	if e.TTL, err = TTL(client, ctx, id); err != nil {
+		return
+	}
+
+	// the TTL is truncated to seconds, the expiry is read in milliseconds, so
+	// that it survives a round trip
+	var expireTime time.Duration
+	if expireTime, err = client.PExpireTime(ctx, id).Result(); err != nil {
+		return
+	}
+
+	if expireTime > 0 {
+		e.ExpiresAt = int64(expireTime.Round(time.Second) / time.Second)
+	}
+
+	return
+}
+
+type ImportResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+	Expired  int `json:"expired"`
+}
+
+func importError(record int, err error) error {
+	slog.Debug("invalid import record", "error", err, "record", record)
+
+	if errors.IsBodyTooLarge(err) {
+		return errors.NewHTTPError(http.StatusRequestEntityTooLarge, &errors.REQUEST_ENTITY_TOO_LARGE_ERROR)
+	}
+
+	// the validation details were logged by Boolean.Validate already
+	if _, ok := err.(*errors.HTTPError); ok {
+		err = fmt.Errorf("invalid boolean")
+	}
+
+	return errors.NewHTTPError(http.StatusBadRequest, &[]errors.ErrorContent{
+		{
+			Status: http.StatusBadRequest,
+			Title:  "Bad Request",
+			Detail: fmt.Sprintf("record %d: %v", record, err),
+		},
+	})
+}
+
+// readExport reads and validates all exported booleans of the given format.
+// IDs must be unique across the records.
+func readExport(format string, r io.Reader) (records []*ExportedBoolean, err error) {
+	seen := map[string]int{}
+
+	add := func(e *ExportedBoolean) error {
+		if err := e.Validate(); err != nil {
+			return importError(len(records)+1, err)
+		}
+
+		if record, ok := seen[e.Id]; ok {
+			return importError(len(records)+1, fmt.Errorf("duplicate ID %s of record %d", e.Id, record))
+		}
+
+		records = append(records, e)
+		seen[e.Id] = len(records)
+
+		return nil
+	}
+
+	switch format {
+	case FORMAT_CSV:
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = len(csvHeader)
+
+		var header []string
+		if header, err = reader.Read(); err != nil {
+			if err == io.EOF {
+				return records, nil
+			}
+
+			return nil, importError(0, err)
+		}
+
+		if header[0] != csvHeader[0] {
+			return nil, importError(0, fmt.Errorf("missing header"))
+		}
+
+		for {
+			var record []string
+			if record, err = reader.Read(); err == io.EOF {
+				return records, nil
+			} else if err != nil {
+				return nil, importError(len(records)+1, err)
+			}
+
+			var e *ExportedBoolean
+			if e, err = parseCSVRecord(record); err != nil {
+				return nil, importError(len(records)+1, err)
+			}
+
+			if err = add(e); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 4096), MAX_IMPORT_LINE_SIZE)
+
+		for scanner.Scan() {
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+
+			e := new(ExportedBoolean)
+			if err = json.Unmarshal(scanner.Bytes(), e); err != nil {
+				return nil, importError(len(records)+1, err)
+			}
+
+			if err = add(e); err != nil {
+				return nil, err
+			}
+		}
+
+		if err = scanner.Err(); err != nil {
+			return nil, importError(len(records)+1, err)
+		}
+
+		return records, nil
+	}
+}
+
+// importedBoolean is a record of an import, which is about to be written.
+type importedBoolean struct {
+	record  int
+	id      string
+	boolean *Boolean
+	expiry  int64
+	exists  bool
+}
+
+// ImportBooleans imports the booleans of an export. All records are validated
+// before any of them is written. Existing IDs are handled according to mode:
+// skip leaves them untouched, overwrite replaces them along with their
+// schedules and fail rejects the whole import. Like updates, overwriting
+// computed or leased booleans is rejected. Expressions may reference other
+// booleans of the import. Booleans, whose absolute expiry has passed, are not
+// imported, the expiry of the others is limited like BooleanParams.LimitTTL.
+func ImportBooleans(client redis.UniversalClient, ctx context.Context, params *ImportParams, r io.Reader) (result *ImportResult, err error) {
+	ctx, span := startSpan(ctx, "ImportBooleans")
+	defer func() { endSpan(span, err) }()
+
+	var records []*ExportedBoolean
+	if records, err = readExport(params.Format, r); err != nil {
+		return
+	}
+
+	result = new(ImportResult)
+
+	imports := make([]*importedBoolean, 0, len(records))
+	pending := make(map[string]*Boolean, len(records))
+
+	for i, e := range records {
+		expiry := e.expiry()
+
+		if expiry > 0 && expiry <= now().Unix() {
+			result.Expired++
+			continue
+		}
+
+		exists := client.Exists(ctx, e.Id).Val() > 0
+
+		if exists && params.Mode == IMPORT_MODE_FAIL {
+			slog.DebugContext(ctx, "boolean already exists", "id", e.Id)
+
+			return nil, errors.NewHTTPError(http.StatusConflict, &errors.CONFLICT_ERROR)
+		}
+
+		if exists && params.Mode != IMPORT_MODE_OVERWRITE {
+			result.Skipped++
+			continue
+		}
+
+		if exists {
+			if err = ensureWritable(client, ctx, e.Id); err != nil {
+				return nil, err
+			}
+		}
+
+		limited := &BooleanParams{ExpiresAt: Expiry(expiry)}
+		if err = limited.LimitTTL(params.MaxTTL, params.DefaultTTL); err != nil {
+			return nil, err
+		}
+
+		expiry = limited.expiresAt()
+
+		b := e.boolean()
+
+		if b.CreatedAt == 0 {
+			b.CreatedAt = now().Unix()
+		}
+
+		if b.UpdatedAt == 0 {
+			b.UpdatedAt = b.CreatedAt
+		}
+
+		imports = append(imports, &importedBoolean{record: i + 1, id: e.Id, boolean: b, expiry: expiry, exists: exists})
+		pending[e.Id] = b
+	}
+
+	// imported booleans take precedence over the stored ones they replace
+	read := func(id string) (*Boolean, error) {
+		if b, ok := pending[id]; ok {
+			return b, nil
+		}
+
+		cmd := client.HGetAll(ctx, id)
+		if err := cmd.Err(); err != nil || len(cmd.Val()) == 0 {
+			return nil, err
+		}
+
+		ref := new(Boolean)
+
+		return ref, cmd.Scan(ref)
+	}
+
+	for _, i := range imports {
+		if i.boolean.Expression == "" {
+			continue
+		}
+
+		id := i.id
+		if i.boolean.Value, err = resolveExpression(i.boolean.Expression, &id, read); err != nil {
+			if _, ok := err.(*expressionError); ok {
+				return nil, importError(i.record, err)
+			}
+
+			slog.ErrorContext(ctx, "failed to evaluate expression", "error", err, "id", i.id)
+
+			return nil, errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+	}
+
+	for _, i := range imports {
+		var schedules []string
+		if i.exists {
+			if schedules, err = client.ZRange(ctx, booleanSchedulesKey(i.id), 0, -1).Result(); err != nil {
+				slog.ErrorContext(ctx, "failed to read schedules of boolean", "error", err, "id", i.id)
+
+				return nil, errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+			}
+		}
+
+		if _, err = client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, i.id, booleanSchedulesKey(i.id))
+			pipe.HSet(ctx, i.id, i.boolean)
+
+			if i.expiry > 0 {
+				pipe.ExpireAt(ctx, i.id, time.Unix(i.expiry, 0))
+			}
+
+			return nil
+		}); err != nil {
+			slog.ErrorContext(ctx, "failed to import boolean", "error", err, "id", i.id)
+
+			return nil, errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		if err = deleteSchedules(client, ctx, schedules); err != nil {
+			slog.ErrorContext(ctx, "failed to delete schedules of boolean", "error", err, "id", i.id)
+
+			return nil, errors.NewHTTPError(http.StatusInternalServerError, &errors.INTERNAL_SERVER_ERROR)
+		}
+
+		syncShadow(client, ctx, i.id)
+
+		result.Imported++
+	}
+
+	return
+}
+
+func ParseExportParams(r *http.Request) (params *ExportParams, err error) {
+	params = new(ExportParams)
+
+	if err = decoder.Decode(params, r.URL.Query()); err != nil {
+		slog.DebugContext(r.Context(), "failed to decode query", "error", err)
+
+		return nil, errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+	}
+
+	if err = params.Validate(); err != nil {
+		return nil, err
+	}
+
+	if params.Format == "" {
+		params.Format = FORMAT_NDJSON
+	}
+
+	return
+}
+
+// ParseImportParams parses the import mode from the query and the format
+// from the Content-Type header.
+func ParseImportParams(r *http.Request) (params *ImportParams, err error) {
+	params = new(ImportParams)
+
+	if err = decoder.Decode(params, r.URL.Query()); err != nil {
+		slog.DebugContext(r.Context(), "failed to decode query", "error", err)
+
+		return nil, errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+	}
+
+	if err = params.Validate(); err != nil {
+		return nil, err
+	}
+
+	if params.Mode == "" {
+		params.Mode = IMPORT_MODE_SKIP
+	}
+
+	var mt string
+	if mt, err = mediaType(r); err != nil {
+		return nil, err
+	}
+
+	switch mt {
+	case CONTENT_TYPE_NDJSON:
+		params.Format = FORMAT_NDJSON
+	case CONTENT_TYPE_CSV:
+		params.Format = FORMAT_CSV
+	default:
+		return nil, errors.NewHTTPError(http.StatusUnsupportedMediaType, &errors.UNSUPPORTED_MEDIA_TYPE_ERROR)
+	}
+
+	return
+}
+
+func CreateImportResponse(result *ImportResult) (body *importResponse) {
+	body = &importResponse{
+		Data: result,
+	}
+
+	return
+}
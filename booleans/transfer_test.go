@@ -0,0 +1,341 @@
+package booleans
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/errors"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCSVRecord(t *testing.T) {
+	e := &ExportedBoolean{
+		Id:        "abc",
+		Label:     "deploy, freeze",
+		Value:     true,
+		Rollout:   &Rollout{Percentage: 25, Allow: []string{"user-42"}},
+		TTL:       3600,
+		ExpiresAt: 1700003600,
+		CreatedAt: 1700000000,
+		UpdatedAt: 1700000001,
+	}
+
+	record, err := e.csvRecord()
+	if err != nil {
+		t.Fatalf("csvRecord() error = %v", err)
+	}
+
+	got, err := parseCSVRecord(record)
+	if err != nil {
+		t.Fatalf("parseCSVRecord() error = %v", err)
+	}
+
+	assert.Equal(t, e, got)
+
+	tests := []struct {
+		name   string
+		record []string
+	}{
+		{
+			name:   "invalid value",
+			record: []string{"abc", "", "maybe", "", "", "-1", "", "", ""},
+		},
+		{
+			name:   "invalid rollout",
+			record: []string{"abc", "", "true", "", "{", "-1", "", "", ""},
+		},
+		{
+			name:   "invalid ttl",
+			record: []string{"abc", "", "true", "", "", "", "", "", ""},
+		},
+		{
+			name:   "invalid expiry",
+			record: []string{"abc", "", "true", "", "", "-1", "tomorrow", "", ""},
+		},
+		{
+			name:   "missing columns",
+			record: []string{"abc", "", "true"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCSVRecord(tt.record)
+
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	opts, err := redis.ParseURL(os.Getenv(db.REDIS_URL_ENV))
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	rdb := redis.NewClient(opts)
+
+	clock := time.Unix(time.Now().Unix(), 0)
+
+	now = func() time.Time { return clock }
+
+	t.Cleanup(func() {
+		now = time.Now
+	})
+
+	permanent := Boolean{Label: "deploy freeze", Value: true, Rollout: &Rollout{Percentage: 50}}
+	if err = permanent.Save(rdb, ctx); err != nil {
+		t.Fatalf("Boolean.Save() error = %v", err)
+	}
+
+	expiring := Boolean{Label: "maintenance window", BooleanParams: &BooleanParams{ExpiresIn: 3600}}
+	if err = expiring.Save(rdb, ctx); err != nil {
+		t.Fatalf("Boolean.Save() error = %v", err)
+	}
+
+	for _, format := range []string{FORMAT_NDJSON, FORMAT_CSV} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := ExportBooleans(rdb, ctx, format, &buf); err != nil {
+				t.Fatalf("ExportBooleans() error = %v", err)
+			}
+
+			export := buf.String()
+
+			records, err := readExport(format, strings.NewReader(export))
+			if err != nil {
+				t.Fatalf("readExport() error = %v", err)
+			}
+
+			assert.Len(t, records, 2)
+
+			var expiresAt int64
+
+			for _, e := range records {
+				switch e.Id {
+				case *permanent.Id:
+					assert.Equal(t, int64(-1), e.TTL)
+					assert.Zero(t, e.ExpiresAt)
+					assert.Equal(t, permanent.Rollout, e.Rollout)
+					assert.Equal(t, clock.Unix(), e.CreatedAt)
+				case *expiring.Id:
+					assert.Equal(t, clock.Unix()+3600, e.ExpiresAt)
+
+					expiresAt = e.ExpiresAt
+				default:
+					t.Errorf("unexpected ID %s", e.Id)
+				}
+			}
+
+			result, err := ImportBooleans(rdb, ctx, &ImportParams{Mode: IMPORT_MODE_SKIP, Format: format}, strings.NewReader(export))
+			if err != nil {
+				t.Fatalf("ImportBooleans() error = %v", err)
+			}
+
+			assert.Equal(t, &ImportResult{Skipped: 2}, result)
+
+			_, err = ImportBooleans(rdb, ctx, &ImportParams{Mode: IMPORT_MODE_FAIL, Format: format}, strings.NewReader(export))
+			if assert.Error(t, err) {
+				assert.Equal(t, http.StatusConflict, err.(*errors.HTTPError).Status)
+			}
+
+			if err = rdb.FlushDB(ctx).Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err = ImportBooleans(rdb, ctx, &ImportParams{Mode: IMPORT_MODE_FAIL, Format: format}, strings.NewReader(export))
+			if err != nil {
+				t.Fatalf("ImportBooleans() error = %v", err)
+			}
+
+			assert.Equal(t, &ImportResult{Imported: 2}, result)
+
+			// the absolute expiry is kept, instead of restarting the TTL
+			assert.Equal(t, time.Duration(expiresAt)*time.Second, rdb.ExpireTime(ctx, *expiring.Id).Val())
+
+			b, err := GetBoolean(rdb, ctx, *permanent.Id)
+			if err != nil {
+				t.Fatalf("GetBoolean() error = %v", err)
+			}
+
+			assert.Equal(t, permanent.Label, b.Label)
+			assert.True(t, b.Value)
+			assert.Equal(t, permanent.Rollout, b.Rollout)
+			assert.Equal(t, permanent.CreatedAt, b.CreatedAt)
+
+			if err = rdb.HSet(ctx, *permanent.Id, BOOLEAN_LABEL, "changed").Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err = ImportBooleans(rdb, ctx, &ImportParams{Mode: IMPORT_MODE_OVERWRITE, Format: format}, strings.NewReader(export))
+			if err != nil {
+				t.Fatalf("ImportBooleans() error = %v", err)
+			}
+
+			assert.Equal(t, &ImportResult{Imported: 2}, result)
+			assert.Equal(t, permanent.Label, rdb.HGet(ctx, *permanent.Id, BOOLEAN_LABEL).Val())
+
+			// once the absolute expiry passed, the boolean is not imported
+			clock = clock.Add(2 * time.Hour)
+
+			result, err = ImportBooleans(rdb, ctx, &ImportParams{Mode: IMPORT_MODE_OVERWRITE, Format: format}, strings.NewReader(export))
+			if err != nil {
+				t.Fatalf("ImportBooleans() error = %v", err)
+			}
+
+			assert.Equal(t, &ImportResult{Imported: 1, Expired: 1}, result)
+
+			clock = clock.Add(-2 * time.Hour)
+		})
+	}
+
+	invalid := []struct {
+		name   string
+		format string
+		body   string
+	}{
+		{
+			name:   "invalid JSON",
+			format: FORMAT_NDJSON,
+			body:   "{\"id\": ",
+		},
+		{
+			name:   "missing ID",
+			format: FORMAT_NDJSON,
+			body:   "{\"value\": true}\n",
+		},
+		{
+			name:   "invalid ID",
+			format: FORMAT_NDJSON,
+			body:   "{\"id\": \"schedule:abc\", \"value\": true}\n",
+		},
+		{
+			name:   "ID outside base58",
+			format: FORMAT_NDJSON,
+			body:   "{\"id\": \"a b\", \"value\": true}\n",
+		},
+		{
+			name:   "duplicate ID",
+			format: FORMAT_NDJSON,
+			body:   "{\"id\": \"dup\", \"value\": true}\n{\"id\": \"dup\", \"value\": false}\n",
+		},
+		{
+			name:   "unknown reference",
+			format: FORMAT_NDJSON,
+			body:   "{\"id\": \"abc\", \"value\": true, \"expression\": \"inexistent\"}\n",
+		},
+		{
+			name:   "cyclic references",
+			format: FORMAT_NDJSON,
+			body:   "{\"id\": \"abc\", \"value\": true, \"expression\": \"def\"}\n{\"id\": \"def\", \"value\": true, \"expression\": \"NOT abc\"}\n",
+		},
+		{
+			name:   "invalid rollout",
+			format: FORMAT_NDJSON,
+			body:   "{\"id\": \"abc\", \"value\": true, \"rollout\": {\"percentage\": 101}}\n",
+		},
+		{
+			name:   "missing CSV header",
+			format: FORMAT_CSV,
+			body:   "abc,,true,,,-1,,,\n",
+		},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ImportBooleans(rdb, ctx, &ImportParams{Mode: IMPORT_MODE_OVERWRITE, Format: tt.format}, strings.NewReader(tt.body))
+
+			if assert.Error(t, err) {
+				assert.Equal(t, http.StatusBadRequest, err.(*errors.HTTPError).Status)
+			}
+
+			assert.Zero(t, rdb.Exists(ctx, "dup").Val())
+		})
+	}
+
+	t.Run("resolves references within the import", func(t *testing.T) {
+		body := "{\"id\": \"abc\", \"expression\": \"NOT def\"}\n{\"id\": \"def\", \"value\": false}\n"
+
+		result, err := ImportBooleans(rdb, ctx, &ImportParams{Mode: IMPORT_MODE_OVERWRITE, Format: FORMAT_NDJSON}, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("ImportBooleans() error = %v", err)
+		}
+
+		assert.Equal(t, &ImportResult{Imported: 2}, result)
+		assert.Equal(t, "1", rdb.HGet(ctx, "abc", BOOLEAN_VALUE).Val())
+	})
+
+	t.Run("rejects overwriting computed and leased booleans", func(t *testing.T) {
+		leased := Boolean{Label: "deploy lock"}
+		if err := leased.Save(rdb, ctx); err != nil {
+			t.Fatalf("Boolean.Save() error = %v", err)
+		}
+
+		if err := rdb.HSet(ctx, *leased.Id, BOOLEAN_VALUE, true, BOOLEAN_LEASE_OWNER, "owner", BOOLEAN_LEASE_EXPIRES_AT, clock.Add(time.Hour).Unix()).Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, id := range []string{"abc", *leased.Id} {
+			body := "{\"id\": \"" + id + "\", \"value\": true}\n"
+
+			_, err := ImportBooleans(rdb, ctx, &ImportParams{Mode: IMPORT_MODE_OVERWRITE, Format: FORMAT_NDJSON}, strings.NewReader(body))
+			if assert.Error(t, err) {
+				assert.Equal(t, http.StatusConflict, err.(*errors.HTTPError).Status)
+			}
+		}
+
+		assert.Equal(t, "owner", rdb.HGet(ctx, *leased.Id, BOOLEAN_LEASE_OWNER).Val())
+	})
+
+	t.Run("deletes schedules of overwritten booleans", func(t *testing.T) {
+		s := Schedule{BooleanId: "def", At: clock.Unix() + 3600, Toggle: true}
+		if err := s.Save(rdb, ctx); err != nil {
+			t.Fatalf("Schedule.Save() error = %v", err)
+		}
+
+		body := "{\"id\": \"def\", \"value\": true}\n"
+
+		if _, err := ImportBooleans(rdb, ctx, &ImportParams{Mode: IMPORT_MODE_OVERWRITE, Format: FORMAT_NDJSON}, strings.NewReader(body)); err != nil {
+			t.Fatalf("ImportBooleans() error = %v", err)
+		}
+
+		assert.Zero(t, rdb.Exists(ctx, scheduleKey(s.Id), booleanSchedulesKey("def")).Val())
+		assert.Zero(t, rdb.ZScore(ctx, SCHEDULES_DUE_KEY, s.Id).Val())
+	})
+
+	t.Run("limits the expiry to the maximum TTL", func(t *testing.T) {
+		params := &ImportParams{Mode: IMPORT_MODE_OVERWRITE, Format: FORMAT_NDJSON, MaxTTL: 2 * time.Hour, DefaultTTL: time.Hour}
+
+		if _, err := ImportBooleans(rdb, ctx, params, strings.NewReader("{\"id\": \"ghi\", \"value\": true, \"ttl\": -1}\n")); err != nil {
+			t.Fatalf("ImportBooleans() error = %v", err)
+		}
+
+		assert.Equal(t, clock.Add(time.Hour).Unix(), rdb.ExpireTime(ctx, "ghi").Val().Milliseconds()/1000)
+
+		_, err := ImportBooleans(rdb, ctx, params, strings.NewReader("{\"id\": \"ghi\", \"value\": true, \"ttl\": 10800}\n"))
+		if assert.Error(t, err) {
+			assert.Equal(t, http.StatusBadRequest, err.(*errors.HTTPError).Status)
+		}
+	})
+}
@@ -1,16 +1,26 @@
 package booleans
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"fmt"
+	"io"
+	"log/slog"
 	"math/rand"
+	"mime"
 	"net/http"
+	"strings"
 
 	"github.com/saschazar21/go-baas/errors"
 )
 
 const base58 = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
 
+const (
+	CONTENT_TYPE_JSON = "application/json"
+	CONTENT_TYPE_FORM = "application/x-www-form-urlencoded"
+)
+
 func generateRandomId() string {
 	data := make([]byte, 16)
 
@@ -21,35 +31,94 @@ func generateRandomId() string {
 	return string(data)
 }
 
+// mediaType returns the media type of the request body, parameters are
+// ignored, except for the charset, which must be UTF-8, if given.
+func mediaType(r *http.Request) (string, error) {
+	mt, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		slog.DebugContext(r.Context(), "invalid content type", "error", err)
+
+		return "", errors.NewHTTPError(http.StatusUnsupportedMediaType, &errors.UNSUPPORTED_MEDIA_TYPE_ERROR)
+	}
+
+	if charset, ok := params["charset"]; ok && !strings.EqualFold(charset, "utf-8") {
+		slog.DebugContext(r.Context(), "unsupported charset", "charset", charset)
+
+		return "", errors.NewHTTPError(http.StatusUnsupportedMediaType, &[]errors.ErrorContent{
+			{
+				Status: http.StatusUnsupportedMediaType,
+				Title:  "Unsupported Media Type",
+				Detail: fmt.Sprintf("unsupported charset %q, use utf-8", charset),
+			},
+		})
+	}
+
+	return mt, nil
+}
+
+// bodyError maps errors reading the body to 413 Request Entity Too Large,
+// if the body exceeds its limit, otherwise to 400 Bad Request.
+func bodyError(ctx context.Context, msg string, err error) error {
+	slog.DebugContext(ctx, msg, "error", err)
+
+	if errors.IsBodyTooLarge(err) {
+		return errors.NewHTTPError(http.StatusRequestEntityTooLarge, &errors.REQUEST_ENTITY_TOO_LARGE_ERROR)
+	}
+
+	return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+}
+
+type strictJSONKey struct{}
+
+// WithStrictJSON returns a context, which makes JSON request bodies reject
+// unknown fields, if strict is set.
+func WithStrictJSON(ctx context.Context, strict bool) context.Context {
+	return context.WithValue(ctx, strictJSONKey{}, strict)
+}
+
+// parseJsonEncodedBody decodes a single JSON value into d, rejecting trailing
+// content, and unknown fields as well, if the request context is strict.
 func parseJsonEncodedBody(r *http.Request, d interface{}) (err error) {
-	if r.Header.Get("Content-Type") != "application/json" {
+	if mt, err := mediaType(r); err != nil {
+		return err
+	} else if mt != CONTENT_TYPE_JSON {
 		return errors.NewHTTPError(http.StatusUnsupportedMediaType, &errors.UNSUPPORTED_MEDIA_TYPE_ERROR)
 	}
 
 	decoder := json.NewDecoder(r.Body)
 
+	if strict, _ := r.Context().Value(strictJSONKey{}).(bool); strict {
+		decoder.DisallowUnknownFields()
+	}
+
 	if err := decoder.Decode(d); err != nil {
-		log.Println(err)
+		return bodyError(r.Context(), "failed to decode JSON body", err)
+	}
 
-		return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+	if err := decoder.Decode(&json.RawMessage{}); err != io.EOF {
+		if err == nil {
+			err = fmt.Errorf("multiple JSON values")
+		}
+
+		return bodyError(r.Context(), "unexpected content after JSON body", err)
 	}
 
 	return
 }
 
 func parseUrlEncodedBody(r *http.Request, d interface{}) (err error) {
-	if r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+	if mt, err := mediaType(r); err != nil {
+		return err
+	} else if mt != CONTENT_TYPE_FORM {
 		return errors.NewHTTPError(http.StatusUnsupportedMediaType, &errors.UNSUPPORTED_MEDIA_TYPE_ERROR)
 	}
 
 	if err := r.ParseForm(); err != nil {
-		log.Println(err)
-
-		return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
+		return bodyError(r.Context(), "failed to parse form", err)
 	}
 
 	if err := decoder.Decode(d, r.PostForm); err != nil {
-		log.Println(err)
+		slog.DebugContext(r.Context(), "failed to decode form", "error", err)
 
 		return errors.NewHTTPError(http.StatusBadRequest, &errors.BAD_REQUEST_ERROR)
 	}
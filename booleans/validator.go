@@ -3,15 +3,25 @@ package booleans
 import (
 	"fmt"
 	"log"
-	"time"
+	"log/slog"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/go-playground/validator/v10"
 )
 
 const (
-	EPOCH_GT_NOW = "epoch-gt-now"
+	EPOCH_GT_NOW     = "epoch-gt-now"
+	VALID_EXPRESSION = "boolean-expression"
+	VALID_ID         = "boolean-id"
+	VALID_LABEL      = "boolean-label"
 )
 
+// MAX_LABEL_LENGTH limits the length of labels in characters.
+const MAX_LABEL_LENGTH = 256
+
 var _customValidator *validator.Validate
 
 func NewCustomValidator() *validator.Validate {
@@ -19,9 +29,19 @@ func NewCustomValidator() *validator.Validate {
 		_customValidator = validator.New(validator.WithRequiredStructEnabled())
 
 		if err := _customValidator.RegisterValidation(EPOCH_GT_NOW, validateEpochGreaterNow); err != nil {
-			log.Println(err)
+			log.Fatalf("failed to register custom validator %s: %v", EPOCH_GT_NOW, err)
+		}
+
+		if err := _customValidator.RegisterValidation(VALID_EXPRESSION, validateBooleanExpression); err != nil {
+			log.Fatalf("failed to register custom validator %s: %v", VALID_EXPRESSION, err)
+		}
 
-			log.Fatalf("failed to register custom validator: %s", EPOCH_GT_NOW)
+		if err := _customValidator.RegisterValidation(VALID_ID, validateBooleanId); err != nil {
+			log.Fatalf("failed to register custom validator %s: %v", VALID_ID, err)
+		}
+
+		if err := _customValidator.RegisterValidation(VALID_LABEL, validateBooleanLabel); err != nil {
+			log.Fatalf("failed to register custom validator %s: %v", VALID_LABEL, err)
 		}
 	}
 
@@ -31,28 +51,69 @@ func NewCustomValidator() *validator.Validate {
 func validateEpochGreaterNow(fl validator.FieldLevel) bool {
 	var epoch int64
 
-	now := time.Now().Unix()
+	switch fl.Field().Kind() {
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		epoch = fl.Field().Int()
+	default:
+		return false
+	}
+
+	return epoch > now().Unix()
+}
+
+func validateBooleanExpression(fl validator.FieldLevel) bool {
+	var input string
 
 	switch t := fl.Field().Interface().(type) {
-	case int64:
-		epoch = t
+	case string:
+		input = t
 	default:
 		return false
 	}
 
-	return epoch > now
+	if _, err := parseExpression(input); err != nil {
+		slog.Debug("invalid expression", "error", err, "expression", input)
+
+		return false
+	}
+
+	return true
+}
+
+// validateBooleanId accepts IDs consisting of the base58 characters, which are
+// used by generated IDs.
+func validateBooleanId(fl validator.FieldLevel) bool {
+	input, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	return strings.IndexFunc(input, func(r rune) bool {
+		return !strings.ContainsRune(base58, r)
+	}) == -1
+}
+
+// validateBooleanLabel accepts valid UTF-8 of up to MAX_LABEL_LENGTH
+// characters without control characters, such as line breaks.
+func validateBooleanLabel(fl validator.FieldLevel) bool {
+	input, ok := fl.Field().Interface().(string)
+	if !ok || !utf8.ValidString(input) || utf8.RuneCountInString(input) > MAX_LABEL_LENGTH {
+		return false
+	}
+
+	return strings.IndexFunc(input, unicode.IsControl) == -1
 }
 
 func CustomValidateStruct(s interface{}) (err error) {
 	if err = NewCustomValidator().Struct(s); err != nil {
 		if _, ok := err.(*validator.InvalidValidationError); ok {
-			log.Println(err)
+			slog.Error("failed to validate struct", "error", err)
 
 			return
 		}
 
 		for _, err := range err.(validator.ValidationErrors) {
-			log.Printf("[%s] %s = %v\n", err.StructField(), err.Tag(), err.Value())
+			slog.Debug("validation failed", "field", err.StructField(), "tag", err.Tag(), "value", err.Value())
 
 			return fmt.Errorf("[%s] invalid value \"%s\" for tag: %s", err.StructField(), err.Value(), err.Tag())
 		}
@@ -1,6 +1,7 @@
 package booleans
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -41,6 +42,62 @@ func TestCustomValidator(t *testing.T) {
 			}{"not an integer"},
 			wantErr: true,
 		},
+		{
+			name: "id is base58",
+			data: struct {
+				Val string `validate:"boolean-id"`
+			}{"3yQfRd9zXk"},
+			wantErr: false,
+		},
+		{
+			name: "id contains hash tag",
+			data: struct {
+				Val string `validate:"boolean-id"`
+			}{"{abc}"},
+			wantErr: true,
+		},
+		{
+			name: "id contains characters outside base58",
+			data: struct {
+				Val string `validate:"boolean-id"`
+			}{"abc/0l"},
+			wantErr: true,
+		},
+		{
+			name: "label is valid",
+			data: struct {
+				Val string `validate:"boolean-label"`
+			}{"Größe der Tür 🚪"},
+			wantErr: false,
+		},
+		{
+			name: "label of maximum length",
+			data: struct {
+				Val string `validate:"boolean-label"`
+			}{strings.Repeat("ä", MAX_LABEL_LENGTH)},
+			wantErr: false,
+		},
+		{
+			name: "label is too long",
+			data: struct {
+				Val string `validate:"boolean-label"`
+			}{strings.Repeat("a", MAX_LABEL_LENGTH+1)},
+			wantErr: true,
+		},
+		{
+			name: "label contains line break",
+			data: struct {
+				Val string `validate:"boolean-label"`
+			}{"first\nsecond"},
+			wantErr: true,
+		},
+		{
+			name: "label is invalid UTF-8",
+			data: struct {
+				Val string `validate:"boolean-label"`
+			}{"\xff"},
+			wantErr: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -10,6 +10,13 @@ export GOARCH="amd64"
 export GO111MODULE=on
 export CGO_ENABLED=0
 
+# Build information, shown at /version
+VERSION_PACKAGE="github.com/saschazar21/go-baas/version"
+VERSION=$(git describe --tags --always --dirty 2>/dev/null || echo "dev")
+COMMIT=$(git rev-parse HEAD 2>/dev/null || echo "unknown")
+BUILD_TIME=$(date -u +"%Y-%m-%dT%H:%M:%SZ")
+LDFLAGS="-s -w -X $VERSION_PACKAGE.Version=$VERSION -X $VERSION_PACKAGE.Commit=$COMMIT -X $VERSION_PACKAGE.BuildTime=$BUILD_TIME"
+
 echo "Building the project..."
 
 # Create the build directory
@@ -18,7 +25,7 @@ if [ ! -d $FUNCTIONS_DIR ]; then
 fi
 
 # Build the functions
-for v in $(ls -d $PWD/cmd/*); do
+for v in $(ls -d $PWD/cmd/v*); do
   for e in $(ls -d $v/*); do
     echo -ne "Building $e..."
     cd $e
@@ -27,7 +34,7 @@ for v in $(ls -d $PWD/cmd/*); do
     endpoint=$(basename $e)   # endpoint name
     version=$(basename $v)    # API version number
 
-    go build -o ../../../$FUNCTIONS_DIR/${version}_${endpoint} -ldflags="-s -w" main.go
+    go build -o ../../../$FUNCTIONS_DIR/${version}_${endpoint} -ldflags="$LDFLAGS" main.go
     
     echo " done"
     cd ..
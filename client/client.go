@@ -0,0 +1,525 @@
+// Package client implements a Go client for the go-baas API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saschazar21/go-baas/errors"
+)
+
+const (
+	DEFAULT_RETRIES = 3
+	DEFAULT_BACKOFF = 100 * time.Millisecond
+
+	FORMAT_NDJSON = "ndjson"
+	FORMAT_CSV    = "csv"
+
+	IMPORT_MODE_SKIP      = "skip"
+	IMPORT_MODE_OVERWRITE = "overwrite"
+	IMPORT_MODE_FAIL      = "fail"
+
+	EVALUATION_REASON_DISABLED = "disabled"
+	EVALUATION_REASON_DEFAULT  = "default"
+	EVALUATION_REASON_DENIED   = "denied"
+	EVALUATION_REASON_ALLOWED  = "allowed"
+	EVALUATION_REASON_ROLLOUT  = "rollout"
+)
+
+// ContentTypes maps the transfer formats to their media type.
+var ContentTypes = map[string]string{
+	FORMAT_NDJSON: "application/x-ndjson",
+	FORMAT_CSV:    "text/csv",
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces the http.DefaultClient used for API requests.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.client = client
+	}
+}
+
+// WithAPIKey authenticates requests using the given API key as bearer token.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithRetries sets how often requests are retried on server errors, 0
+// disables retries. Only GET, PUT and DELETE requests, as well as creates,
+// which carry an Idempotency-Key, are retried.
+func WithRetries(retries int) Option {
+	return func(c *Client) {
+		c.retries = retries
+	}
+}
+
+// WithBackoff sets the delay before the first retry, which doubles for every
+// subsequent retry.
+func WithBackoff(backoff time.Duration) Option {
+	return func(c *Client) {
+		c.backoff = backoff
+	}
+}
+
+// ParamOption sets a query parameter of the boolean, e.g. its expiry.
+type ParamOption func(url.Values)
+
+// ExpiresIn deletes the boolean after the given duration, rounded up to
+// seconds, as the API does not accept an expiry of 0.
+func ExpiresIn(d time.Duration) ParamOption {
+	seconds := int64(d / time.Second)
+	if d%time.Second > 0 {
+		seconds++
+	}
+
+	return func(v url.Values) {
+		v.Set("expires_in", strconv.FormatInt(seconds, 10))
+	}
+}
+
+// ExpiresAt deletes the boolean at the given time, rounded down to seconds.
+// It takes precedence over ExpiresIn.
+func ExpiresAt(t time.Time) ParamOption {
+	return func(v url.Values) {
+		v.Set("expires_at", strconv.FormatInt(t.Unix(), 10))
+	}
+}
+
+// Rollout restricts a boolean to a percentage of subjects, with explicit
+// allow and deny lists.
+type Rollout struct {
+	Percentage float64  `json:"percentage"`
+	Allow      []string `json:"allow,omitempty"`
+	Deny       []string `json:"deny,omitempty"`
+}
+
+type Boolean struct {
+	Id             string   `json:"id,omitempty"`
+	Label          string   `json:"label,omitempty"`
+	Value          bool     `json:"value"`
+	Expression     string   `json:"expression,omitempty"`
+	Rollout        *Rollout `json:"rollout,omitempty"`
+	CreatedAt      int64    `json:"created_at,omitempty"`
+	UpdatedAt      int64    `json:"updated_at,omitempty"`
+	LeaseExpiresAt int64    `json:"lease_expires_at,omitempty"`
+}
+
+// Evaluation is the value of a boolean for a subject, with the reason, one
+// of the EVALUATION_REASON_* constants.
+type Evaluation struct {
+	Id      string `json:"id"`
+	Subject string `json:"subject"`
+	Value   bool   `json:"value"`
+	Reason  string `json:"reason"`
+}
+
+// ImportResult counts the imported, skipped and already expired records.
+type ImportResult struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+	Expired  int `json:"expired"`
+}
+
+// booleanBody is the request body for creating and updating booleans, which
+// omits the read-only fields of Boolean.
+type booleanBody struct {
+	Label      string   `json:"label,omitempty"`
+	Value      bool     `json:"value"`
+	Expression string   `json:"expression,omitempty"`
+	Rollout    *Rollout `json:"rollout,omitempty"`
+}
+
+type Client struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+	retries int
+	backoff time.Duration
+}
+
+// New creates a client for the go-baas API at baseURL, e.g.
+// https://go-baas.netlify.app.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  http.DefaultClient,
+		retries: DEFAULT_RETRIES,
+		backoff: DEFAULT_BACKOFF,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Create creates a new boolean. All attempts carry the same generated
+// Idempotency-Key, so that a retry does not create a duplicate.
+func (c *Client) Create(ctx context.Context, b *Boolean, opts ...ParamOption) (*Boolean, error) {
+	res := new(Boolean)
+
+	req, err := newRequest(http.MethodPost, "/api/v1/booleans", params(opts), toBody(b), &response{Data: res})
+	if err != nil {
+		return nil, err
+	}
+
+	if req.idempotencyKey, err = generateIdempotencyKey(); err != nil {
+		return nil, err
+	}
+
+	if err = c.execute(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// List retrieves all booleans.
+func (c *Client) List(ctx context.Context) ([]*Boolean, error) {
+	bools := []*Boolean{}
+	cursor := "0"
+
+	for {
+		var page []*Boolean
+
+		res := &response{Data: &page}
+
+		if err := c.do(ctx, http.MethodGet, "/api/v1/booleans", url.Values{"cursor": {cursor}}, nil, res); err != nil {
+			return nil, err
+		}
+
+		bools = append(bools, page...)
+
+		if cursor = res.Cursor; cursor == "" || cursor == "0" {
+			return bools, nil
+		}
+	}
+}
+
+// Export streams all booleans in the given format, FORMAT_NDJSON or FORMAT_CSV,
+// to w.
+func (c *Client) Export(ctx context.Context, format string, w io.Writer) error {
+	return c.execute(ctx, &request{
+		method: http.MethodGet,
+		path:   "/api/v1/export",
+		query:  url.Values{"format": {format}},
+		handle: func(body io.Reader) error {
+			_, err := io.Copy(w, body)
+
+			return err
+		},
+	})
+}
+
+// Import imports an export of the given format from r. Existing IDs are
+// handled according to mode, one of IMPORT_MODE_SKIP,
+// IMPORT_MODE_OVERWRITE or IMPORT_MODE_FAIL.
+func (c *Client) Import(ctx context.Context, format, mode string, r io.Reader) (*ImportResult, error) {
+	payload, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	res := new(ImportResult)
+
+	req := &request{
+		method:      http.MethodPost,
+		path:        "/api/v1/import",
+		query:       url.Values{"mode": {mode}},
+		contentType: ContentTypes[format],
+		payload:     payload,
+		handle: func(body io.Reader) error {
+			return json.NewDecoder(body).Decode(&response{Data: res})
+		},
+	}
+
+	if err = c.execute(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Get retrieves the boolean with the given ID.
+func (c *Client) Get(ctx context.Context, id string) (*Boolean, error) {
+	res := new(Boolean)
+
+	if err := c.do(ctx, http.MethodGet, booleanPath(id), nil, nil, &response{Data: res}); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Update replaces label, value, expression and rollout of the boolean with
+// the given ID.
+func (c *Client) Update(ctx context.Context, id string, b *Boolean, opts ...ParamOption) (*Boolean, error) {
+	res := new(Boolean)
+
+	if err := c.do(ctx, http.MethodPut, booleanPath(id), params(opts), toBody(b), &response{Data: res}); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Toggle inverts the value of the boolean with the given ID.
+func (c *Client) Toggle(ctx context.Context, id string) (*Boolean, error) {
+	res := new(Boolean)
+
+	if err := c.do(ctx, http.MethodPatch, booleanPath(id), nil, nil, &response{Data: res}); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Delete deletes the boolean with the given ID.
+func (c *Client) Delete(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, booleanPath(id), nil, nil, nil)
+}
+
+// Evaluate resolves the boolean with the given ID for subject, applying its
+// rollout.
+func (c *Client) Evaluate(ctx context.Context, id, subject string) (*Evaluation, error) {
+	res := new(Evaluation)
+
+	if err := c.do(ctx, http.MethodGet, booleanPath(id)+"/evaluate", url.Values{"subject": {subject}}, nil, &response{Data: res}); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func booleanPath(id string) string {
+	return "/api/v1/booleans/" + url.PathEscape(id)
+}
+
+func params(opts []ParamOption) url.Values {
+	values := url.Values{}
+
+	for _, opt := range opts {
+		opt(values)
+	}
+
+	return values
+}
+
+func toBody(b *Boolean) *booleanBody {
+	if b == nil {
+		return &booleanBody{}
+	}
+
+	return &booleanBody{
+		Label:      b.Label,
+		Value:      b.Value,
+		Expression: b.Expression,
+		Rollout:    b.Rollout,
+	}
+}
+
+// response is the envelope of API responses.
+type response struct {
+	Data   interface{} `json:"data"`
+	Cursor string      `json:"cursor,omitempty"`
+}
+
+// request describes an API request, whose successful response is passed to
+// handle.
+type request struct {
+	method         string
+	path           string
+	query          url.Values
+	contentType    string
+	payload        []byte
+	idempotencyKey string
+	handle         func(body io.Reader) error
+}
+
+// retryable reports whether the request may be sent again after a failure,
+// as it is idempotent by its method or by its Idempotency-Key. Otherwise, a
+// retry could apply a request twice, whose response was lost, e.g. a toggle.
+func (r *request) retryable() bool {
+	switch r.method {
+	case http.MethodGet, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	return r.idempotencyKey != ""
+}
+
+func generateIdempotencyKey() (string, error) {
+	data := make([]byte, 16)
+
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(data), nil
+}
+
+// do sends the JSON encoded body and decodes the response into res.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body interface{}, res *response) error {
+	req, err := newRequest(method, path, query, body, res)
+	if err != nil {
+		return err
+	}
+
+	return c.execute(ctx, req)
+}
+
+// newRequest creates a request with the JSON encoded body, decoding the
+// response into res.
+func newRequest(method, path string, query url.Values, body interface{}, res *response) (req *request, err error) {
+	req = &request{
+		method: method,
+		path:   path,
+		query:  query,
+	}
+
+	if body != nil {
+		req.contentType = "application/json"
+
+		if req.payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	if res != nil {
+		req.handle = func(body io.Reader) error {
+			if err := json.NewDecoder(body).Decode(res); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+
+			return nil
+		}
+	}
+
+	return
+}
+
+// execute sends the request. Network errors and server errors of retryable
+// requests are retried with exponential backoff.
+func (c *Client) execute(ctx context.Context, req *request) (err error) {
+	backoff := c.backoff
+
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		if retry, err = c.send(ctx, req); !retry || !req.retryable() || attempt >= c.retries {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+func (c *Client) send(ctx context.Context, req *request) (retry bool, err error) {
+	endpoint := c.baseURL + req.path
+
+	if len(req.query) > 0 {
+		endpoint += "?" + req.query.Encode()
+	}
+
+	var reader io.Reader
+	if req.payload != nil {
+		reader = bytes.NewReader(req.payload)
+	}
+
+	r, err := http.NewRequestWithContext(ctx, req.method, endpoint, reader)
+	if err != nil {
+		return false, err
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	if c.apiKey != "" {
+		r.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	if req.contentType != "" {
+		r.Header.Set("Content-Type", req.contentType)
+	}
+
+	if req.idempotencyKey != "" {
+		r.Header.Set("Idempotency-Key", req.idempotencyKey)
+	}
+
+	res, err := c.client.Do(r)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return res.StatusCode >= http.StatusInternalServerError, decodeError(res)
+	}
+
+	if req.handle == nil || res.StatusCode == http.StatusNoContent {
+		return false, nil
+	}
+
+	return false, req.handle(res.Body)
+}
+
+// decodeError decodes the errors.HTTPError envelope of the response. The
+// status code of the response is used, if the body contains no errors.
+func decodeError(r *http.Response) error {
+	httpErr := &errors.HTTPError{}
+
+	if err := json.NewDecoder(r.Body).Decode(httpErr); err != nil || httpErr.Errors == nil || len(*httpErr.Errors) == 0 {
+		httpErr.Errors = &[]errors.ErrorContent{
+			{
+				Status: r.StatusCode,
+				Title:  http.StatusText(r.StatusCode),
+			},
+		}
+	}
+
+	httpErr.Status = r.StatusCode
+	httpErr.Header = &http.Header{}
+
+	return httpErr
+}
+
+// StatusCode returns the HTTP status of errors returned by the client, or 0
+// for any other error.
+func StatusCode(err error) int {
+	if httpErr, ok := err.(*errors.HTTPError); ok {
+		return httpErr.Status
+	}
+
+	return 0
+}
+
+// IsNotFound reports whether the boolean does not exist.
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsConflict reports whether the boolean is computed or leased and thus
+// cannot be modified.
+func IsConflict(err error) bool {
+	return StatusCode(err) == http.StatusConflict
+}
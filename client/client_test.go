@@ -0,0 +1,276 @@
+package client_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/client"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/errors"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	c := test.Config(t)
+
+	return httptest.NewServer(v1.NewRouter(c, db.NewPool(c)))
+}
+
+func TestClient(t *testing.T) {
+	var container *redis.RedisContainer
+	var err error
+	var server *httptest.Server
+
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		server.Close()
+		test.TerminateContainer(container, t)
+	})
+
+	if container, err = test.CreateContainer(ctx, t); err != nil {
+		t.Fatal(err)
+	}
+
+	server = newServer(t)
+
+	c := client.New(server.URL, client.WithRetries(0))
+
+	created, err := c.Create(ctx, &client.Boolean{Label: "deploy freeze", Value: true}, client.ExpiresIn(time.Hour))
+	if err != nil {
+		t.Fatalf("Client.Create() error = %v", err)
+	}
+
+	assert.NotEmpty(t, created.Id)
+	assert.Equal(t, "deploy freeze", created.Label)
+	assert.True(t, created.Value)
+
+	t.Run("gets a boolean", func(t *testing.T) {
+		got, err := c.Get(ctx, created.Id)
+		if err != nil {
+			t.Fatalf("Client.Get() error = %v", err)
+		}
+
+		assert.Equal(t, created, got)
+	})
+
+	t.Run("lists booleans", func(t *testing.T) {
+		got, err := c.List(ctx)
+		if err != nil {
+			t.Fatalf("Client.List() error = %v", err)
+		}
+
+		assert.Equal(t, []*client.Boolean{created}, got)
+	})
+
+	t.Run("updates a boolean", func(t *testing.T) {
+		got, err := c.Update(ctx, created.Id, &client.Boolean{
+			Label:   "new checkout",
+			Value:   true,
+			Rollout: &client.Rollout{Allow: []string{"user-42"}},
+		}, client.ExpiresAt(time.Now().Add(time.Hour)))
+		if err != nil {
+			t.Fatalf("Client.Update() error = %v", err)
+		}
+
+		assert.Equal(t, "new checkout", got.Label)
+		assert.Equal(t, []string{"user-42"}, got.Rollout.Allow)
+	})
+
+	t.Run("evaluates a boolean", func(t *testing.T) {
+		got, err := c.Evaluate(ctx, created.Id, "user-42")
+		if err != nil {
+			t.Fatalf("Client.Evaluate() error = %v", err)
+		}
+
+		assert.True(t, got.Value)
+		assert.Equal(t, client.EVALUATION_REASON_ALLOWED, got.Reason)
+	})
+
+	t.Run("toggles a boolean", func(t *testing.T) {
+		got, err := c.Toggle(ctx, created.Id)
+		if err != nil {
+			t.Fatalf("Client.Toggle() error = %v", err)
+		}
+
+		assert.False(t, got.Value)
+	})
+
+	t.Run("deletes a boolean", func(t *testing.T) {
+		if err := c.Delete(ctx, created.Id); err != nil {
+			t.Fatalf("Client.Delete() error = %v", err)
+		}
+
+		_, err := c.Get(ctx, created.Id)
+
+		assert.True(t, client.IsNotFound(err))
+	})
+
+	t.Run("decodes API errors", func(t *testing.T) {
+		_, err := c.Create(ctx, &client.Boolean{Expression: "inexistent AND"})
+
+		httpErr, ok := err.(*errors.HTTPError)
+		if !ok {
+			t.Fatalf("Client.Create() error = %v, want *errors.HTTPError", err)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, httpErr.Status)
+		assert.Equal(t, "Bad Request", (*httpErr.Errors)[0].Title)
+	})
+}
+
+func TestClientRetries(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		status   int
+		failures int32
+		wantErr  bool
+		want     int32
+	}{
+		{
+			name:     "retries server errors",
+			status:   http.StatusServiceUnavailable,
+			failures: 2,
+			wantErr:  false,
+			want:     3,
+		},
+		{
+			name:     "gives up after the configured retries",
+			status:   http.StatusInternalServerError,
+			failures: 10,
+			wantErr:  true,
+			want:     4,
+		},
+		{
+			name:     "does not retry client errors",
+			status:   http.StatusNotFound,
+			failures: 10,
+			wantErr:  true,
+			want:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if attempts.Add(1) <= tt.failures {
+					httpErr := errors.NewHTTPError(tt.status, &[]errors.ErrorContent{{Status: tt.status, Title: http.StatusText(tt.status)}})
+					httpErr.Write(w)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"data":{"id":"test","value":true}}`))
+			}))
+
+			t.Cleanup(server.Close)
+
+			c := client.New(server.URL, client.WithRetries(3), client.WithBackoff(time.Millisecond))
+
+			got, err := c.Get(ctx, "test")
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Client.Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				assert.Equal(t, tt.status, client.StatusCode(err))
+			} else {
+				assert.True(t, got.Value)
+			}
+
+			assert.Equal(t, tt.want, attempts.Load())
+		})
+	}
+}
+
+func TestClientRetriesIdempotentRequests(t *testing.T) {
+	ctx := context.Background()
+
+	var attempts atomic.Int32
+	keys := make(chan string, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		keys <- r.Header.Get("Idempotency-Key")
+
+		httpErr := errors.NewHTTPError(http.StatusServiceUnavailable, &[]errors.ErrorContent{{Status: http.StatusServiceUnavailable}})
+		httpErr.Write(w)
+	}))
+
+	t.Cleanup(server.Close)
+
+	c := client.New(server.URL, client.WithRetries(2), client.WithBackoff(time.Millisecond))
+
+	t.Run("does not retry toggles", func(t *testing.T) {
+		attempts.Store(0)
+
+		_, err := c.Toggle(ctx, "test")
+
+		assert.Equal(t, http.StatusServiceUnavailable, client.StatusCode(err))
+		assert.Equal(t, int32(1), attempts.Load())
+		assert.Empty(t, <-keys)
+	})
+
+	t.Run("retries creates with the same idempotency key", func(t *testing.T) {
+		attempts.Store(0)
+
+		_, err := c.Create(ctx, &client.Boolean{Value: true})
+
+		assert.Equal(t, http.StatusServiceUnavailable, client.StatusCode(err))
+		assert.Equal(t, int32(3), attempts.Load())
+
+		key := <-keys
+		assert.NotEmpty(t, key)
+		assert.Equal(t, key, <-keys)
+		assert.Equal(t, key, <-keys)
+	})
+}
+
+func TestExpiresIn(t *testing.T) {
+	ctx := context.Background()
+
+	queries := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries <- r.URL.Query().Get("expires_in")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"id":"test","value":true}}`))
+	}))
+
+	t.Cleanup(server.Close)
+
+	c := client.New(server.URL)
+
+	tests := []struct {
+		name string
+		d    time.Duration
+		want string
+	}{
+		{"keeps whole seconds", time.Minute, "60"},
+		{"rounds up fractions", 1500 * time.Millisecond, "2"},
+		{"rounds up sub-second durations", 200 * time.Millisecond, "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.Update(ctx, "test", &client.Boolean{Value: true}, client.ExpiresIn(tt.d)); err != nil {
+				t.Fatalf("Client.Update() error = %v", err)
+			}
+
+			assert.Equal(t, tt.want, <-queries)
+		})
+	}
+}
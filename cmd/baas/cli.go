@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/saschazar21/go-baas/client"
+)
+
+// cli holds the state shared by all commands.
+type cli struct {
+	name   string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	configPath string
+	url        string
+	output     string
+
+	client *client.Client
+}
+
+// flagSet creates the flag set of the command, including the common flags.
+func (c *cli) flagSet(usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+
+	fs.StringVar(&c.configPath, "config", "", "path of the JSON config file (env BAAS_CONFIG)")
+	fs.StringVar(&c.url, "url", "", "base URL of the API (env BAAS_URL)")
+	fs.StringVar(&c.output, "o", OUTPUT_HUMAN, "output format: human, json or bare")
+
+	fs.Usage = func() {
+		fmt.Fprintf(c.stderr, "Usage: baas %s %s\n\nFlags:\n", c.name, usage)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parse parses the arguments of the command, ensures the expected amount of
+// positional arguments and sets up the API client.
+func (c *cli) parse(fs *flag.FlagSet, args []string, min, max int) (err error) {
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	if fs.NArg() < min || fs.NArg() > max {
+		fs.Usage()
+
+		return errors.New("invalid amount of arguments")
+	}
+
+	if !validOutput(c.output) {
+		return fmt.Errorf("invalid output format %q", c.output)
+	}
+
+	var conf *config
+	if conf, err = loadConfig(c.configPath); err != nil {
+		return
+	}
+
+	if c.url != "" {
+		conf.URL = c.url
+	}
+
+	if conf.URL == "" {
+		return fmt.Errorf("no API URL configured, use -url, the %s env or the config file", BAAS_URL_ENV)
+	}
+
+	opts := []client.Option{}
+	if conf.APIKey != "" {
+		opts = append(opts, client.WithAPIKey(conf.APIKey))
+	}
+
+	c.client = client.New(conf.URL, opts...)
+
+	return
+}
+
+// fail prints the error and returns the error exit status. Help requests
+// were already answered by the flag set.
+func (c *cli) fail(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return EXIT_TRUE
+	}
+
+	fmt.Fprintf(c.stderr, "baas %s: %v\n", c.name, err)
+
+	return EXIT_ERROR
+}
+
+// result prints the boolean and returns the exit status for its value.
+func (c *cli) result(b *client.Boolean) int {
+	if err := printBoolean(c.stdout, c.output, b); err != nil {
+		return c.fail(err)
+	}
+
+	return exitCode(b.Value)
+}
+
+func exitCode(value bool) int {
+	if value {
+		return EXIT_TRUE
+	}
+
+	return EXIT_FALSE
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saschazar21/go-baas/client"
+)
+
+const DEFAULT_POLL_INTERVAL = 2 * time.Second
+
+func parseValue(arg string) (bool, error) {
+	value, err := strconv.ParseBool(arg)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean value %q", arg)
+	}
+
+	return value, nil
+}
+
+func runCreate(ctx context.Context, c *cli, args []string) int {
+	fs := c.flagSet("[flags] [value]")
+
+	label := fs.String("label", "", "optional label of the boolean")
+	expiresIn := fs.Duration("expires-in", 0, "delete the boolean after the given duration, e.g. 1h")
+	expiresAt := fs.String("expires-at", "", "delete the boolean at the given RFC 3339 time")
+
+	if err := c.parse(fs, args, 0, 1); err != nil {
+		return c.fail(err)
+	}
+
+	b := &client.Boolean{Label: *label}
+
+	if fs.NArg() > 0 {
+		var err error
+		if b.Value, err = parseValue(fs.Arg(0)); err != nil {
+			return c.fail(err)
+		}
+	}
+
+	opts := []client.ParamOption{}
+
+	if *expiresIn > 0 {
+		opts = append(opts, client.ExpiresIn(*expiresIn))
+	}
+
+	if *expiresAt != "" {
+		t, err := time.Parse(time.RFC3339, *expiresAt)
+		if err != nil {
+			return c.fail(fmt.Errorf("invalid expiry time %q", *expiresAt))
+		}
+
+		opts = append(opts, client.ExpiresAt(t))
+	}
+
+	created, err := c.client.Create(ctx, b, opts...)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	return c.result(created)
+}
+
+func runGet(ctx context.Context, c *cli, args []string) int {
+	fs := c.flagSet("[flags] <id>")
+
+	if err := c.parse(fs, args, 1, 1); err != nil {
+		return c.fail(err)
+	}
+
+	b, err := c.client.Get(ctx, fs.Arg(0))
+	if err != nil {
+		return c.fail(err)
+	}
+
+	return c.result(b)
+}
+
+func runSet(ctx context.Context, c *cli, args []string) int {
+	fs := c.flagSet("[flags] <id> <value>")
+
+	if err := c.parse(fs, args, 2, 2); err != nil {
+		return c.fail(err)
+	}
+
+	value, err := parseValue(fs.Arg(1))
+	if err != nil {
+		return c.fail(err)
+	}
+
+	b, err := c.client.Get(ctx, fs.Arg(0))
+	if err != nil {
+		return c.fail(err)
+	}
+
+	b.Value = value
+
+	if b, err = c.client.Update(ctx, fs.Arg(0), b); err != nil {
+		return c.fail(err)
+	}
+
+	return c.result(b)
+}
+
+func runToggle(ctx context.Context, c *cli, args []string) int {
+	fs := c.flagSet("[flags] <id>")
+
+	if err := c.parse(fs, args, 1, 1); err != nil {
+		return c.fail(err)
+	}
+
+	b, err := c.client.Toggle(ctx, fs.Arg(0))
+	if err != nil {
+		return c.fail(err)
+	}
+
+	return c.result(b)
+}
+
+func runDelete(ctx context.Context, c *cli, args []string) int {
+	fs := c.flagSet("[flags] <id>")
+
+	if err := c.parse(fs, args, 1, 1); err != nil {
+		return c.fail(err)
+	}
+
+	if err := c.client.Delete(ctx, fs.Arg(0)); err != nil {
+		return c.fail(err)
+	}
+
+	return EXIT_TRUE
+}
+
+func runList(ctx context.Context, c *cli, args []string) int {
+	fs := c.flagSet("[flags]")
+
+	if err := c.parse(fs, args, 0, 0); err != nil {
+		return c.fail(err)
+	}
+
+	bools, err := c.client.List(ctx)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	if err = printBooleans(c.stdout, c.output, bools); err != nil {
+		return c.fail(err)
+	}
+
+	return EXIT_TRUE
+}
+
+// runWait polls the boolean until it has the desired value. It exits with
+// status 1, if the timeout elapses beforehand.
+func runWait(ctx context.Context, c *cli, args []string) int {
+	fs := c.flagSet("[flags] <id>")
+
+	want := fs.Bool("for", true, "the value to wait for")
+	interval := fs.Duration("interval", DEFAULT_POLL_INTERVAL, "polling interval")
+	timeout := fs.Duration("timeout", 0, "give up after the given duration, 0 waits forever")
+
+	if err := c.parse(fs, args, 1, 1); err != nil {
+		return c.fail(err)
+	}
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		b, err := c.client.Get(ctx, fs.Arg(0))
+
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return EXIT_FALSE
+		case err != nil:
+			return c.fail(err)
+		case b.Value == *want:
+			if err = printBoolean(c.stdout, c.output, b); err != nil {
+				return c.fail(err)
+			}
+
+			return EXIT_TRUE
+		}
+
+		select {
+		case <-ctx.Done():
+			return EXIT_FALSE
+		case <-ticker.C:
+		}
+	}
+}
+
+// runWatch polls the boolean and prints it initially and whenever its value
+// changes, until interrupted.
+func runWatch(ctx context.Context, c *cli, args []string) int {
+	fs := c.flagSet("[flags] <id>")
+
+	interval := fs.Duration("interval", DEFAULT_POLL_INTERVAL, "polling interval")
+
+	if err := c.parse(fs, args, 1, 1); err != nil {
+		return c.fail(err)
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	var last *client.Boolean
+
+	for {
+		b, err := c.client.Get(ctx, fs.Arg(0))
+
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return EXIT_TRUE
+		case err != nil:
+			return c.fail(err)
+		case last == nil || last.Value != b.Value:
+			if err = printBoolean(c.stdout, c.output, b); err != nil {
+				return c.fail(err)
+			}
+
+			last = b
+		}
+
+		select {
+		case <-ctx.Done():
+			return EXIT_TRUE
+		case <-ticker.C:
+		}
+	}
+}
+
+// runExport writes all booleans to stdout or the given file.
+func runExport(ctx context.Context, c *cli, args []string) int {
+	fs := c.flagSet("[flags]")
+
+	format := fs.String("format", client.FORMAT_NDJSON, "export format: ndjson or csv")
+	file := fs.String("f", "", "write the export to the given file instead of stdout")
+
+	if err := c.parse(fs, args, 0, 0); err != nil {
+		return c.fail(err)
+	}
+
+	var w io.Writer = c.stdout
+
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return c.fail(err)
+		}
+
+		defer f.Close()
+
+		w = f
+	}
+
+	if err := c.client.Export(ctx, *format, w); err != nil {
+		return c.fail(err)
+	}
+
+	return EXIT_TRUE
+}
+
+// runImport imports an export from the given file, or stdin if the file is
+// omitted or "-".
+func runImport(ctx context.Context, c *cli, args []string) int {
+	fs := c.flagSet("[flags] [file]")
+
+	format := fs.String("format", "", "import format: ndjson or csv, derived from the file extension by default")
+	mode := fs.String("mode", client.IMPORT_MODE_SKIP, "handling of existing IDs: skip, overwrite or fail")
+
+	if err := c.parse(fs, args, 0, 1); err != nil {
+		return c.fail(err)
+	}
+
+	var r io.Reader = c.stdin
+
+	if name := fs.Arg(0); name != "" && name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return c.fail(err)
+		}
+
+		defer f.Close()
+
+		r = f
+
+		if *format == "" {
+			*format = strings.TrimPrefix(filepath.Ext(name), ".")
+		}
+	}
+
+	if *format == "" {
+		*format = client.FORMAT_NDJSON
+	}
+
+	if _, ok := client.ContentTypes[*format]; !ok {
+		return c.fail(fmt.Errorf("invalid import format %q", *format))
+	}
+
+	result, err := c.client.Import(ctx, *format, *mode, r)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	if c.output == OUTPUT_JSON {
+		err = printJSON(c.stdout, result)
+	} else {
+		_, err = fmt.Fprintf(c.stdout, "imported: %d, skipped: %d, expired: %d\n", result.Imported, result.Skipped, result.Expired)
+	}
+
+	if err != nil {
+		return c.fail(err)
+	}
+
+	return EXIT_TRUE
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	BAAS_URL_ENV     = "BAAS_URL"
+	BAAS_API_KEY_ENV = "BAAS_API_KEY"
+	BAAS_CONFIG_ENV  = "BAAS_CONFIG"
+)
+
+type config struct {
+	URL    string `json:"url"`
+	APIKey string `json:"api_key"`
+}
+
+// defaultConfigPath returns the path of the config file, either from the
+// BAAS_CONFIG env or within the user config directory.
+func defaultConfigPath() string {
+	if path := os.Getenv(BAAS_CONFIG_ENV); path != "" {
+		return path
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "baas", "config.json")
+}
+
+// loadConfig reads the JSON config file at path, if present, and overrides
+// its settings using the BAAS_URL and BAAS_API_KEY env. A missing config file
+// is only an error, if its path was given explicitly.
+func loadConfig(path string) (c *config, err error) {
+	c = new(config)
+
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigPath()
+	}
+
+	if path != "" {
+		var data []byte
+		if data, err = os.ReadFile(path); err != nil {
+			if explicit || !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+		} else if err = json.Unmarshal(data, c); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if url := os.Getenv(BAAS_URL_ENV); url != "" {
+		c.URL = url
+	}
+
+	if key := os.Getenv(BAAS_API_KEY_ENV); key != "" {
+		c.APIKey = key
+	}
+
+	return c, nil
+}
@@ -0,0 +1,91 @@
+// Command baas is a command-line client for the go-baas API.
+//
+// The boolean commands exit with status 0, if the resulting boolean value is
+// true, and with status 1, if it is false, so that they can be used directly
+// in shell conditions:
+//
+//	if baas get deploy-freeze; then echo "frozen"; fi
+//
+// Any error results in exit status 2.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+const (
+	EXIT_TRUE  = 0
+	EXIT_FALSE = 1
+	EXIT_ERROR = 2
+)
+
+const usage = `Usage: baas <command> [flags] [arguments]
+
+Commands:
+  create [value]        create a new boolean, value defaults to false
+  get <id>              retrieve a boolean
+  set <id> <value>      set the value of a boolean, keeping its label
+  toggle <id>           toggle the value of a boolean
+  delete <id>           delete a boolean
+  ls                    list all booleans
+  wait <id>             wait until a boolean has the desired value
+  watch <id>            print a boolean whenever its value changes
+  export                export all booleans as NDJSON or CSV
+  import [file]         import an export from a file or stdin
+
+Every command accepts the following flags:
+  -config string        path of the JSON config file (env BAAS_CONFIG)
+  -url string           base URL of the API (env BAAS_URL)
+  -o string             output format: human, json or bare (default "human")
+
+Run "baas <command> -h" for the flags of a command.
+`
+
+type command func(ctx context.Context, cli *cli, args []string) int
+
+var commands = map[string]command{
+	"create": runCreate,
+	"get":    runGet,
+	"set":    runSet,
+	"toggle": runToggle,
+	"delete": runDelete,
+	"ls":     runList,
+	"wait":   runWait,
+	"watch":  runWatch,
+	"export": runExport,
+	"import": runImport,
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return EXIT_ERROR
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stdout, usage)
+		return EXIT_TRUE
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "baas: unknown command %q\n\n%s", args[0], usage)
+		return EXIT_ERROR
+	}
+
+	return cmd(ctx, &cli{name: args[0], stdin: stdin, stdout: stdout, stderr: stderr}, args[1:])
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+
+	stop()
+	os.Exit(code)
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/client"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	c := test.Config(t)
+
+	return httptest.NewServer(v1.NewRouter(c, db.NewPool(c)))
+}
+
+func execute(t *testing.T, args ...string) (code int, stdout string) {
+	var out, errOut bytes.Buffer
+
+	code = run(context.Background(), args, strings.NewReader(""), &out, &errOut)
+
+	if errOut.Len() > 0 {
+		t.Log(errOut.String())
+	}
+
+	return code, out.String()
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newServer(t)
+
+	t.Cleanup(func() {
+		server.Close()
+		test.TerminateContainer(container, t)
+	})
+
+	config := filepath.Join(t.TempDir(), "config.json")
+	if err = os.WriteFile(config, []byte(`{"url": "`+server.URL+`"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(BAAS_CONFIG_ENV, config)
+	t.Setenv(BAAS_URL_ENV, "")
+
+	code, out := execute(t, "create", "-o", "json", "-label", "deploy freeze", "-expires-in", "1h", "true")
+	assert.Equal(t, EXIT_TRUE, code)
+
+	var b client.Boolean
+	if err = json.Unmarshal([]byte(out), &b); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "deploy freeze", b.Label)
+
+	export := filepath.Join(t.TempDir(), "booleans.csv")
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+		out  string
+	}{
+		{
+			name: "get true boolean",
+			args: []string{"get", "-o", "bare", b.Id},
+			want: EXIT_TRUE,
+			out:  "true\n",
+		},
+		{
+			name: "set boolean to false",
+			args: []string{"set", b.Id, "false"},
+			want: EXIT_FALSE,
+			out:  "[" + b.Id + "]: false, Label: \"deploy freeze\"\n",
+		},
+		{
+			name: "get false boolean",
+			args: []string{"get", "-o", "bare", b.Id},
+			want: EXIT_FALSE,
+			out:  "false\n",
+		},
+		{
+			name: "wait for timeout",
+			args: []string{"wait", "-interval", "10ms", "-timeout", "50ms", b.Id},
+			want: EXIT_FALSE,
+		},
+		{
+			name: "wait for current value",
+			args: []string{"wait", "-for=false", "-o", "bare", b.Id},
+			want: EXIT_TRUE,
+			out:  "false\n",
+		},
+		{
+			name: "toggle boolean",
+			args: []string{"toggle", "-o", "bare", b.Id},
+			want: EXIT_TRUE,
+			out:  "true\n",
+		},
+		{
+			name: "list booleans",
+			args: []string{"ls", "-o", "bare"},
+			want: EXIT_TRUE,
+			out:  b.Id + " true\n",
+		},
+		{
+			name: "export booleans",
+			args: []string{"export", "-format", "csv", "-f", export},
+			want: EXIT_TRUE,
+		},
+		{
+			name: "delete boolean",
+			args: []string{"delete", b.Id},
+			want: EXIT_TRUE,
+		},
+		{
+			name: "get inexistent boolean",
+			args: []string{"get", b.Id},
+			want: EXIT_ERROR,
+		},
+		{
+			name: "import booleans",
+			args: []string{"import", "-mode", "fail", export},
+			want: EXIT_TRUE,
+			out:  "imported: 1, skipped: 0, expired: 0\n",
+		},
+		{
+			name: "get imported boolean",
+			args: []string{"get", "-o", "bare", b.Id},
+			want: EXIT_TRUE,
+			out:  "true\n",
+		},
+		{
+			name: "import existing booleans",
+			args: []string{"import", export},
+			want: EXIT_TRUE,
+			out:  "imported: 0, skipped: 1, expired: 0\n",
+		},
+		{
+			name: "invalid import format",
+			args: []string{"import", "-format", "xml", export},
+			want: EXIT_ERROR,
+		},
+		{
+			name: "invalid value",
+			args: []string{"create", "maybe"},
+			want: EXIT_ERROR,
+		},
+		{
+			name: "invalid output",
+			args: []string{"ls", "-o", "yaml"},
+			want: EXIT_ERROR,
+		},
+		{
+			name: "missing arguments",
+			args: []string{"get"},
+			want: EXIT_ERROR,
+		},
+		{
+			name: "unknown command",
+			args: []string{"flip"},
+			want: EXIT_ERROR,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, out := execute(t, tt.args...)
+
+			assert.Equal(t, tt.want, code)
+
+			if tt.out != "" {
+				assert.Equal(t, tt.out, out)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"url": "https://file.example", "api_key": "secret"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("reads the config file", func(t *testing.T) {
+		t.Setenv(BAAS_URL_ENV, "")
+		t.Setenv(BAAS_API_KEY_ENV, "")
+
+		c, err := loadConfig(path)
+		if err != nil {
+			t.Fatalf("loadConfig() error = %v", err)
+		}
+
+		assert.Equal(t, "https://file.example", c.URL)
+		assert.Equal(t, "secret", c.APIKey)
+	})
+
+	t.Run("overrides the config file by env", func(t *testing.T) {
+		t.Setenv(BAAS_URL_ENV, "https://env.example")
+		t.Setenv(BAAS_API_KEY_ENV, "")
+
+		c, err := loadConfig(path)
+		if err != nil {
+			t.Fatalf("loadConfig() error = %v", err)
+		}
+
+		assert.Equal(t, "https://env.example", c.URL)
+		assert.Equal(t, "secret", c.APIKey)
+	})
+
+	t.Run("ignores a missing default config file", func(t *testing.T) {
+		t.Setenv(BAAS_CONFIG_ENV, filepath.Join(t.TempDir(), "missing.json"))
+
+		if _, err := loadConfig(""); err != nil {
+			t.Errorf("loadConfig() error = %v", err)
+		}
+	})
+
+	t.Run("rejects a missing explicit config file", func(t *testing.T) {
+		if _, err := loadConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil || !strings.Contains(err.Error(), "missing.json") {
+			t.Errorf("loadConfig() error = %v, wantErr %v", err, true)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/saschazar21/go-baas/client"
+)
+
+const (
+	OUTPUT_HUMAN = "human"
+	OUTPUT_JSON  = "json"
+	OUTPUT_BARE  = "bare"
+)
+
+func validOutput(output string) bool {
+	switch output {
+	case OUTPUT_HUMAN, OUTPUT_JSON, OUTPUT_BARE:
+		return true
+	}
+
+	return false
+}
+
+func printBoolean(w io.Writer, output string, b *client.Boolean) error {
+	switch output {
+	case OUTPUT_JSON:
+		return printJSON(w, b)
+	case OUTPUT_BARE:
+		_, err := fmt.Fprintln(w, b.Value)
+		return err
+	default:
+		_, err := fmt.Fprintf(w, "[%s]: %t, Label: \"%s\"\n", b.Id, b.Value, b.Label)
+		return err
+	}
+}
+
+func printBooleans(w io.Writer, output string, bools []*client.Boolean) error {
+	if output == OUTPUT_JSON {
+		return printJSON(w, bools)
+	}
+
+	for _, b := range bools {
+		var err error
+
+		if output == OUTPUT_BARE {
+			_, err = fmt.Fprintf(w, "%s %t\n", b.Id, b.Value)
+		} else {
+			err = printBoolean(w, output, b)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/saschazar21/go-baas/booleans"
+)
+
+type entry struct {
+	Id    string `json:"id"`
+	Label string `json:"label"`
+	Value bool   `json:"value"`
+	TTL   int64  `json:"ttl"`
+}
+
+type modification struct {
+	DryRun bool     `json:"dry_run"`
+	Ids    []string `json:"ids"`
+}
+
+type purge struct {
+	DryRun  bool              `json:"dry_run"`
+	Orphans []booleans.Orphan `json:"orphans"`
+}
+
+type migration struct {
+	DryRun bool     `json:"dry_run"`
+	Keys   []string `json:"keys"`
+}
+
+type verification struct {
+	Keys   int              `json:"keys"`
+	Issues []booleans.Issue `json:"issues"`
+}
+
+func (c *ctl) printJSON(v interface{}) error {
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+func (c *ctl) printModification(verb string, m *modification) error {
+	if c.output == OUTPUT_JSON {
+		return c.printJSON(m)
+	}
+
+	if m.DryRun {
+		verb = "would " + verb
+	}
+
+	for _, id := range m.Ids {
+		fmt.Fprintf(c.stdout, "%s %s\n", verb, id)
+	}
+
+	_, err := fmt.Fprintf(c.stdout, "%d boolean(s)\n", len(m.Ids))
+
+	return err
+}
+
+func runList(ctx context.Context, c *ctl, args []string) int {
+	fs := c.flagSet(false)
+
+	label := fs.String("label", "*", "label pattern, supporting * and ? as wildcards")
+
+	if err := c.parse(fs, args); err != nil {
+		return c.fail(err)
+	}
+
+	entries := []*entry{}
+
+	if err := booleans.ScanBooleans(c.client, ctx, *label, func(b *booleans.Boolean) (err error) {
+		e := &entry{Id: *b.Id, Label: b.Label, Value: b.Value}
+
+		e.TTL, err = booleans.TTL(c.client, ctx, e.Id)
+		entries = append(entries, e)
+
+		return
+	}); err != nil {
+		return c.fail(err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Id < entries[j].Id
+	})
+
+	if c.output == OUTPUT_JSON {
+		if err := c.printJSON(entries); err != nil {
+			return c.fail(err)
+		}
+
+		return EXIT_OK
+	}
+
+	for _, e := range entries {
+		fmt.Fprintf(c.stdout, "%s\t%t\t%d\t%s\n", e.Id, e.Value, e.TTL, e.Label)
+	}
+
+	return EXIT_OK
+}
+
+func runDump(ctx context.Context, c *ctl, args []string) int {
+	fs := c.flagSet(false)
+
+	if err := c.parse(fs, args); err != nil {
+		return c.fail(err)
+	}
+
+	records := []*booleans.Record{}
+
+	if err := booleans.ScanKeys(c.client, ctx, func(key string) (err error) {
+		var r *booleans.Record
+		if r, err = booleans.DumpKey(c.client, ctx, key); err != nil {
+			return
+		}
+
+		records = append(records, r)
+
+		return
+	}); err != nil {
+		return c.fail(err)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Key < records[j].Key
+	})
+
+	if c.output == OUTPUT_JSON {
+		if err := c.printJSON(records); err != nil {
+			return c.fail(err)
+		}
+
+		return EXIT_OK
+	}
+
+	for _, r := range records {
+		fmt.Fprintf(c.stdout, "%s (%s, ttl %d)\n", r.Key, r.Type, r.TTL)
+
+		fields := make([]string, 0, len(r.Fields))
+		for field := range r.Fields {
+			fields = append(fields, field)
+		}
+
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			fmt.Fprintf(c.stdout, "  %s = %s\n", field, r.Fields[field])
+		}
+
+		for _, value := range r.Values {
+			fmt.Fprintf(c.stdout, "  %s\n", value)
+		}
+	}
+
+	return EXIT_OK
+}
+
+// matchIds returns the IDs of all booleans, whose label matches the pattern.
+func matchIds(ctx context.Context, c *ctl, pattern string) (ids []string, err error) {
+	ids = []string{}
+
+	err = booleans.ScanBooleans(c.client, ctx, pattern, func(b *booleans.Boolean) error {
+		ids = append(ids, *b.Id)
+
+		return nil
+	})
+
+	sort.Strings(ids)
+
+	return
+}
+
+func runDelete(ctx context.Context, c *ctl, args []string) int {
+	fs := c.flagSet(true)
+
+	label := fs.String("label", "", "label pattern, supporting * and ? as wildcards (required)")
+
+	if err := c.parse(fs, args); err != nil {
+		return c.fail(err)
+	}
+
+	if *label == "" {
+		return c.fail(fmt.Errorf("missing -label pattern, use \"*\" to delete all booleans"))
+	}
+
+	ids, err := matchIds(ctx, c, *label)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	if !c.dryRun {
+		for _, id := range ids {
+			if err = booleans.DeleteBoolean(c.client, ctx, id); err != nil {
+				return c.fail(err)
+			}
+		}
+	}
+
+	if err = c.printModification("deleted", &modification{DryRun: c.dryRun, Ids: ids}); err != nil {
+		return c.fail(err)
+	}
+
+	return EXIT_OK
+}
+
+func runResetTTL(ctx context.Context, c *ctl, args []string) int {
+	fs := c.flagSet(true)
+
+	label := fs.String("label", "*", "label pattern, supporting * and ? as wildcards")
+	ttl := fs.Duration("ttl", 0, "the new TTL, e.g. 24h, 0 removes the expiry")
+
+	if err := c.parse(fs, args); err != nil {
+		return c.fail(err)
+	}
+
+	if *ttl < 0 || (*ttl > 0 && *ttl < time.Second) {
+		return c.fail(fmt.Errorf("invalid TTL %s", *ttl))
+	}
+
+	if c.maxTTL > 0 && (*ttl == 0 || *ttl > c.maxTTL) {
+		return c.fail(fmt.Errorf("TTL %s exceeds the maximum TTL of %s", *ttl, c.maxTTL))
+	}
+
+	ids, err := matchIds(ctx, c, *label)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	if !c.dryRun {
+		for _, id := range ids {
+			if err = booleans.ResetExpiry(c.client, ctx, id, *ttl, c.maxTTL); err != nil {
+				return c.fail(err)
+			}
+		}
+	}
+
+	if err = c.printModification("reset TTL of", &modification{DryRun: c.dryRun, Ids: ids}); err != nil {
+		return c.fail(err)
+	}
+
+	return EXIT_OK
+}
+
+func runPurgeOrphans(ctx context.Context, c *ctl, args []string) int {
+	fs := c.flagSet(true)
+
+	if err := c.parse(fs, args); err != nil {
+		return c.fail(err)
+	}
+
+	orphans, err := booleans.FindOrphans(c.client, ctx)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	if !c.dryRun {
+		for _, o := range orphans {
+			if err = booleans.PurgeOrphan(c.client, ctx, o); err != nil {
+				return c.fail(err)
+			}
+		}
+	}
+
+	p := &purge{DryRun: c.dryRun, Orphans: orphans}
+	if p.Orphans == nil {
+		p.Orphans = []booleans.Orphan{}
+	}
+
+	if c.output == OUTPUT_JSON {
+		if err = c.printJSON(p); err != nil {
+			return c.fail(err)
+		}
+
+		return EXIT_OK
+	}
+
+	verb := "purged"
+	if c.dryRun {
+		verb = "would purge"
+	}
+
+	for _, o := range orphans {
+		if o.Member != "" {
+			fmt.Fprintf(c.stdout, "%s %s from %s\n", verb, o.Member, o.Key)
+		} else {
+			fmt.Fprintf(c.stdout, "%s %s\n", verb, o.Key)
+		}
+	}
+
+	fmt.Fprintf(c.stdout, "%d orphan(s)\n", len(orphans))
+
+	return EXIT_OK
+}
+
+// runMigrateKeys moves legacy keys to the current key layout, or lists them
+// with --dry-run.
+func runMigrateKeys(ctx context.Context, c *ctl, args []string) int {
+	fs := c.flagSet(true)
+
+	if err := c.parse(fs, args); err != nil {
+		return c.fail(err)
+	}
+
+	m := &migration{DryRun: c.dryRun, Keys: []string{}}
+
+	if err := booleans.ScanKeys(c.client, ctx, func(key string) error {
+		if booleans.IsLegacyKey(key) {
+			m.Keys = append(m.Keys, key)
+		}
+
+		return nil
+	}); err != nil {
+		return c.fail(err)
+	}
+
+	sort.Strings(m.Keys)
+
+	if !c.dryRun {
+		for _, key := range m.Keys {
+			if err := booleans.MigrateKey(c.client, ctx, key); err != nil {
+				return c.fail(err)
+			}
+		}
+	}
+
+	if c.output == OUTPUT_JSON {
+		if err := c.printJSON(m); err != nil {
+			return c.fail(err)
+		}
+
+		return EXIT_OK
+	}
+
+	verb := "migrated"
+	if c.dryRun {
+		verb = "would migrate"
+	}
+
+	for _, key := range m.Keys {
+		fmt.Fprintf(c.stdout, "%s %s\n", verb, key)
+	}
+
+	fmt.Fprintf(c.stdout, "%d key(s)\n", len(m.Keys))
+
+	return EXIT_OK
+}
+
+// runVerify checks all keys and exits with status 1, if any issues were
+// found.
+func runVerify(ctx context.Context, c *ctl, args []string) int {
+	fs := c.flagSet(false)
+
+	if err := c.parse(fs, args); err != nil {
+		return c.fail(err)
+	}
+
+	v := &verification{Issues: []booleans.Issue{}}
+
+	if err := booleans.ScanKeys(c.client, ctx, func(key string) (err error) {
+		var issues []booleans.Issue
+		if issues, err = booleans.VerifyKey(c.client, ctx, key); err != nil {
+			return
+		}
+
+		v.Keys++
+		v.Issues = append(v.Issues, issues...)
+
+		return
+	}); err != nil {
+		return c.fail(err)
+	}
+
+	sort.SliceStable(v.Issues, func(i, j int) bool {
+		return v.Issues[i].Key < v.Issues[j].Key
+	})
+
+	if c.output == OUTPUT_JSON {
+		if err := c.printJSON(v); err != nil {
+			return c.fail(err)
+		}
+	} else {
+		for _, issue := range v.Issues {
+			if issue.Member != "" {
+				fmt.Fprintf(c.stdout, "%s [%s]: %s\n", issue.Key, issue.Member, issue.Problem)
+			} else {
+				fmt.Fprintf(c.stdout, "%s: %s\n", issue.Key, issue.Problem)
+			}
+		}
+
+		fmt.Fprintf(c.stdout, "%d key(s) verified, %d issue(s) found\n", v.Keys, len(v.Issues))
+	}
+
+	if len(v.Issues) > 0 {
+		return EXIT_ISSUES
+	}
+
+	return EXIT_OK
+}
@@ -0,0 +1,164 @@
+// Command baasctl operates on the Redis data of go-baas directly, bypassing
+// the HTTP API. It connects to the database given by the config, see the
+// config package, usually the REDIS_URL env.
+//
+// Modifying commands support the --dry-run flag, which only reports the
+// affected keys. Every command supports machine-readable output using -o json.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+)
+
+const (
+	EXIT_OK     = 0
+	EXIT_ISSUES = 1
+	EXIT_ERROR  = 2
+)
+
+const (
+	OUTPUT_TEXT = "text"
+	OUTPUT_JSON = "json"
+)
+
+const usage = `Usage: baasctl <command> [flags]
+
+Commands:
+  list            list booleans with their remaining TTL
+  dump            dump the raw content of all keys
+  delete          delete booleans by label pattern
+  purge-orphans   delete schedules referring to inexistent booleans
+  migrate-keys    move keys of earlier versions to the cluster-safe key layout
+  reset-ttl       set or remove the TTL of booleans by label pattern
+  verify          check all keys for integrity problems
+
+Every command accepts -o text|json, the database is set by the REDIS_URL env
+or the config file given by the BAAS_CONFIG_FILE env.
+Run "baasctl <command> -h" for the flags of a command.
+`
+
+type command func(ctx context.Context, c *ctl, args []string) int
+
+var commands = map[string]command{
+	"list":          runList,
+	"dump":          runDump,
+	"delete":        runDelete,
+	"purge-orphans": runPurgeOrphans,
+	"migrate-keys":  runMigrateKeys,
+	"reset-ttl":     runResetTTL,
+	"verify":        runVerify,
+}
+
+// ctl holds the state shared by all commands.
+type ctl struct {
+	name   string
+	stdout io.Writer
+	stderr io.Writer
+
+	output string
+	dryRun bool
+
+	client redis.UniversalClient
+	maxTTL time.Duration
+}
+
+// flagSet creates the flag set of the command, including the common flags.
+// Modifying commands additionally get the --dry-run flag.
+func (c *ctl) flagSet(modifying bool) *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+
+	fs.StringVar(&c.output, "o", OUTPUT_TEXT, "output format: text or json")
+
+	if modifying {
+		fs.BoolVar(&c.dryRun, "dry-run", false, "only report the affected keys")
+	}
+
+	return fs
+}
+
+// parse parses the flags of the command and connects to the database.
+func (c *ctl) parse(fs *flag.FlagSet, args []string) (err error) {
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	if fs.NArg() > 0 {
+		fs.Usage()
+
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+
+	if c.output != OUTPUT_TEXT && c.output != OUTPUT_JSON {
+		return fmt.Errorf("invalid output format %q", c.output)
+	}
+
+	var cfg *config.Config
+	if cfg, err = config.Load(); err != nil {
+		return
+	}
+
+	c.maxTTL = cfg.MaxTTL
+	c.client, err = db.NewRedis(cfg)
+
+	return
+}
+
+func (c *ctl) fail(err error) int {
+	if errors.Is(err, flag.ErrHelp) {
+		return EXIT_OK
+	}
+
+	fmt.Fprintf(c.stderr, "baasctl %s: %v\n", c.name, err)
+
+	return EXIT_ERROR
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return EXIT_ERROR
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Fprint(stdout, usage)
+		return EXIT_OK
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "baasctl: unknown command %q\n\n%s", args[0], usage)
+		return EXIT_ERROR
+	}
+
+	c := &ctl{name: args[0], stdout: stdout, stderr: stderr}
+
+	defer func() {
+		if c.client != nil {
+			c.client.Close()
+		}
+	}()
+
+	return cmd(ctx, c, args[1:])
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	code := run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+
+	stop()
+	os.Exit(code)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func execute(t *testing.T, args ...string) (code int, stdout []byte) {
+	var out, errOut bytes.Buffer
+
+	code = run(context.Background(), args, &out, &errOut)
+
+	if errOut.Len() > 0 {
+		t.Log(errOut.String())
+	}
+
+	return code, out.Bytes()
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	client, err := db.NewRedis(test.Config(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		client.Close()
+	})
+
+	ids := map[string]string{}
+
+	for _, label := range []string{"tmp one", "tmp two", "keep"} {
+		b := booleans.Boolean{Label: label, BooleanParams: &booleans.BooleanParams{ExpiresIn: 3600}}
+
+		if err = b.Save(client, ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		ids[label] = *b.Id
+	}
+
+	client.Set(ctx, "wrong-type", "1", 0)
+
+	t.Run("lists booleans", func(t *testing.T) {
+		code, out := execute(t, "list", "-o", "json", "-label", "keep")
+
+		assert.Equal(t, EXIT_OK, code)
+
+		var entries []entry
+		if err := json.Unmarshal(out, &entries); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Len(t, entries, 1)
+		assert.Equal(t, ids["keep"], entries[0].Id)
+		assert.InDelta(t, 3600, entries[0].TTL, 1)
+	})
+
+	t.Run("reports issues", func(t *testing.T) {
+		code, out := execute(t, "verify", "-o", "json")
+
+		assert.Equal(t, EXIT_ISSUES, code)
+
+		var v verification
+		if err := json.Unmarshal(out, &v); err != nil {
+			t.Fatal(err)
+		}
+
+		// the expiring booleans come with a shadow copy each
+		assert.Equal(t, 7, v.Keys)
+		assert.Len(t, v.Issues, 1)
+		assert.Equal(t, "wrong-type", v.Issues[0].Key)
+	})
+
+	t.Run("does not delete in dry-run mode", func(t *testing.T) {
+		code, out := execute(t, "delete", "-o", "json", "--dry-run", "-label", "tmp *")
+
+		assert.Equal(t, EXIT_OK, code)
+
+		var m modification
+		if err := json.Unmarshal(out, &m); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.True(t, m.DryRun)
+		assert.ElementsMatch(t, []string{ids["tmp one"], ids["tmp two"]}, m.Ids)
+		assert.Equal(t, int64(3), client.Exists(ctx, ids["tmp one"], ids["tmp two"], ids["keep"]).Val())
+	})
+
+	t.Run("deletes by label pattern", func(t *testing.T) {
+		code, _ := execute(t, "delete", "-label", "tmp *")
+
+		assert.Equal(t, EXIT_OK, code)
+		assert.Equal(t, int64(1), client.Exists(ctx, ids["tmp one"], ids["tmp two"], ids["keep"]).Val())
+	})
+
+	t.Run("removes TTLs", func(t *testing.T) {
+		code, _ := execute(t, "reset-ttl", "-label", "keep", "-ttl", "0")
+
+		assert.Equal(t, EXIT_OK, code)
+		assert.Equal(t, int64(-1), int64(client.TTL(ctx, ids["keep"]).Val()))
+	})
+
+	t.Run("limits TTLs to the maximum TTL", func(t *testing.T) {
+		t.Setenv(config.MAX_TTL_ENV, "1h")
+
+		for _, ttl := range []string{"0", "2h"} {
+			code, _ := execute(t, "reset-ttl", "-label", "keep", "-ttl", ttl)
+
+			assert.Equal(t, EXIT_ERROR, code)
+		}
+
+		assert.Equal(t, int64(-1), int64(client.TTL(ctx, ids["keep"]).Val()))
+	})
+
+	t.Run("migrates legacy keys", func(t *testing.T) {
+		legacy := "schedules:" + ids["keep"]
+
+		client.ZAdd(ctx, legacy, redis.Z{Score: 1, Member: "legacy-schedule"})
+
+		code, out := execute(t, "migrate-keys", "-o", "json", "--dry-run")
+
+		assert.Equal(t, EXIT_OK, code)
+
+		var m migration
+		if err := json.Unmarshal(out, &m); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, []string{legacy}, m.Keys)
+		assert.Equal(t, int64(1), client.Exists(ctx, legacy).Val())
+
+		code, _ = execute(t, "migrate-keys")
+
+		assert.Equal(t, EXIT_OK, code)
+		assert.Equal(t, int64(0), client.Exists(ctx, legacy).Val())
+		assert.Equal(t, []string{"legacy-schedule"}, client.ZRange(ctx, "schedules:{"+ids["keep"]+"}", 0, -1).Val())
+
+		client.Del(ctx, "schedules:{"+ids["keep"]+"}")
+	})
+
+	t.Run("requires a label pattern for deletion", func(t *testing.T) {
+		code, _ := execute(t, "delete")
+
+		assert.Equal(t, EXIT_ERROR, code)
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/logging"
+	"github.com/saschazar21/go-baas/tracing"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err = logging.Setup(cfg.LogLevel); err != nil {
+		log.Fatal(err)
+	}
+
+	tp, err := tracing.Setup(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if tp != nil {
+		defer tp.Shutdown(context.Background())
+	}
+
+	client, err := db.NewRedis(cfg)
+	if err != nil {
+		slog.Error("failed to create redis client", "error", err)
+		os.Exit(1)
+	}
+
+	defer client.Close()
+
+	if err = booleans.EnableExpiredNotifications(client, ctx); err != nil {
+		slog.WarnContext(ctx, "failed to enable keyspace notifications, make sure notify-keyspace-events includes Ex", "error", err)
+	}
+
+	slog.Info("reporting expired booleans", "channel", cfg.KeyPrefix+booleans.EXPIRED_EVENTS_CHANNEL)
+
+	if err = booleans.WatchExpired(client, ctx, cfg.KeyPrefix); err != nil {
+		slog.ErrorContext(ctx, "failed to watch expired booleans", "error", err)
+		os.Exit(1)
+	}
+}
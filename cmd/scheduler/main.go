@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/logging"
+	"github.com/saschazar21/go-baas/tracing"
+)
+
+func main() {
+	interval := flag.Duration("interval", 5*time.Second, "interval between checks for due schedules")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err = logging.Setup(cfg.LogLevel); err != nil {
+		log.Fatal(err)
+	}
+
+	tp, err := tracing.Setup(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if tp != nil {
+		defer tp.Shutdown(context.Background())
+	}
+
+	client, err := db.NewRedis(cfg)
+	if err != nil {
+		slog.Error("failed to create redis client", "error", err)
+		os.Exit(1)
+	}
+
+	defer client.Close()
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	slog.Info("running due schedules", "interval", interval.String())
+
+	for {
+		applied, err := booleans.RunDueSchedules(client, ctx, time.Now())
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to run due schedules", "error", err)
+		}
+
+		if applied > 0 {
+			slog.InfoContext(ctx, "applied scheduled operations", "applied", applied)
+		}
+
+		reverted, err := booleans.RevertExpiredLeases(client, ctx, time.Now())
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to revert expired leases", "error", err)
+		}
+
+		if reverted > 0 {
+			slog.InfoContext(ctx, "reverted expired leases", "reverted", reverted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/logging"
+	"github.com/saschazar21/go-baas/tracing"
+)
+
+// SHUTDOWN_TIMEOUT is the time granted to running requests on shutdown.
+const SHUTDOWN_TIMEOUT = 10 * time.Second
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err = logging.Setup(cfg.LogLevel); err != nil {
+		log.Fatal(err)
+	}
+
+	tp, err := tracing.Setup(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if tp != nil {
+		defer tp.Shutdown(context.Background())
+	}
+
+	pool := db.NewPool(cfg)
+	defer pool.Close()
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", v1.NewMetricsHandler(pool))
+	mux.Handle("/", v1.NewRouter(cfg, pool))
+
+	server := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), SHUTDOWN_TIMEOUT)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("failed to shut down server", "error", err)
+		}
+	}()
+
+	slog.Info("serving API", "addr", cfg.Addr)
+
+	if err = server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("failed to serve API", "error", err)
+		os.Exit(1)
+	}
+
+	<-done
+}
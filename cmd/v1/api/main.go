@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/saschazar21/go-baas/adapter"
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/logging"
+	"github.com/saschazar21/go-baas/metrics"
+	"github.com/saschazar21/go-baas/tracing"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// flushingHandler exports the spans and writes the metrics of every
+// invocation to stdout in the embedded metric format, which CloudWatch
+// extracts from the logs, before the instance is frozen.
+type flushingHandler struct {
+	lambda.Handler
+
+	namespace string
+	tp        *sdktrace.TracerProvider
+}
+
+func (h *flushingHandler) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	defer func() {
+		if h.tp != nil {
+			if err := h.tp.ForceFlush(ctx); err != nil {
+				slog.ErrorContext(ctx, "failed to flush spans", "error", err)
+			}
+		}
+
+		if h.namespace != "" {
+			if err := metrics.Flush(os.Stdout, h.namespace); err != nil {
+				slog.ErrorContext(ctx, "failed to flush metrics", "error", err)
+			}
+		}
+	}()
+
+	return h.Handler.Invoke(ctx, payload)
+}
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err = logging.Setup(cfg.LogLevel); err != nil {
+		log.Fatal(err)
+	}
+
+	tp, err := tracing.Setup(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var handler lambda.Handler = adapter.New(v1.NewRouter(cfg, db.NewPool(cfg)))
+
+	if cfg.Metrics.Namespace != "" || tp != nil {
+		handler = &flushingHandler{Handler: handler, namespace: cfg.Metrics.Namespace, tp: tp}
+	}
+
+	lambda.StartHandler(handler)
+}
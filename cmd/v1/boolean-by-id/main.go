@@ -1,13 +0,0 @@
-package main
-
-import (
-	"net/http"
-
-	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/awslabs/aws-lambda-go-api-proxy/httpadapter"
-	v1 "github.com/saschazar21/go-baas/api/v1"
-)
-
-func main() {
-	lambda.Start(httpadapter.New(http.HandlerFunc(v1.HandleBooleanById)).ProxyWithContext)
-}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/logging"
+	"github.com/saschazar21/go-baas/metrics"
+	"github.com/saschazar21/go-baas/tracing"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+var (
+	pool      *db.Pool
+	namespace string
+	tp        *sdktrace.TracerProvider
+)
+
+func handleRunSchedules(ctx context.Context) (err error) {
+	defer func() {
+		if tp != nil {
+			if err := tp.ForceFlush(ctx); err != nil {
+				slog.ErrorContext(ctx, "failed to flush spans", "error", err)
+			}
+		}
+
+		if namespace != "" {
+			if err := metrics.Flush(os.Stdout, namespace); err != nil {
+				slog.ErrorContext(ctx, "failed to flush metrics", "error", err)
+			}
+		}
+	}()
+
+	client, err := pool.Client(ctx)
+	if err != nil {
+		return
+	}
+
+	// a failing schedule must not keep expired leases from being reverted
+	applied, schedulesErr := booleans.RunDueSchedules(client, ctx, time.Now())
+
+	slog.InfoContext(ctx, "applied scheduled operations", "applied", applied)
+
+	reverted, leasesErr := booleans.RevertExpiredLeases(client, ctx, time.Now())
+
+	slog.InfoContext(ctx, "reverted expired leases", "reverted", reverted)
+
+	return errors.Join(schedulesErr, leasesErr)
+}
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err = logging.Setup(cfg.LogLevel); err != nil {
+		log.Fatal(err)
+	}
+
+	if tp, err = tracing.Setup(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+
+	pool = db.NewPool(cfg)
+	namespace = cfg.Metrics.Namespace
+
+	lambda.Start(handleRunSchedules)
+}
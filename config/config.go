@@ -0,0 +1,606 @@
+// Package config loads the settings of the service from an optional YAML or
+// TOML file and the env, which takes precedence over the file. The settings
+// are validated once at startup and passed to the components using them.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CONFIG_FILE_ENV = "BAAS_CONFIG_FILE"
+
+	REDIS_URL_ENV            = "REDIS_URL"
+	REDIS_POOL_SIZE_ENV      = "REDIS_POOL_SIZE"
+	REDIS_MIN_IDLE_CONNS_ENV = "REDIS_MIN_IDLE_CONNS"
+	REDIS_DIAL_TIMEOUT_ENV   = "REDIS_DIAL_TIMEOUT"
+	REDIS_READ_TIMEOUT_ENV   = "REDIS_READ_TIMEOUT"
+	REDIS_WRITE_TIMEOUT_ENV  = "REDIS_WRITE_TIMEOUT"
+
+	REDIS_SENTINEL_MASTER_ENV   = "REDIS_SENTINEL_MASTER"
+	REDIS_SENTINEL_ADDRS_ENV    = "REDIS_SENTINEL_ADDRS"
+	REDIS_SENTINEL_PASSWORD_ENV = "REDIS_SENTINEL_PASSWORD"
+	REDIS_CLUSTER_ADDRS_ENV     = "REDIS_CLUSTER_ADDRS"
+
+	KEY_PREFIX_ENV           = "BAAS_KEY_PREFIX"
+	MAX_TTL_ENV              = "BAAS_MAX_TTL"
+	DEFAULT_TTL_ENV          = "BAAS_DEFAULT_TTL"
+	STRICT_JSON_ENV          = "BAAS_STRICT_JSON"
+	API_KEYS_ENV             = "BAAS_API_KEYS"
+	CORS_ALLOWED_ORIGINS_ENV = "BAAS_CORS_ALLOWED_ORIGINS"
+	CORS_ALLOWED_METHODS_ENV = "BAAS_CORS_ALLOWED_METHODS"
+	CORS_ALLOWED_HEADERS_ENV = "BAAS_CORS_ALLOWED_HEADERS"
+	LOG_LEVEL_ENV            = "BAAS_LOG_LEVEL"
+	ADDR_ENV                 = "BAAS_ADDR"
+	METRICS_NAMESPACE_ENV    = "BAAS_METRICS_NAMESPACE"
+
+	IDEMPOTENCY_TTL_ENV = "BAAS_IDEMPOTENCY_TTL"
+
+	RATE_LIMIT_CLIENT_RATE_ENV    = "BAAS_RATE_LIMIT_CLIENT_RATE"
+	RATE_LIMIT_CLIENT_PERIOD_ENV  = "BAAS_RATE_LIMIT_CLIENT_PERIOD"
+	RATE_LIMIT_CLIENT_BURST_ENV   = "BAAS_RATE_LIMIT_CLIENT_BURST"
+	RATE_LIMIT_BOOLEAN_RATE_ENV   = "BAAS_RATE_LIMIT_BOOLEAN_RATE"
+	RATE_LIMIT_BOOLEAN_PERIOD_ENV = "BAAS_RATE_LIMIT_BOOLEAN_PERIOD"
+	RATE_LIMIT_BOOLEAN_BURST_ENV  = "BAAS_RATE_LIMIT_BOOLEAN_BURST"
+)
+
+const (
+	LOG_LEVEL_DEBUG = "debug"
+	LOG_LEVEL_INFO  = "info"
+	LOG_LEVEL_WARN  = "warn"
+	LOG_LEVEL_ERROR = "error"
+)
+
+const (
+	REDIS_MODE_STANDALONE = "standalone"
+	REDIS_MODE_SENTINEL   = "sentinel"
+	REDIS_MODE_CLUSTER    = "cluster"
+)
+
+const MAX_KEY_PREFIX_LENGTH = 64
+
+// corsMethods are the methods, which may be allowed from browsers.
+var corsMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete,
+}
+
+const (
+	DEFAULT_ADDR = ":8080"
+
+	MAX_METRICS_NAMESPACE_LENGTH = 255
+
+	DEFAULT_RATE_LIMIT_PERIOD = time.Minute
+
+	DEFAULT_IDEMPOTENCY_TTL = 24 * time.Hour
+)
+
+type Sentinel struct {
+	MasterName string   `yaml:"master_name" toml:"master_name"`
+	Addrs      []string `yaml:"addrs" toml:"addrs"`
+	Password   string   `yaml:"password" toml:"password"`
+}
+
+type Cluster struct {
+	// Addrs are the seed nodes, the remaining nodes are discovered.
+	Addrs []string `yaml:"addrs" toml:"addrs"`
+}
+
+type Redis struct {
+	// URL is the connection string of a single node. Using Sentinel or
+	// Cluster, its host is ignored, but its credentials and parameters apply.
+	URL          string        `yaml:"url" toml:"url"`
+	Sentinel     Sentinel      `yaml:"sentinel" toml:"sentinel"`
+	Cluster      Cluster       `yaml:"cluster" toml:"cluster"`
+	PoolSize     int           `yaml:"pool_size" toml:"pool_size"`
+	MinIdleConns int           `yaml:"min_idle_conns" toml:"min_idle_conns"`
+	DialTimeout  time.Duration `yaml:"dial_timeout" toml:"dial_timeout"`
+	ReadTimeout  time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+}
+
+// Mode returns REDIS_MODE_SENTINEL, if a Sentinel master name is set,
+// REDIS_MODE_CLUSTER, if cluster addresses are set, otherwise
+// REDIS_MODE_STANDALONE.
+func (r *Redis) Mode() string {
+	switch {
+	case r.Sentinel.MasterName != "":
+		return REDIS_MODE_SENTINEL
+	case len(r.Cluster.Addrs) > 0:
+		return REDIS_MODE_CLUSTER
+	default:
+		return REDIS_MODE_STANDALONE
+	}
+}
+
+// Options returns the client options for the mode, taken from the URL,
+// overridden by the pool size and timeouts, if set.
+func (r *Redis) Options() (options *redis.UniversalOptions, err error) {
+	options = &redis.UniversalOptions{}
+
+	if r.URL != "" {
+		var o *redis.Options
+		if o, err = redis.ParseURL(r.URL); err != nil {
+			return nil, err
+		}
+
+		options = &redis.UniversalOptions{
+			Addrs:                 []string{o.Addr},
+			ClientName:            o.ClientName,
+			DB:                    o.DB,
+			Protocol:              o.Protocol,
+			Username:              o.Username,
+			Password:              o.Password,
+			MaxRetries:            o.MaxRetries,
+			MinRetryBackoff:       o.MinRetryBackoff,
+			MaxRetryBackoff:       o.MaxRetryBackoff,
+			DialTimeout:           o.DialTimeout,
+			ReadTimeout:           o.ReadTimeout,
+			WriteTimeout:          o.WriteTimeout,
+			ContextTimeoutEnabled: o.ContextTimeoutEnabled,
+			PoolFIFO:              o.PoolFIFO,
+			PoolSize:              o.PoolSize,
+			PoolTimeout:           o.PoolTimeout,
+			MinIdleConns:          o.MinIdleConns,
+			MaxIdleConns:          o.MaxIdleConns,
+			MaxActiveConns:        o.MaxActiveConns,
+			ConnMaxIdleTime:       o.ConnMaxIdleTime,
+			ConnMaxLifetime:       o.ConnMaxLifetime,
+			TLSConfig:             o.TLSConfig,
+		}
+	}
+
+	switch r.Mode() {
+	case REDIS_MODE_SENTINEL:
+		options.MasterName = r.Sentinel.MasterName
+		options.Addrs = r.Sentinel.Addrs
+		options.SentinelPassword = r.Sentinel.Password
+	case REDIS_MODE_CLUSTER:
+		options.Addrs = r.Cluster.Addrs
+	}
+
+	if r.PoolSize > 0 {
+		options.PoolSize = r.PoolSize
+	}
+
+	if r.MinIdleConns > 0 {
+		options.MinIdleConns = r.MinIdleConns
+	}
+
+	if r.DialTimeout > 0 {
+		options.DialTimeout = r.DialTimeout
+	}
+
+	if r.ReadTimeout > 0 {
+		options.ReadTimeout = r.ReadTimeout
+	}
+
+	if r.WriteTimeout > 0 {
+		options.WriteTimeout = r.WriteTimeout
+	}
+
+	return
+}
+
+type Auth struct {
+	// APIKeys are accepted as bearer tokens, if empty, no authentication is
+	// required.
+	APIKeys []string `yaml:"api_keys" toml:"api_keys"`
+}
+
+type CORS struct {
+	// AllowedOrigins lists the origins allowed to use the API from browsers,
+	// "*" allows any origin.
+	AllowedOrigins []string `yaml:"allowed_origins" toml:"allowed_origins"`
+
+	// AllowedMethods restricts the methods allowed from browsers, if empty,
+	// all methods of a route are allowed.
+	AllowedMethods []string `yaml:"allowed_methods" toml:"allowed_methods"`
+
+	// AllowedHeaders lists the request headers allowed from browsers, if
+	// empty, the headers used by the API are allowed.
+	AllowedHeaders []string `yaml:"allowed_headers" toml:"allowed_headers"`
+}
+
+type Metrics struct {
+	// Namespace is the CloudWatch namespace of the metrics flushed as
+	// embedded metric format log lines on AWS Lambda, if empty, no metrics
+	// are flushed.
+	Namespace string `yaml:"namespace" toml:"namespace"`
+}
+
+// Limit is a token bucket, which holds up to Burst tokens and is refilled by
+// Rate tokens per Period. Every request takes one token.
+type Limit struct {
+	// Rate is the amount of requests per Period, 0 disables the limit.
+	Rate   int           `yaml:"rate" toml:"rate"`
+	Period time.Duration `yaml:"period" toml:"period"`
+
+	// Burst is the amount of requests allowed at once, 0 means Rate.
+	Burst int `yaml:"burst" toml:"burst"`
+}
+
+func (l *Limit) Enabled() bool {
+	return l.Rate > 0 && l.Period > 0
+}
+
+// Capacity returns the maximum amount of tokens of the bucket.
+func (l *Limit) Capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+
+	return l.Rate
+}
+
+// Interval returns the time to refill a single token.
+func (l *Limit) Interval() time.Duration {
+	return l.Period / time.Duration(l.Rate)
+}
+
+type RateLimit struct {
+	// Client limits the requests of every API key, or of every client IP,
+	// if no API keys are configured.
+	Client Limit `yaml:"client" toml:"client"`
+
+	// Boolean limits the writes to every boolean, regardless of the client.
+	Boolean Limit `yaml:"boolean" toml:"boolean"`
+}
+
+type Idempotency struct {
+	// TTL is the time, for which the responses to requests with an
+	// Idempotency-Key header are replayed.
+	TTL time.Duration `yaml:"ttl" toml:"ttl"`
+}
+
+type Config struct {
+	Redis Redis `yaml:"redis" toml:"redis"`
+
+	// KeyPrefix is prepended to all Redis keys, so that multiple deployments
+	// can share a database.
+	KeyPrefix string `yaml:"key_prefix" toml:"key_prefix"`
+
+	// MaxTTL limits the lifetime of booleans, 0 means unlimited.
+	MaxTTL time.Duration `yaml:"max_ttl" toml:"max_ttl"`
+
+	// DefaultTTL is the lifetime of new booleans created without expiry, 0
+	// means MaxTTL.
+	DefaultTTL time.Duration `yaml:"default_ttl" toml:"default_ttl"`
+
+	// StrictJSON rejects JSON request bodies with unknown fields.
+	StrictJSON bool `yaml:"strict_json" toml:"strict_json"`
+
+	Auth Auth `yaml:"auth" toml:"auth"`
+	CORS CORS `yaml:"cors" toml:"cors"`
+
+	LogLevel string `yaml:"log_level" toml:"log_level"`
+
+	// Addr is the listen address of the standalone server.
+	Addr string `yaml:"addr" toml:"addr"`
+
+	Metrics Metrics `yaml:"metrics" toml:"metrics"`
+
+	RateLimit RateLimit `yaml:"rate_limit" toml:"rate_limit"`
+
+	Idempotency Idempotency `yaml:"idempotency" toml:"idempotency"`
+}
+
+func Default() *Config {
+	return &Config{
+		LogLevel: LOG_LEVEL_INFO,
+		Addr:     DEFAULT_ADDR,
+		RateLimit: RateLimit{
+			Client:  Limit{Period: DEFAULT_RATE_LIMIT_PERIOD},
+			Boolean: Limit{Period: DEFAULT_RATE_LIMIT_PERIOD},
+		},
+		Idempotency: Idempotency{TTL: DEFAULT_IDEMPOTENCY_TTL},
+	}
+}
+
+// Load returns the default config, overridden by the file given in the
+// BAAS_CONFIG_FILE env, if any, and the env. The result is validated.
+func Load() (c *Config, err error) {
+	c = Default()
+
+	if path := os.Getenv(CONFIG_FILE_ENV); path != "" {
+		if err = c.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = c.LoadEnv(); err != nil {
+		return nil, err
+	}
+
+	if err = c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// LoadFile reads the YAML or TOML file, according to its extension, into
+// the config. Settings missing in the file are kept.
+func (c *Config) LoadFile(path string) (err error) {
+	var content []byte
+	if content, err = os.ReadFile(path); err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, c)
+	case ".toml":
+		err = toml.Unmarshal(content, c)
+	default:
+		return fmt.Errorf("unsupported config file extension %q, use .yaml, .yml or .toml", ext)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return
+}
+
+// LoadEnv overrides the config with the settings present in the env.
+func (c *Config) LoadEnv() (err error) {
+	strs := map[string]*string{
+		REDIS_URL_ENV:               &c.Redis.URL,
+		REDIS_SENTINEL_MASTER_ENV:   &c.Redis.Sentinel.MasterName,
+		REDIS_SENTINEL_PASSWORD_ENV: &c.Redis.Sentinel.Password,
+		KEY_PREFIX_ENV:              &c.KeyPrefix,
+		LOG_LEVEL_ENV:               &c.LogLevel,
+		ADDR_ENV:                    &c.Addr,
+		METRICS_NAMESPACE_ENV:       &c.Metrics.Namespace,
+	}
+
+	for env, field := range strs {
+		if value, ok := os.LookupEnv(env); ok {
+			*field = value
+		}
+	}
+
+	ints := map[string]*int{
+		REDIS_POOL_SIZE_ENV:          &c.Redis.PoolSize,
+		REDIS_MIN_IDLE_CONNS_ENV:     &c.Redis.MinIdleConns,
+		RATE_LIMIT_CLIENT_RATE_ENV:   &c.RateLimit.Client.Rate,
+		RATE_LIMIT_CLIENT_BURST_ENV:  &c.RateLimit.Client.Burst,
+		RATE_LIMIT_BOOLEAN_RATE_ENV:  &c.RateLimit.Boolean.Rate,
+		RATE_LIMIT_BOOLEAN_BURST_ENV: &c.RateLimit.Boolean.Burst,
+	}
+
+	for env, field := range ints {
+		if value := os.Getenv(env); value != "" {
+			if *field, err = strconv.Atoi(value); err != nil {
+				return fmt.Errorf("%s: %q is not an integer", env, value)
+			}
+		}
+	}
+
+	bools := map[string]*bool{
+		STRICT_JSON_ENV: &c.StrictJSON,
+	}
+
+	for env, field := range bools {
+		if value := os.Getenv(env); value != "" {
+			if *field, err = strconv.ParseBool(value); err != nil {
+				return fmt.Errorf("%s: %q is not a boolean", env, value)
+			}
+		}
+	}
+
+	durations := map[string]*time.Duration{
+		REDIS_DIAL_TIMEOUT_ENV:        &c.Redis.DialTimeout,
+		REDIS_READ_TIMEOUT_ENV:        &c.Redis.ReadTimeout,
+		REDIS_WRITE_TIMEOUT_ENV:       &c.Redis.WriteTimeout,
+		MAX_TTL_ENV:                   &c.MaxTTL,
+		DEFAULT_TTL_ENV:               &c.DefaultTTL,
+		RATE_LIMIT_CLIENT_PERIOD_ENV:  &c.RateLimit.Client.Period,
+		RATE_LIMIT_BOOLEAN_PERIOD_ENV: &c.RateLimit.Boolean.Period,
+		IDEMPOTENCY_TTL_ENV:           &c.Idempotency.TTL,
+	}
+
+	for env, field := range durations {
+		if value := os.Getenv(env); value != "" {
+			if *field, err = time.ParseDuration(value); err != nil {
+				return fmt.Errorf("%s: %q is not a duration, e.g. 30s or 24h", env, value)
+			}
+		}
+	}
+
+	lists := map[string]*[]string{
+		REDIS_SENTINEL_ADDRS_ENV: &c.Redis.Sentinel.Addrs,
+		REDIS_CLUSTER_ADDRS_ENV:  &c.Redis.Cluster.Addrs,
+		API_KEYS_ENV:             &c.Auth.APIKeys,
+		CORS_ALLOWED_ORIGINS_ENV: &c.CORS.AllowedOrigins,
+		CORS_ALLOWED_METHODS_ENV: &c.CORS.AllowedMethods,
+		CORS_ALLOWED_HEADERS_ENV: &c.CORS.AllowedHeaders,
+	}
+
+	for env, field := range lists {
+		if value, ok := os.LookupEnv(env); ok {
+			*field = splitList(value)
+		}
+	}
+
+	return
+}
+
+// splitList splits a comma-separated list, omitting empty entries.
+func splitList(value string) (list []string) {
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+
+	return
+}
+
+// Validate checks all settings and reports every invalid one, named by its
+// key in the config file.
+func (c *Config) Validate() error {
+	var errs []error
+
+	invalid := func(key, problem string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(problem, args...)))
+	}
+
+	mode := c.Redis.Mode()
+
+	if c.Redis.URL == "" && mode == REDIS_MODE_STANDALONE {
+		invalid("redis.url", "is required, set it in the config file or the %s env", REDIS_URL_ENV)
+	} else if c.Redis.URL != "" {
+		if o, err := redis.ParseURL(c.Redis.URL); err != nil {
+			invalid("redis.url", "%v", err)
+		} else if mode == REDIS_MODE_CLUSTER && o.DB != 0 {
+			invalid("redis.url", "must not select a database other than 0 in cluster mode, got %d", o.DB)
+		}
+	}
+
+	if c.Redis.Sentinel.MasterName != "" && len(c.Redis.Cluster.Addrs) > 0 {
+		invalid("redis.cluster.addrs", "must not be set together with redis.sentinel.master_name")
+	}
+
+	if mode == REDIS_MODE_SENTINEL && len(c.Redis.Sentinel.Addrs) == 0 {
+		invalid("redis.sentinel.addrs", "is required with redis.sentinel.master_name, set it in the config file or the %s env", REDIS_SENTINEL_ADDRS_ENV)
+	}
+
+	if mode != REDIS_MODE_SENTINEL && len(c.Redis.Sentinel.Addrs) > 0 {
+		invalid("redis.sentinel.master_name", "is required with redis.sentinel.addrs, set it in the config file or the %s env", REDIS_SENTINEL_MASTER_ENV)
+	}
+
+	for key, addrs := range map[string][]string{
+		"redis.sentinel.addrs": c.Redis.Sentinel.Addrs,
+		"redis.cluster.addrs":  c.Redis.Cluster.Addrs,
+	} {
+		for i, addr := range addrs {
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				invalid(fmt.Sprintf("%s[%d]", key, i), "must be an address like localhost:6379, got %q", addr)
+			}
+		}
+	}
+
+	for key, value := range map[string]int{
+		"redis.pool_size":      c.Redis.PoolSize,
+		"redis.min_idle_conns": c.Redis.MinIdleConns,
+	} {
+		if value < 0 {
+			invalid(key, "must not be negative, got %d", value)
+		}
+	}
+
+	for key, value := range map[string]time.Duration{
+		"redis.dial_timeout":  c.Redis.DialTimeout,
+		"redis.read_timeout":  c.Redis.ReadTimeout,
+		"redis.write_timeout": c.Redis.WriteTimeout,
+	} {
+		if value < 0 {
+			invalid(key, "must not be negative, got %s", value)
+		}
+	}
+
+	if len(c.KeyPrefix) > MAX_KEY_PREFIX_LENGTH {
+		invalid("key_prefix", "must not be longer than %d characters", MAX_KEY_PREFIX_LENGTH)
+	}
+
+	if strings.ContainsAny(c.KeyPrefix, "{}*?[]\\ \t\r\n") {
+		invalid("key_prefix", "must not contain whitespace, braces or the pattern characters *?[]\\, got %q", c.KeyPrefix)
+	}
+
+	if c.MaxTTL < 0 || (c.MaxTTL > 0 && c.MaxTTL < time.Second) {
+		invalid("max_ttl", "must be 0 for unlimited or at least 1s, got %s", c.MaxTTL)
+	}
+
+	if c.DefaultTTL < 0 || (c.DefaultTTL > 0 && c.DefaultTTL < time.Second) {
+		invalid("default_ttl", "must be 0 or at least 1s, got %s", c.DefaultTTL)
+	} else if c.MaxTTL > 0 && c.DefaultTTL > c.MaxTTL {
+		invalid("default_ttl", "must not exceed max_ttl of %s, got %s", c.MaxTTL, c.DefaultTTL)
+	}
+
+	for i, key := range c.Auth.APIKeys {
+		if key == "" || strings.ContainsAny(key, " \t\r\n") {
+			invalid(fmt.Sprintf("auth.api_keys[%d]", i), "must not be empty or contain whitespace")
+		}
+	}
+
+	for i, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			invalid(fmt.Sprintf("cors.allowed_origins[%d]", i), "must be \"*\" or an origin like https://example.com, got %q", origin)
+		}
+	}
+
+	for i, method := range c.CORS.AllowedMethods {
+		if !slices.Contains(corsMethods, method) {
+			invalid(fmt.Sprintf("cors.allowed_methods[%d]", i), "must be one of %s, got %q", strings.Join(corsMethods, ", "), method)
+		}
+	}
+
+	for i, header := range c.CORS.AllowedHeaders {
+		if header == "" || strings.ContainsAny(header, " \t\r\n,:()<>@;\\\"/[]?={}") {
+			invalid(fmt.Sprintf("cors.allowed_headers[%d]", i), "must be a header name like X-Custom-Header, got %q", header)
+		}
+	}
+
+	if !slices.Contains([]string{LOG_LEVEL_DEBUG, LOG_LEVEL_INFO, LOG_LEVEL_WARN, LOG_LEVEL_ERROR}, c.LogLevel) {
+		invalid("log_level", "must be one of debug, info, warn or error, got %q", c.LogLevel)
+	}
+
+	if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+		invalid("addr", "must be a listen address like :8080, got %q", c.Addr)
+	}
+
+	if len(c.Metrics.Namespace) > MAX_METRICS_NAMESPACE_LENGTH || strings.ContainsAny(c.Metrics.Namespace, " \t\r\n") {
+		invalid("metrics.namespace", "must not be longer than %d characters or contain whitespace", MAX_METRICS_NAMESPACE_LENGTH)
+	}
+
+	for key, limit := range map[string]Limit{
+		"rate_limit.client":  c.RateLimit.Client,
+		"rate_limit.boolean": c.RateLimit.Boolean,
+	} {
+		if limit.Rate < 0 {
+			invalid(key+".rate", "must not be negative, got %d", limit.Rate)
+		}
+
+		if limit.Burst < 0 {
+			invalid(key+".burst", "must not be negative, got %d", limit.Burst)
+		}
+
+		if limit.Rate > 0 && limit.Period < time.Second {
+			invalid(key+".period", "must be at least 1s, got %s", limit.Period)
+		}
+	}
+
+	if c.Idempotency.TTL < time.Second {
+		invalid("idempotency.ttl", "must be at least 1s, got %s", c.Idempotency.TTL)
+	}
+
+	if len(errs) > 0 {
+		slices.SortFunc(errs, func(a, b error) int {
+			return strings.Compare(a.Error(), b.Error())
+		})
+
+		return fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+	}
+
+	return nil
+}
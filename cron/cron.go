@@ -0,0 +1,271 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MAX_LOOKAHEAD_DAYS limits the search for the next fire time, so that
+// expressions which never match (e.g. 30th of February) terminate.
+const MAX_LOOKAHEAD_DAYS = 366 * 5
+
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12, names: map[string]int{
+		"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+		"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+	}}
+	dowField = field{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+	}}
+)
+
+// Schedule is a parsed five-field cron expression bound to a time zone.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domRestricted and dowRestricted track whether the day fields were
+	// given explicitly, as cron matches either of them in that case.
+	domRestricted, dowRestricted bool
+
+	location *time.Location
+}
+
+// Parse parses a cron expression of the form "minute hour day-of-month month
+// day-of-week", optionally followed by an IANA time zone, e.g.
+// "0 9 * * MON-FRI Europe/Vienna". The timezone argument is used when the
+// expression itself does not contain one, defaulting to UTC.
+func Parse(spec, timezone string) (s *Schedule, err error) {
+	fields := strings.Fields(spec)
+
+	switch len(fields) {
+	case 5:
+	case 6:
+		if timezone != "" && timezone != fields[5] {
+			return nil, fmt.Errorf("conflicting time zones %q and %q", fields[5], timezone)
+		}
+
+		timezone = fields[5]
+	default:
+		return nil, fmt.Errorf("expected 5 fields and an optional time zone, got %d fields", len(fields))
+	}
+
+	s = &Schedule{location: time.UTC}
+
+	if timezone != "" {
+		if s.location, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", timezone, err)
+		}
+	}
+
+	if s.minute, err = parseField(fields[0], minuteField); err != nil {
+		return nil, err
+	}
+
+	if s.hour, err = parseField(fields[1], hourField); err != nil {
+		return nil, err
+	}
+
+	if s.dom, err = parseField(fields[2], domField); err != nil {
+		return nil, err
+	}
+
+	if s.month, err = parseField(fields[3], monthField); err != nil {
+		return nil, err
+	}
+
+	if s.dow, err = parseField(fields[4], dowField); err != nil {
+		return nil, err
+	}
+
+	// 7 is an alias for Sunday
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	s.domRestricted = fields[2] != "*"
+	s.dowRestricted = fields[4] != "*"
+
+	return
+}
+
+func parseField(expr string, f field) (bits uint64, err error) {
+	for _, part := range strings.Split(expr, ",") {
+		var b uint64
+		if b, err = parseRange(part, f); err != nil {
+			return 0, err
+		}
+
+		bits |= b
+	}
+
+	return
+}
+
+func parseRange(expr string, f field) (bits uint64, err error) {
+	rng, stepExpr, hasStep := strings.Cut(expr, "/")
+
+	step := 1
+
+	if hasStep {
+		if step, err = strconv.Atoi(stepExpr); err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step %q in %s field", stepExpr, f.name)
+		}
+	}
+
+	start, end := f.min, f.max
+
+	switch {
+	case rng == "*":
+	case strings.Contains(rng, "-"):
+		lo, hi, _ := strings.Cut(rng, "-")
+
+		if start, err = parseValue(lo, f); err != nil {
+			return
+		}
+
+		if end, err = parseValue(hi, f); err != nil {
+			return
+		}
+
+		if start > end {
+			return 0, fmt.Errorf("invalid range %q in %s field", rng, f.name)
+		}
+	default:
+		if start, err = parseValue(rng, f); err != nil {
+			return
+		}
+
+		if hasStep {
+			end = f.max
+		} else {
+			end = start
+		}
+	}
+
+	for i := start; i <= end; i += step {
+		bits |= 1 << uint(i)
+	}
+
+	return
+}
+
+func parseValue(expr string, f field) (value int, err error) {
+	if v, ok := f.names[strings.ToUpper(expr)]; ok {
+		return v, nil
+	}
+
+	if value, err = strconv.Atoi(expr); err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", expr, f.name)
+	}
+
+	if value < f.min || value > f.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d] in %s field", value, f.min, f.max, f.name)
+	}
+
+	return
+}
+
+// Location returns the time zone the schedule is evaluated in.
+func (s *Schedule) Location() *time.Location {
+	return s.location
+}
+
+func (s *Schedule) matchesDay(date time.Time) bool {
+	if s.month&(1<<uint(date.Month())) == 0 {
+		return false
+	}
+
+	dom := s.dom&(1<<uint(date.Day())) != 0
+	dow := s.dow&(1<<uint(date.Weekday())) != 0
+
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+
+	return dom && dow
+}
+
+// instant resolves a local wall-clock time to an instant. Times skipped by a
+// DST transition resolve to the corresponding time after the transition, times
+// repeated by a DST transition resolve to their first occurrence.
+func (s *Schedule) instant(year int, month time.Month, day, hour, minute int) time.Time {
+	t := time.Date(year, month, day, hour, minute, 0, 0, s.location)
+
+	_, offset := t.Zone()
+	_, before := t.Add(-12 * time.Hour).Zone()
+
+	if before > offset {
+		earlier := t.Add(-time.Duration(before-offset) * time.Second)
+
+		if earlier.Day() == day && earlier.Hour() == hour && earlier.Minute() == minute {
+			return earlier
+		}
+	}
+
+	return t
+}
+
+// Next returns the first fire time strictly after t, or the zero time if the
+// schedule does not fire within MAX_LOOKAHEAD_DAYS.
+func (s *Schedule) Next(t time.Time) time.Time {
+	local := t.In(s.location)
+	year, month, day := local.Date()
+
+	for i := 0; i <= MAX_LOOKAHEAD_DAYS; i++ {
+		date := time.Date(year, month, day+i, 0, 0, 0, 0, time.UTC)
+
+		if !s.matchesDay(date) {
+			continue
+		}
+
+		for hour := 0; hour < 24; hour++ {
+			if s.hour&(1<<uint(hour)) == 0 {
+				continue
+			}
+
+			if i == 0 && hour < local.Hour() {
+				continue
+			}
+
+			for minute := 0; minute < 60; minute++ {
+				if s.minute&(1<<uint(minute)) == 0 {
+					continue
+				}
+
+				if i == 0 && hour == local.Hour() && minute <= local.Minute() {
+					continue
+				}
+
+				if next := s.instant(date.Year(), date.Month(), date.Day(), hour, minute); next.After(t) {
+					return next
+				}
+			}
+		}
+	}
+
+	return time.Time{}
+}
+
+// NextN returns up to n consecutive fire times after t.
+func (s *Schedule) NextN(t time.Time, n int) (times []time.Time) {
+	for len(times) < n {
+		if t = s.Next(t); t.IsZero() {
+			break
+		}
+
+		times = append(times, t)
+	}
+
+	return
+}
@@ -0,0 +1,210 @@
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	type test struct {
+		name     string
+		spec     string
+		timezone string
+		wantErr  bool
+	}
+
+	tests := []test{
+		{
+			name: "every minute",
+			spec: "* * * * *",
+		},
+		{
+			name: "business hours with names",
+			spec: "0 9 * * MON-FRI",
+		},
+		{
+			name: "lists, ranges and steps",
+			spec: "*/15 8-18/2 1,15 JAN-jun 0,7",
+		},
+		{
+			name: "time zone in expression",
+			spec: "0 9 * * MON-FRI Europe/Vienna",
+		},
+		{
+			name:     "time zone as argument",
+			spec:     "0 9 * * MON-FRI",
+			timezone: "Europe/Vienna",
+		},
+		{
+			name:     "conflicting time zones",
+			spec:     "0 9 * * MON-FRI Europe/Vienna",
+			timezone: "America/New_York",
+			wantErr:  true,
+		},
+		{
+			name:    "invalid time zone",
+			spec:    "0 9 * * MON-FRI Mars/Olympus_Mons",
+			wantErr: true,
+		},
+		{
+			name:    "too few fields",
+			spec:    "0 9 * *",
+			wantErr: true,
+		},
+		{
+			name:    "out of range",
+			spec:    "60 * * * *",
+			wantErr: true,
+		},
+		{
+			name:    "inverted range",
+			spec:    "0 18-9 * * *",
+			wantErr: true,
+		},
+		{
+			name:    "invalid step",
+			spec:    "*/0 * * * *",
+			wantErr: true,
+		},
+		{
+			name:    "invalid name",
+			spec:    "0 9 * * MONDAY",
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Parse(tc.spec, tc.timezone); (err != nil) != tc.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	vienna, err := time.LoadLocation("Europe/Vienna")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type test struct {
+		name string
+		spec string
+		now  time.Time
+		want []time.Time
+	}
+
+	tests := []test{
+		{
+			name: "business days skip the weekend",
+			spec: "0 9 * * MON-FRI Europe/Vienna",
+			// Friday
+			now: time.Date(2026, 1, 9, 10, 0, 0, 0, vienna),
+			want: []time.Time{
+				time.Date(2026, 1, 12, 8, 0, 0, 0, time.UTC),
+				time.Date(2026, 1, 13, 8, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "strictly after now",
+			spec: "0 9 * * *",
+			now:  time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "keeps local time across spring forward",
+			spec: "0 9 * * * Europe/Vienna",
+			now:  time.Date(2026, 3, 28, 10, 0, 0, 0, vienna),
+			want: []time.Time{
+				// CET, UTC+1
+				time.Date(2026, 3, 29, 7, 0, 0, 0, time.UTC),
+				// CEST, UTC+2
+				time.Date(2026, 3, 30, 7, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "keeps local time across fall back",
+			spec: "0 18 * * * Europe/Vienna",
+			now:  time.Date(2026, 10, 24, 19, 0, 0, 0, vienna),
+			want: []time.Time{
+				// CET, UTC+1
+				time.Date(2026, 10, 25, 17, 0, 0, 0, time.UTC),
+				time.Date(2026, 10, 26, 17, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "skipped local time fires after the transition",
+			spec: "30 2 * * * Europe/Vienna",
+			now:  time.Date(2026, 3, 28, 12, 0, 0, 0, vienna),
+			want: []time.Time{
+				// 02:30 does not exist, fires at 03:30 CEST
+				time.Date(2026, 3, 29, 1, 30, 0, 0, time.UTC),
+				time.Date(2026, 3, 30, 0, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "repeated local time fires once",
+			spec: "30 2 * * * Europe/Vienna",
+			now:  time.Date(2026, 10, 24, 12, 0, 0, 0, vienna),
+			want: []time.Time{
+				// first occurrence of 02:30, still CEST
+				time.Date(2026, 10, 25, 0, 30, 0, 0, time.UTC),
+				time.Date(2026, 10, 26, 1, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "day of month or day of week",
+			spec: "0 0 13 * FRI",
+			now:  time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			want: []time.Time{
+				time.Date(2026, 2, 6, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 2, 13, 0, 0, 0, 0, time.UTC),
+				time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "never fires",
+			spec: "0 0 30 FEB *",
+			now:  time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Parse(tc.spec, "")
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			got := s.NextN(tc.now, len(tc.want)+1)
+
+			if len(tc.want) == 0 {
+				assert.Empty(t, got)
+				return
+			}
+
+			for i, want := range tc.want {
+				assert.True(t, want.Equal(got[i]), "fire time %d: got %s, want %s", i, got[i].UTC(), want)
+			}
+		})
+	}
+
+	t.Run("repeated local time does not fire again in the second pass", func(t *testing.T) {
+		s, err := Parse("*/20 * * * * Europe/Vienna", "")
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+
+		// 02:10 CET, after the clocks went back from 03:00 CEST
+		now := time.Date(2026, 10, 25, 1, 10, 0, 0, time.UTC)
+		want := time.Date(2026, 10, 25, 2, 0, 0, 0, time.UTC)
+
+		assert.True(t, want.Equal(s.Next(now)), "got %s, want %s", s.Next(now).UTC(), want)
+	})
+}
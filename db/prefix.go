@@ -0,0 +1,184 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type prefixMode int
+
+const (
+	// prefixAll handles all commands, as used by single node clients.
+	prefixAll prefixMode = iota
+	// prefixKeys skips scans, as cluster clients pass them to a node client.
+	prefixKeys
+	// prefixScans only handles scans, as used by the node clients of a
+	// cluster client, which receive scans directly.
+	prefixScans
+)
+
+// prefixHook prepends the prefix to the keys of all commands and strips it
+// from the keys returned by SCAN, so that the prefix is transparent to the
+// rest of the code. Lua scripts must only access keys passed in KEYS.
+type prefixHook struct {
+	prefix string
+	mode   prefixMode
+}
+
+// addPrefixHook adds the hooks handling the prefix to the client, for
+// cluster clients, to the client of every node as well.
+func addPrefixHook(client redis.UniversalClient, prefix string) {
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		cluster.AddHook(&prefixHook{prefix: prefix, mode: prefixKeys})
+		cluster.OnNewNode(func(node *redis.Client) {
+			node.AddHook(&prefixHook{prefix: prefix, mode: prefixScans})
+		})
+
+		return
+	}
+
+	client.AddHook(&prefixHook{prefix: prefix, mode: prefixAll})
+}
+
+// keylessCommands do not take any key.
+var keylessCommands = map[string]bool{
+	"auth": true, "client": true, "config": true, "dbsize": true, "discard": true,
+	"echo": true, "exec": true, "flushall": true, "flushdb": true, "hello": true,
+	"info": true, "multi": true, "ping": true, "quit": true, "readonly": true,
+	"script": true, "select": true, "time": true, "unwatch": true,
+}
+
+// multiKeyCommands only take keys as arguments.
+var multiKeyCommands = map[string]bool{
+	"del": true, "exists": true, "mget": true, "touch": true, "unlink": true,
+	"watch": true,
+}
+
+func (h *prefixHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *prefixHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.prefixKeys(cmd)
+
+		err := next(ctx, cmd)
+
+		h.stripKeys(cmd)
+
+		return err
+	}
+}
+
+func (h *prefixHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			h.prefixKeys(cmd)
+		}
+
+		err := next(ctx, cmds)
+
+		for _, cmd := range cmds {
+			h.stripKeys(cmd)
+		}
+
+		return err
+	}
+}
+
+// prefixKeys rewrites the keys in the arguments of the command in place.
+func (h *prefixHook) prefixKeys(cmd redis.Cmder) {
+	args := cmd.Args()
+	name := strings.ToLower(cmd.Name())
+
+	if !h.handles(name) {
+		return
+	}
+
+	switch {
+	case keylessCommands[name] || len(args) < 2:
+		return
+	case multiKeyCommands[name]:
+		for i := 1; i < len(args); i++ {
+			args[i] = h.prefixKey(toString(args[i]))
+		}
+	case name == "eval" || name == "evalsha" || name == "eval_ro" || name == "evalsha_ro":
+		if len(args) < 3 {
+			return
+		}
+
+		n, _ := strconv.Atoi(toString(args[2]))
+
+		for i := 3; i < 3+n && i < len(args); i++ {
+			args[i] = h.prefixKey(toString(args[i]))
+		}
+	case name == "scan":
+		for i := 2; i < len(args)-1; i++ {
+			if strings.EqualFold(toString(args[i]), "match") {
+				args[i+1] = h.prefixKey(toString(args[i+1]))
+			}
+		}
+	default:
+		args[1] = h.prefixKey(toString(args[1]))
+	}
+}
+
+func (h *prefixHook) handles(name string) bool {
+	switch h.mode {
+	case prefixKeys:
+		return name != "scan"
+	case prefixScans:
+		return name == "scan"
+	default:
+		return true
+	}
+}
+
+// prefixKey prepends the prefix to the key and to its hash tag, if any, so
+// that a key tagged with another key, e.g. schedules:{id} and id, keeps
+// sharing its cluster slot.
+func (h *prefixHook) prefixKey(key string) string {
+	if start := strings.IndexByte(key, '{'); start >= 0 && strings.IndexByte(key[start+1:], '}') > 0 {
+		key = key[:start+1] + h.prefix + key[start+1:]
+	}
+
+	return h.prefix + key
+}
+
+func (h *prefixHook) stripKey(key string) string {
+	return strings.Replace(strings.TrimPrefix(key, h.prefix), "{"+h.prefix, "{", 1)
+}
+
+// stripKeys removes the prefix from the keys returned by SCAN.
+func (h *prefixHook) stripKeys(cmd redis.Cmder) {
+	scan, ok := cmd.(*redis.ScanCmd)
+	if !ok || !h.handles(strings.ToLower(cmd.Name())) {
+		return
+	}
+
+	keys, cursor := scan.Val()
+
+	for i, key := range keys {
+		keys[i] = h.stripKey(key)
+	}
+
+	scan.SetVal(keys, cursor)
+}
+
+func toString(arg interface{}) string {
+	switch v := arg.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixKey(t *testing.T) {
+	h := &prefixHook{prefix: "tenant:"}
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "id", want: "tenant:id"},
+		{key: "schedules:{id}", want: "tenant:schedules:{tenant:id}"},
+		{key: "schedules:{}", want: "tenant:schedules:{}"},
+		{key: "*", want: "tenant:*"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.key, func(t *testing.T) {
+			got := h.prefixKey(tc.key)
+
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.key, h.stripKey(got))
+		})
+	}
+
+	assert.Equal(t, test.Slot(h.prefixKey("id")), test.Slot(h.prefixKey("schedules:{id}")))
+
+	cmd := redis.NewMapStringStringCmd(context.Background(), "config", "get", "notify-keyspace-events")
+	h.prefixKeys(cmd)
+
+	assert.Equal(t, []interface{}{"config", "get", "notify-keyspace-events"}, cmd.Args())
+}
+
+func TestPrefixHook(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	c := test.Config(t)
+
+	plain, err := NewRedis(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.KeyPrefix = "tenant:"
+
+	prefixed, err := NewRedis(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		prefixed.Close()
+		plain.Close()
+	})
+
+	t.Run("writes prefixed keys", func(t *testing.T) {
+		if err := prefixed.HSet(ctx, "one", "value", "1").Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := prefixed.Set(ctx, "two", "2", 0).Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, int64(2), plain.Exists(ctx, "tenant:one", "tenant:two").Val())
+		assert.Equal(t, int64(0), plain.Exists(ctx, "one", "two").Val())
+		assert.Equal(t, "1", prefixed.HGet(ctx, "one", "value").Val())
+	})
+
+	t.Run("pipelines and transactions", func(t *testing.T) {
+		if _, err := prefixed.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, "three", "3", 0)
+			pipe.Incr(ctx, "three")
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, "4", plain.Get(ctx, "tenant:three").Val())
+	})
+
+	t.Run("scan strips the prefix", func(t *testing.T) {
+		if err := plain.Set(ctx, "other", "x", 0).Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		keys, _, err := prefixed.Scan(ctx, 0, "*", 100).Result()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.ElementsMatch(t, []string{"one", "two", "three"}, keys)
+	})
+
+	t.Run("script keys", func(t *testing.T) {
+		script := redis.NewScript(`return redis.call("GET", KEYS[1])`)
+
+		got, err := script.Run(ctx, prefixed, []string{"two"}).Text()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, "2", got)
+	})
+
+	t.Run("deletes multiple keys", func(t *testing.T) {
+		assert.Equal(t, int64(3), prefixed.Del(ctx, "one", "two", "three").Val())
+		assert.Equal(t, int64(1), plain.Exists(ctx, "other").Val())
+	})
+
+	t.Run("cluster", func(t *testing.T) {
+		cluster, err := test.CreateCluster(ctx, t, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		addPrefixHook(cluster, "tenant:")
+
+		for _, key := range []string{"a", "b", "c", "schedules:{a}"} {
+			if err := cluster.Set(ctx, key, key, 0).Err(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		assert.Equal(t, "schedules:{a}", cluster.Get(ctx, "schedules:{a}").Val())
+		assert.Equal(t, int64(2), cluster.Del(ctx, "a", "schedules:{a}").Val())
+
+		var mu sync.Mutex
+		keys := []string{}
+
+		if err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			found, _, err := node.Scan(ctx, 0, "*", 100).Result()
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			keys = append(keys, found...)
+
+			return err
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.ElementsMatch(t, []string{"b", "c"}, keys)
+	})
+}
@@ -1,31 +1,136 @@
 package db
 
 import (
-	"log"
-	"os"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/metrics"
 )
 
-const REDIS_URL_ENV string = "REDIS_URL"
+const REDIS_URL_ENV string = config.REDIS_URL_ENV
 
-func NewRedis() (rdb *redis.Client, err error) {
-	if os.Getenv(REDIS_URL_ENV) == "" {
-		log.Fatal("No REDIS_URL env provided.")
+// HEALTH_CHECK_INTERVAL is the minimum time between two health checks of the
+// pooled client.
+const HEALTH_CHECK_INTERVAL = 30 * time.Second
 
-		return
+// NewRedis creates a single node, Sentinel failover or cluster client
+// according to the config, prefixing all keys with the configured key prefix
+// and observing the commands in the metrics and traces.
+func NewRedis(c *config.Config) (rdb redis.UniversalClient, err error) {
+	var options *redis.UniversalOptions
+	if options, err = c.Redis.Options(); err != nil {
+		return nil, fmt.Errorf("invalid redis config: %w", err)
 	}
 
-	var options *redis.Options
-	options, err = redis.ParseURL(os.Getenv(REDIS_URL_ENV))
+	switch c.Redis.Mode() {
+	case config.REDIS_MODE_SENTINEL:
+		rdb = redis.NewFailoverClient(options.Failover())
+	case config.REDIS_MODE_CLUSTER:
+		rdb = redis.NewClusterClient(options.Cluster())
+	default:
+		rdb = redis.NewClient(options.Simple())
+	}
+
+	rdb.AddHook(metrics.RedisHook{})
+
+	// the statements are omitted, as they contain values like lease tokens
+	if err = redisotel.InstrumentTracing(rdb, redisotel.WithDBStatement(false)); err != nil {
+		rdb.Close()
+
+		return nil, fmt.Errorf("failed to instrument redis client: %w", err)
+	}
+
+	if c.KeyPrefix != "" {
+		addPrefixHook(rdb, c.KeyPrefix)
+	}
+
+	return
+}
+
+// Pool holds a client, which is shared by all requests of a process. The
+// client is created on first use and checked using PING at most every
+// HEALTH_CHECK_INTERVAL, until the check passes again, the client is not
+// handed out. Broken connections are replaced by the client itself.
+type Pool struct {
+	config *config.Config
+
+	mu      sync.Mutex
+	client  redis.UniversalClient
+	checked time.Time
+}
+
+func NewPool(c *config.Config) *Pool {
+	return &Pool{config: c}
+}
+
+// Client returns the pooled client, creating it if necessary. The client is
+// checked without holding the lock, so that a slow check does not delay the
+// callers of a healthy client.
+func (p *Pool) Client(ctx context.Context) (redis.UniversalClient, error) {
+	p.mu.Lock()
+	client, checked := p.client, p.checked
+	p.mu.Unlock()
+
+	if client != nil && time.Since(checked) < HEALTH_CHECK_INTERVAL {
+		return client, nil
+	}
+
+	if client == nil {
+		created, err := NewRedis(p.config)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to create redis client", "error", err)
+
+			return nil, err
+		}
+
+		p.mu.Lock()
+
+		// another caller may have stored a client in the meantime
+		if p.client == nil {
+			slog.DebugContext(ctx, "created redis client", "mode", p.config.Redis.Mode())
+
+			p.client = created
+		} else {
+			created.Close()
+		}
+
+		client = p.client
+
+		p.mu.Unlock()
+	}
 
-	if err != nil {
-		log.Println(err)
+	if err := client.Ping(ctx).Err(); err != nil {
+		slog.ErrorContext(ctx, "redis health check failed", "error", err)
 
-		return
+		return nil, fmt.Errorf("redis health check failed: %w", err)
 	}
 
-	rdb = redis.NewClient(options)
+	p.mu.Lock()
+
+	if p.client == client {
+		p.checked = time.Now()
+	}
+
+	p.mu.Unlock()
+
+	return client, nil
+}
+
+// Close closes the pooled client, a subsequent use creates a new one.
+func (p *Pool) Close() (err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client != nil {
+		err = p.client.Close()
+		p.client = nil
+	}
 
 	return
 }
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRedis(t *testing.T) {
+	tests := []struct {
+		name  string
+		redis config.Redis
+		want  interface{}
+	}{
+		{
+			name:  "standalone",
+			redis: config.Redis{URL: "redis://localhost:6379"},
+			want:  &redis.Client{},
+		},
+		{
+			name:  "sentinel",
+			redis: config.Redis{Sentinel: config.Sentinel{MasterName: "mymaster", Addrs: []string{"localhost:26379"}}},
+			want:  &redis.Client{},
+		},
+		{
+			name:  "cluster",
+			redis: config.Redis{Cluster: config.Cluster{Addrs: []string{"localhost:7000", "localhost:7001"}}},
+			want:  &redis.ClusterClient{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := config.Default()
+			c.Redis = tc.redis
+			c.KeyPrefix = "tenant:"
+
+			client, err := NewRedis(c)
+			if err != nil {
+				t.Fatalf("NewRedis() error = %v", err)
+			}
+
+			defer client.Close()
+
+			assert.IsType(t, tc.want, client)
+		})
+	}
+}
+
+func TestPool(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := NewPool(config.Default()).Client(ctx)
+	assert.Error(t, err)
+
+	container, err := test.CreateContainer(ctx, t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool := NewPool(test.Config(t))
+
+	t.Cleanup(func() {
+		pool.Close()
+		test.TerminateContainer(container, t)
+	})
+
+	client, err := pool.Client(ctx)
+	if err != nil {
+		t.Fatalf("Pool.Client() error = %v", err)
+	}
+
+	again, err := pool.Client(ctx)
+	if err != nil {
+		t.Fatalf("Pool.Client() error = %v", err)
+	}
+
+	assert.Same(t, client, again)
+
+	if err = pool.Close(); err != nil {
+		t.Fatalf("Pool.Close() error = %v", err)
+	}
+
+	if again, err = pool.Client(ctx); err != nil {
+		t.Fatalf("Pool.Client() error = %v", err)
+	}
+
+	assert.NotSame(t, client, again)
+}
+
+// barrierHook answers commands without Redis, once n of them are in flight
+// at the same time.
+type barrierHook struct {
+	mu  sync.Mutex
+	n   int
+	all chan struct{}
+}
+
+func (h *barrierHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *barrierHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.mu.Lock()
+		if h.n--; h.n == 0 {
+			close(h.all)
+		}
+		h.mu.Unlock()
+
+		select {
+		case <-h.all:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (h *barrierHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestPoolChecksWithoutLock(t *testing.T) {
+	client := redis.NewClient(&redis.Options{})
+	client.AddHook(&barrierHook{n: 2, all: make(chan struct{})})
+
+	// the checks pass only, if they run concurrently
+	pool := &Pool{config: config.Default(), client: client}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if _, err := pool.Client(ctx); err != nil {
+				t.Errorf("Pool.Client() error = %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
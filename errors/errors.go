@@ -2,10 +2,16 @@ package errors
 
 import (
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"net/http"
 )
 
+// REQUEST_ID_HEADER carries the ID of a request, which is echoed in errors.
+// It is defined here, so that clients decoding errors do not depend on the
+// logging of the server.
+const REQUEST_ID_HEADER = "X-Request-ID"
+
 var (
 	BAD_REQUEST_ERROR = []ErrorContent{
 		{
@@ -14,6 +20,13 @@ var (
 		},
 	}
 
+	UNAUTHORIZED_ERROR = []ErrorContent{
+		{
+			Status: http.StatusUnauthorized,
+			Title:  "Unauthorized",
+		},
+	}
+
 	NOT_FOUND_ERROR = []ErrorContent{
 		{
 			Status: http.StatusNotFound,
@@ -28,6 +41,20 @@ var (
 		},
 	}
 
+	CONFLICT_ERROR = []ErrorContent{
+		{
+			Status: http.StatusConflict,
+			Title:  "Conflict",
+		},
+	}
+
+	REQUEST_ENTITY_TOO_LARGE_ERROR = []ErrorContent{
+		{
+			Status: http.StatusRequestEntityTooLarge,
+			Title:  "Request Entity Too Large",
+		},
+	}
+
 	UNSUPPORTED_MEDIA_TYPE_ERROR = []ErrorContent{
 		{
 			Status: http.StatusUnsupportedMediaType,
@@ -35,6 +62,20 @@ var (
 		},
 	}
 
+	UNPROCESSABLE_ENTITY_ERROR = []ErrorContent{
+		{
+			Status: http.StatusUnprocessableEntity,
+			Title:  "Unprocessable Entity",
+		},
+	}
+
+	TOO_MANY_REQUESTS_ERROR = []ErrorContent{
+		{
+			Status: http.StatusTooManyRequests,
+			Title:  "Too Many Requests",
+		},
+	}
+
 	INTERNAL_SERVER_ERROR = []ErrorContent{
 		{
 			Status: http.StatusInternalServerError,
@@ -54,6 +95,9 @@ type HTTPError struct {
 	Header *http.Header `json:"-"`
 
 	Errors *[]ErrorContent `json:"errors"`
+
+	// RequestID correlates the error with the logs of the request.
+	RequestID string `json:"request_id,omitempty"`
 }
 
 func (e *HTTPError) Error() string {
@@ -77,6 +121,10 @@ func (e *HTTPError) Write(w http.ResponseWriter) {
 		}
 	}
 
+	if id := w.Header().Get(REQUEST_ID_HEADER); id != "" {
+		e.RequestID = id
+	}
+
 	w.WriteHeader(e.Status)
 
 	if e.Errors == nil {
@@ -86,6 +134,14 @@ func (e *HTTPError) Write(w http.ResponseWriter) {
 	json.NewEncoder(w).Encode(e)
 }
 
+// IsBodyTooLarge reports whether err was caused by reading beyond the limit of
+// http.MaxBytesReader.
+func IsBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+
+	return stderrors.As(err, &maxBytesErr)
+}
+
 func NewHTTPError(status int, errors *[]ErrorContent) *HTTPError {
 	return &HTTPError{
 		Status: status,
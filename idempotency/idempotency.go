@@ -0,0 +1,136 @@
+// Package idempotency stores the responses to requests carrying an
+// Idempotency-Key header in Redis, so that retries of the request are
+// answered with the original response instead of being processed again.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	KEY_PREFIX = "idempotency:"
+
+	HEADER          = "Idempotency-Key"
+	REPLAYED_HEADER = "Idempotent-Replayed"
+
+	MAX_KEY_LENGTH = 255
+
+	// LOCK_TTL limits the time a request is considered in progress, in case
+	// the instance processing it fails before storing the response.
+	LOCK_TTL = time.Minute
+)
+
+// beginScript records the fingerprint of the request, if the key is unused,
+// otherwise it returns the stored fingerprint and response, whose status is
+// missing while the first request is in progress.
+var beginScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	redis.call("HSET", KEYS[1], "fingerprint", ARGV[1])
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return {}
+end
+
+return redis.call("HMGET", KEYS[1], "fingerprint", "status", "content_type", "body")
+`)
+
+// Response is a stored response, a Status of 0 denotes a request in
+// progress.
+type Response struct {
+	Fingerprint string
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// ValidKey reports whether the Idempotency-Key is non-empty, printable ASCII
+// and at most MAX_KEY_LENGTH characters long.
+func ValidKey(key string) bool {
+	if key == "" || len(key) > MAX_KEY_LENGTH {
+		return false
+	}
+
+	for _, c := range key {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Key returns the key storing the response, the Idempotency-Key is scoped to
+// the client, so that clients cannot replay the responses of others.
+func Key(client, idempotencyKey string) string {
+	sum := sha256.Sum256([]byte(client + "\n" + idempotencyKey))
+
+	return KEY_PREFIX + hex.EncodeToString(sum[:16])
+}
+
+// Fingerprint identifies the request by its method, URI, content type and
+// body.
+func Fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+
+	for _, part := range []string{r.Method, r.URL.RequestURI(), r.Header.Get("Content-Type")} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Begin marks the request as in progress and returns nil, if the key is
+// unused, otherwise it returns the stored response.
+func Begin(client redis.UniversalClient, ctx context.Context, key, fingerprint string) (res *Response, err error) {
+	var values []interface{}
+	if values, err = beginScript.Run(ctx, client, []string{key}, fingerprint, LOCK_TTL.Milliseconds()).Slice(); err != nil || len(values) == 0 {
+		return
+	}
+
+	field := func(i int) string {
+		value, _ := values[i].(string)
+
+		return value
+	}
+
+	res = &Response{
+		Fingerprint: field(0),
+		ContentType: field(2),
+		Body:        []byte(field(3)),
+	}
+
+	if status := field(1); status != "" {
+		if res.Status, err = strconv.Atoi(status); err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// Save stores the response of the request for ttl, along with its
+// fingerprint, in case the lock expired in the meantime.
+func Save(client redis.UniversalClient, ctx context.Context, key string, res *Response, ttl time.Duration) error {
+	_, err := client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "fingerprint", res.Fingerprint, "status", res.Status, "content_type", res.ContentType, "body", res.Body)
+		pipe.Expire(ctx, key, ttl)
+
+		return nil
+	})
+
+	return err
+}
+
+// Abort removes the key, so that the request may be retried.
+func Abort(client redis.UniversalClient, ctx context.Context, key string) error {
+	return client.Del(ctx, key).Err()
+}
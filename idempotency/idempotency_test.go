@@ -0,0 +1,96 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidKey(t *testing.T) {
+	assert.True(t, ValidKey("f3b0c442-98fc-1c14"))
+	assert.True(t, ValidKey("with space"))
+	assert.False(t, ValidKey(""))
+	assert.False(t, ValidKey("line\nbreak"))
+	assert.False(t, ValidKey("ümlaut"))
+	assert.False(t, ValidKey(strings.Repeat("a", MAX_KEY_LENGTH+1)))
+}
+
+func TestFingerprint(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/booleans?expires_in=60", nil)
+	req.Header.Set("Content-Type", "application/json")
+
+	fingerprint := Fingerprint(req, []byte(`{"value":true}`))
+
+	assert.Equal(t, fingerprint, Fingerprint(req, []byte(`{"value":true}`)))
+	assert.NotEqual(t, fingerprint, Fingerprint(req, []byte(`{"value":false}`)))
+
+	req.URL.RawQuery = "expires_in=120"
+
+	assert.NotEqual(t, fingerprint, Fingerprint(req, []byte(`{"value":true}`)))
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	opts, err := redis.ParseURL(os.Getenv(db.REDIS_URL_ENV))
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	rdb := redis.NewClient(opts)
+
+	key := Key("key:secret", "deploy-42")
+
+	assert.NotEqual(t, key, Key("key:other", "deploy-42"))
+
+	res, err := Begin(rdb, ctx, key, "fingerprint")
+
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+
+	res, err = Begin(rdb, ctx, key, "fingerprint")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Response{Fingerprint: "fingerprint", Body: []byte{}}, res)
+
+	want := &Response{Fingerprint: "fingerprint", Status: http.StatusOK, ContentType: "application/json", Body: []byte(`{"data":{}}`)}
+
+	if err = Save(rdb, ctx, key, want, time.Hour); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	res, err = Begin(rdb, ctx, key, "fingerprint")
+
+	assert.NoError(t, err)
+	assert.Equal(t, want, res)
+	assert.InDelta(t, time.Hour, rdb.TTL(ctx, key).Val(), float64(time.Second))
+
+	if err = Abort(rdb, ctx, key); err != nil {
+		t.Fatalf("Abort() error = %v", err)
+	}
+
+	res, err = Begin(rdb, ctx, key, "other")
+
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+}
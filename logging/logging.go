@@ -0,0 +1,120 @@
+// Package logging sets up structured JSON logging using log/slog. Records
+// logged with the context of a request carry its request ID.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/saschazar21/go-baas/errors"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	REQUEST_ID_HEADER = errors.REQUEST_ID_HEADER
+	REQUEST_ID_KEY    = "request_id"
+	TRACE_ID_KEY      = "trace_id"
+	SPAN_ID_KEY       = "span_id"
+
+	MAX_REQUEST_ID_LENGTH = 128
+)
+
+type requestIdKey struct{}
+
+// WithRequestID returns a copy of the context carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, id)
+}
+
+// RequestID returns the request ID of the context, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+
+	return id
+}
+
+// NewRequestID generates a random request ID.
+func NewRequestID() string {
+	data := make([]byte, 16)
+
+	if _, err := rand.Read(data); err != nil {
+		panic(fmt.Sprintf("failed to generate request ID: %v", err))
+	}
+
+	return hex.EncodeToString(data)
+}
+
+// ValidRequestID reports whether a request ID passed by a client may be
+// adopted, i.e. it consists of at most MAX_REQUEST_ID_LENGTH printable ASCII
+// characters without whitespace.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > MAX_REQUEST_ID_LENGTH {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Handler adds the request ID and the trace context of the context to the
+// records.
+type Handler struct {
+	slog.Handler
+}
+
+func (h Handler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String(REQUEST_ID_KEY, id))
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String(TRACE_ID_KEY, sc.TraceID().String()), slog.String(SPAN_ID_KEY, sc.SpanID().String()))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return Handler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h Handler) WithGroup(name string) slog.Handler {
+	return Handler{h.Handler.WithGroup(name)}
+}
+
+// ParseLevel parses one of the log levels debug, info, warn or error.
+func ParseLevel(level string) (l slog.Level, err error) {
+	if err = l.UnmarshalText([]byte(level)); err != nil {
+		return l, fmt.Errorf("invalid log level %q", level)
+	}
+
+	return
+}
+
+// New returns a logger writing records of at least the level as JSON to w.
+func New(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(Handler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+// Setup makes a logger writing JSON to stderr the default logger, which is
+// used by the log package as well.
+func Setup(level string) error {
+	l, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	slog.SetDefault(New(os.Stderr, l))
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := New(&buf, slog.LevelInfo)
+
+	logger.Debug("hidden")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	})
+	ctx := trace.ContextWithSpanContext(WithRequestID(context.Background(), "abc"), sc)
+
+	logger.InfoContext(ctx, "visible", "id", "test")
+	logger.With("component", "test").ErrorContext(context.Background(), "failed")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if !assert.Len(t, lines, 2) {
+		t.FailNow()
+	}
+
+	var record map[string]interface{}
+
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "visible", record["msg"])
+	assert.Equal(t, "test", record["id"])
+	assert.Equal(t, "abc", record[REQUEST_ID_KEY])
+	assert.Equal(t, sc.TraceID().String(), record[TRACE_ID_KEY])
+	assert.Equal(t, sc.SpanID().String(), record[SPAN_ID_KEY])
+
+	record = nil
+
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "ERROR", record["level"])
+	assert.Equal(t, "test", record["component"])
+	assert.NotContains(t, record, REQUEST_ID_KEY)
+	assert.NotContains(t, record, TRACE_ID_KEY)
+}
+
+func TestParseLevel(t *testing.T) {
+	for level, want := range map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"info":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	} {
+		got, err := ParseLevel(level)
+
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+
+	_, err := ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestValidRequestID(t *testing.T) {
+	assert.True(t, ValidRequestID("f3b0c442-98fc-1c14"))
+	assert.True(t, ValidRequestID(NewRequestID()))
+	assert.False(t, ValidRequestID(""))
+	assert.False(t, ValidRequestID("with space"))
+	assert.False(t, ValidRequestID("line\nbreak"))
+	assert.False(t, ValidRequestID(strings.Repeat("a", MAX_REQUEST_ID_LENGTH+1)))
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var now = time.Now
+
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+// emfUnit derives the CloudWatch unit from the suffix of the metric name.
+func emfUnit(name string) string {
+	switch {
+	case strings.HasSuffix(name, "_seconds"), strings.HasSuffix(name, "_seconds_sum"):
+		return "Seconds"
+	case strings.HasSuffix(name, "_total"), strings.HasSuffix(name, "_count"):
+		return "Count"
+	default:
+		return "None"
+	}
+}
+
+// WriteEMF writes every gathered metric as a line in the CloudWatch embedded
+// metric format, using its labels as dimensions. Histograms are written as
+// their sum and count, other types than counters, gauges and histograms are
+// skipped.
+func WriteEMF(w io.Writer, namespace string, g prometheus.Gatherer) error {
+	families, err := g.Gather()
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	timestamp := now().UnixMilli()
+
+	for _, family := range families {
+		name := family.GetName()
+
+		for _, m := range family.GetMetric() {
+			values := map[string]float64{}
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				values[name] = m.GetCounter().GetValue()
+			case dto.MetricType_GAUGE:
+				values[name] = m.GetGauge().GetValue()
+			case dto.MetricType_HISTOGRAM:
+				values[name+"_sum"] = m.GetHistogram().GetSampleSum()
+				values[name+"_count"] = float64(m.GetHistogram().GetSampleCount())
+			default:
+				continue
+			}
+
+			doc := map[string]interface{}{}
+			dimensions := []string{}
+
+			for _, label := range m.GetLabel() {
+				doc[label.GetName()] = label.GetValue()
+				dimensions = append(dimensions, label.GetName())
+			}
+
+			directive := emfDirective{
+				Namespace:  namespace,
+				Dimensions: [][]string{dimensions},
+			}
+
+			for _, key := range slices.Sorted(maps.Keys(values)) {
+				doc[key] = values[key]
+				directive.Metrics = append(directive.Metrics, emfMetric{Name: key, Unit: emfUnit(key)})
+			}
+
+			doc["_aws"] = emfMetadata{
+				Timestamp:         timestamp,
+				CloudWatchMetrics: []emfDirective{directive},
+			}
+
+			if err = enc.Encode(doc); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Flush writes the metrics of the Registry observed since the last flush in
+// the embedded metric format and resets them, as a Lambda instance serves
+// many invocations.
+func Flush(w io.Writer, namespace string) error {
+	defer Reset()
+
+	return WriteEMF(w, namespace, Registry)
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteEMF(t *testing.T) {
+	now = func() time.Time { return time.UnixMilli(1700000000000) }
+
+	t.Cleanup(func() {
+		now = time.Now
+	})
+
+	registry := prometheus.NewRegistry()
+
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "operations_total"}, []string{"operation"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "duration_seconds"})
+	summary := prometheus.NewSummary(prometheus.SummaryOpts{Name: "skipped"})
+
+	registry.MustRegister(counter, histogram, summary)
+
+	counter.WithLabelValues(OPERATION_TOGGLE).Add(2)
+	histogram.Observe(0.5)
+	histogram.Observe(1.5)
+	summary.Observe(1)
+
+	var buf bytes.Buffer
+	if err := WriteEMF(&buf, "baas", registry); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	assert.Len(t, lines, 2)
+	assert.JSONEq(t, `{
+		"_aws": {
+			"Timestamp": 1700000000000,
+			"CloudWatchMetrics": [{
+				"Namespace": "baas",
+				"Dimensions": [[]],
+				"Metrics": [
+					{"Name": "duration_seconds_count", "Unit": "Count"},
+					{"Name": "duration_seconds_sum", "Unit": "Seconds"}
+				]
+			}]
+		},
+		"duration_seconds_count": 2,
+		"duration_seconds_sum": 2
+	}`, lines[0])
+	assert.JSONEq(t, `{
+		"_aws": {
+			"Timestamp": 1700000000000,
+			"CloudWatchMetrics": [{
+				"Namespace": "baas",
+				"Dimensions": [["operation"]],
+				"Metrics": [{"Name": "operations_total", "Unit": "Count"}]
+			}]
+		},
+		"operation": "toggle",
+		"operations_total": 2
+	}`, lines[1])
+}
+
+func TestFlush(t *testing.T) {
+	Reset()
+
+	BooleanOperations.WithLabelValues(OPERATION_CREATE).Inc()
+
+	var buf bytes.Buffer
+	if err := Flush(&buf, "baas"); err != nil {
+		t.Fatal(err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, OPERATION_CREATE, doc["operation"])
+	assert.EqualValues(t, 1, doc[NAMESPACE+"_boolean_operations_total"])
+
+	buf.Reset()
+
+	if err := Flush(&buf, "baas"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Empty(t, buf.String(), "flushed metrics are reset")
+}
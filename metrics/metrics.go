@@ -0,0 +1,90 @@
+// Package metrics collects Prometheus metrics of the HTTP API, the Redis
+// commands and the boolean operations. Long-running processes expose them
+// using promhttp, on AWS Lambda they are flushed as embedded metric format
+// log lines after every invocation.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const NAMESPACE = "baas"
+
+const (
+	OPERATION_CREATE = "create"
+	OPERATION_UPDATE = "update"
+	OPERATION_TOGGLE = "toggle"
+	OPERATION_DELETE = "delete"
+)
+
+// Registry holds the metrics of this package, the runtime metrics of the Go
+// process are registered by the server exposing them.
+var Registry = prometheus.NewRegistry()
+
+var (
+	Requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route pattern, method and status code.",
+	}, []string{"route", "method", "status"})
+
+	RequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NAMESPACE,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route pattern, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	RedisCommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NAMESPACE,
+		Name:      "redis_command_duration_seconds",
+		Help:      "Latency of Redis commands by command, pipelines are observed as a whole.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"command"})
+
+	RedisErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "redis_command_errors_total",
+		Help:      "Failed Redis commands by command, missing keys are not counted.",
+	}, []string{"command"})
+
+	BooleanOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "boolean_operations_total",
+		Help:      "Successful creates, updates, toggles and deletes of booleans.",
+	}, []string{"operation"})
+
+	RateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NAMESPACE,
+		Name:      "rate_limited_requests_total",
+		Help:      "Requests rejected by the rate limits by scope, i.e. client or boolean.",
+	}, []string{"scope"})
+)
+
+var vecs = []interface{ Reset() }{
+	Requests,
+	RequestDuration,
+	RedisCommandDuration,
+	RedisErrors,
+	BooleanOperations,
+	RateLimited,
+}
+
+func init() {
+	Registry.MustRegister(
+		Requests,
+		RequestDuration,
+		RedisCommandDuration,
+		RedisErrors,
+		BooleanOperations,
+		RateLimited,
+	)
+}
+
+// Reset removes all observations, so that the next flush only reports new
+// ones.
+func Reset() {
+	for _, v := range vecs {
+		v.Reset()
+	}
+}
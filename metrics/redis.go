@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const PIPELINE_COMMAND = "pipeline"
+
+// RedisHook observes the latency and the errors of the commands of a client.
+type RedisHook struct{}
+
+func (RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+
+		err := next(ctx, cmd)
+
+		RedisCommandDuration.WithLabelValues(cmd.Name()).Observe(time.Since(start).Seconds())
+		observeError(cmd)
+
+		return err
+	}
+}
+
+func (RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+
+		err := next(ctx, cmds)
+
+		RedisCommandDuration.WithLabelValues(PIPELINE_COMMAND).Observe(time.Since(start).Seconds())
+
+		for _, cmd := range cmds {
+			observeError(cmd)
+		}
+
+		return err
+	}
+}
+
+func observeError(cmd redis.Cmder) {
+	if err := cmd.Err(); err != nil && err != redis.Nil {
+		RedisErrors.WithLabelValues(cmd.Name()).Inc()
+	}
+}
@@ -0,0 +1,282 @@
+// Package provider implements an OpenFeature provider, which resolves boolean
+// flags by their ID against the go-baas API.
+package provider
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/open-feature/go-sdk/openfeature"
+	"github.com/saschazar21/go-baas/client"
+)
+
+const (
+	PROVIDER_NAME = "go-baas"
+
+	DEFAULT_CACHE_TTL = 30 * time.Second
+
+	DEFAULT_CACHE_SIZE = 10000
+)
+
+// now is the clock used by the cache, replaceable in tests.
+var now = time.Now
+
+type Option func(*Provider)
+
+// WithCacheTTL sets how long resolved flags are cached, a TTL of 0 disables
+// caching.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(p *Provider) {
+		p.ttl = ttl
+	}
+}
+
+// WithCacheSize limits the amount of cached flags and subjects, the least
+// recently used entry is evicted beyond the limit.
+func WithCacheSize(size int) Option {
+	return func(p *Provider) {
+		p.size = size
+	}
+}
+
+// WithHTTPClient replaces the http.DefaultClient used for API requests.
+func WithHTTPClient(c *http.Client) Option {
+	return WithClientOptions(client.WithHTTPClient(c))
+}
+
+// WithClientOptions configures the client used for API requests. Unlike the
+// client, the provider does not retry failed lookups by default, as flags
+// fall back to their default value.
+func WithClientOptions(opts ...client.Option) Option {
+	return func(p *Provider) {
+		p.clientOpts = append(p.clientOpts, opts...)
+	}
+}
+
+type cacheEntry struct {
+	key       string
+	detail    openfeature.BoolResolutionDetail
+	expiresAt time.Time
+}
+
+type Provider struct {
+	client *client.Client
+	ttl    time.Duration
+	size   int
+
+	clientOpts []client.Option
+
+	// cache indexes the entries of lru, which is ordered from the most to the
+	// least recently used entry.
+	mu    sync.Mutex
+	cache map[string]*list.Element
+	lru   *list.List
+}
+
+// NewProvider creates a provider for the go-baas API at baseURL, e.g.
+// https://go-baas.netlify.app.
+func NewProvider(baseURL string, opts ...Option) *Provider {
+	p := &Provider{
+		ttl:        DEFAULT_CACHE_TTL,
+		size:       DEFAULT_CACHE_SIZE,
+		cache:      make(map[string]*list.Element),
+		lru:        list.New(),
+		clientOpts: []client.Option{client.WithRetries(0)},
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.client = client.New(baseURL, p.clientOpts...)
+
+	return p
+}
+
+func (p *Provider) Metadata() openfeature.Metadata {
+	return openfeature.Metadata{
+		Name: PROVIDER_NAME,
+	}
+}
+
+func (p *Provider) Hooks() []openfeature.Hook {
+	return []openfeature.Hook{}
+}
+
+// BooleanEvaluation resolves the boolean with the ID flag. If the evaluation
+// context contains a targeting key, the boolean is evaluated for it as
+// subject, applying the rollout of the boolean.
+func (p *Provider) BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, flatCtx openfeature.FlattenedContext) openfeature.BoolResolutionDetail {
+	subject, _ := flatCtx[openfeature.TargetingKey].(string)
+	key := flag + "\x00" + subject
+
+	if detail, ok := p.cached(key); ok {
+		return detail
+	}
+
+	detail := p.resolve(ctx, flag, subject)
+
+	if detail.Error() != nil {
+		detail.Value = defaultValue
+		detail.Reason = openfeature.ErrorReason
+
+		return detail
+	}
+
+	p.store(key, detail)
+
+	return detail
+}
+
+func (p *Provider) StringEvaluation(ctx context.Context, flag string, defaultValue string, flatCtx openfeature.FlattenedContext) openfeature.StringResolutionDetail {
+	return openfeature.StringResolutionDetail{
+		Value:                    defaultValue,
+		ProviderResolutionDetail: typeMismatch(),
+	}
+}
+
+func (p *Provider) FloatEvaluation(ctx context.Context, flag string, defaultValue float64, flatCtx openfeature.FlattenedContext) openfeature.FloatResolutionDetail {
+	return openfeature.FloatResolutionDetail{
+		Value:                    defaultValue,
+		ProviderResolutionDetail: typeMismatch(),
+	}
+}
+
+func (p *Provider) IntEvaluation(ctx context.Context, flag string, defaultValue int64, flatCtx openfeature.FlattenedContext) openfeature.IntResolutionDetail {
+	return openfeature.IntResolutionDetail{
+		Value:                    defaultValue,
+		ProviderResolutionDetail: typeMismatch(),
+	}
+}
+
+func (p *Provider) ObjectEvaluation(ctx context.Context, flag string, defaultValue any, flatCtx openfeature.FlattenedContext) openfeature.InterfaceResolutionDetail {
+	return openfeature.InterfaceResolutionDetail{
+		Value:                    defaultValue,
+		ProviderResolutionDetail: typeMismatch(),
+	}
+}
+
+func typeMismatch() openfeature.ProviderResolutionDetail {
+	return openfeature.ProviderResolutionDetail{
+		ResolutionError: openfeature.NewTypeMismatchResolutionError("go-baas only serves boolean flags"),
+		Reason:          openfeature.ErrorReason,
+	}
+}
+
+func (p *Provider) cached(key string) (detail openfeature.BoolResolutionDetail, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	e, ok := p.cache[key]
+	if !ok {
+		return detail, false
+	}
+
+	entry := e.Value.(*cacheEntry)
+
+	if !now().Before(entry.expiresAt) {
+		p.lru.Remove(e)
+		delete(p.cache, key)
+
+		return detail, false
+	}
+
+	p.lru.MoveToFront(e)
+
+	detail = entry.detail
+	detail.Reason = openfeature.CachedReason
+
+	return detail, true
+}
+
+func (p *Provider) store(key string, detail openfeature.BoolResolutionDetail) {
+	if p.ttl <= 0 || p.size <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry := &cacheEntry{
+		key:       key,
+		detail:    detail,
+		expiresAt: now().Add(p.ttl),
+	}
+
+	if e, ok := p.cache[key]; ok {
+		e.Value = entry
+		p.lru.MoveToFront(e)
+
+		return
+	}
+
+	p.cache[key] = p.lru.PushFront(entry)
+
+	for p.lru.Len() > p.size {
+		e := p.lru.Back()
+
+		p.lru.Remove(e)
+		delete(p.cache, e.Value.(*cacheEntry).key)
+	}
+}
+
+func (p *Provider) resolve(ctx context.Context, flag, subject string) (detail openfeature.BoolResolutionDetail) {
+	var value bool
+	var r string
+
+	if subject != "" {
+		e, err := p.client.Evaluate(ctx, flag, subject)
+		if err != nil {
+			detail.ResolutionError = resolutionError(err)
+			return
+		}
+
+		value, r = e.Value, e.Reason
+	} else {
+		b, err := p.client.Get(ctx, flag)
+		if err != nil {
+			detail.ResolutionError = resolutionError(err)
+			return
+		}
+
+		value = b.Value
+	}
+
+	detail.Value = value
+	detail.Reason = reason(r)
+	detail.Variant = strconv.FormatBool(value)
+
+	return
+}
+
+// resolutionError maps the errors.HTTPError returned by the API to an
+// OpenFeature error code.
+func resolutionError(err error) openfeature.ResolutionError {
+	switch client.StatusCode(err) {
+	case 0:
+		return openfeature.NewProviderNotReadyResolutionError(err.Error())
+	case http.StatusNotFound:
+		return openfeature.NewFlagNotFoundResolutionError(err.Error())
+	case http.StatusBadRequest:
+		return openfeature.NewInvalidContextResolutionError(err.Error())
+	default:
+		return openfeature.NewGeneralResolutionError(err.Error())
+	}
+}
+
+func reason(r string) openfeature.Reason {
+	switch r {
+	case client.EVALUATION_REASON_DISABLED:
+		return openfeature.DisabledReason
+	case client.EVALUATION_REASON_ALLOWED, client.EVALUATION_REASON_DENIED:
+		return openfeature.TargetingMatchReason
+	case client.EVALUATION_REASON_ROLLOUT:
+		return openfeature.SplitReason
+	default:
+		return openfeature.StaticReason
+	}
+}
@@ -0,0 +1,237 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-feature/go-sdk/openfeature"
+	rdb "github.com/redis/go-redis/v9"
+	v1 "github.com/saschazar21/go-baas/api/v1"
+	"github.com/saschazar21/go-baas/booleans"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	c := test.Config(t)
+
+	return httptest.NewServer(v1.NewRouter(c, db.NewPool(c)))
+}
+
+func TestProvider(t *testing.T) {
+	var client rdb.UniversalClient
+	var container *redis.RedisContainer
+	var err error
+	var server *httptest.Server
+
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+		test.TerminateContainer(container, t)
+	})
+
+	if container, err = test.CreateContainer(ctx, t); err != nil {
+		t.Fatal(err)
+	}
+
+	server = newServer(t)
+	if client, err = db.NewRedis(test.Config(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = client.HSet(ctx, "checkout", &booleans.Boolean{
+		Value: true,
+		Rollout: &booleans.Rollout{
+			Allow: []string{"user-42"},
+		},
+	}).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = client.HSet(ctx, "freeze", &booleans.Boolean{
+		Value: false,
+	}).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		flag         string
+		defaultValue bool
+		flatCtx      openfeature.FlattenedContext
+		want         bool
+		reason       openfeature.Reason
+		errorCode    openfeature.ErrorCode
+	}{
+		{
+			name:   "resolves boolean",
+			flag:   "checkout",
+			want:   true,
+			reason: openfeature.StaticReason,
+		},
+		{
+			name:   "resolves disabled boolean",
+			flag:   "freeze",
+			want:   false,
+			reason: openfeature.StaticReason,
+		},
+		{
+			name:    "resolves allow-listed subject",
+			flag:    "checkout",
+			flatCtx: openfeature.FlattenedContext{openfeature.TargetingKey: "user-42"},
+			want:    true,
+			reason:  openfeature.TargetingMatchReason,
+		},
+		{
+			name:    "resolves rolled out subject",
+			flag:    "checkout",
+			flatCtx: openfeature.FlattenedContext{openfeature.TargetingKey: "user-1"},
+			want:    false,
+			reason:  openfeature.SplitReason,
+		},
+		{
+			name:    "resolves subject of disabled boolean",
+			flag:    "freeze",
+			flatCtx: openfeature.FlattenedContext{openfeature.TargetingKey: "user-42"},
+			want:    false,
+			reason:  openfeature.DisabledReason,
+		},
+		{
+			name:         "falls back to default for inexistent boolean",
+			flag:         "inexistent",
+			defaultValue: true,
+			want:         true,
+			reason:       openfeature.ErrorReason,
+			errorCode:    openfeature.FlagNotFoundCode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProvider(server.URL, WithCacheTTL(0))
+
+			got := p.BooleanEvaluation(ctx, tt.flag, tt.defaultValue, tt.flatCtx)
+
+			assert.Equal(t, tt.want, got.Value)
+			assert.Equal(t, tt.reason, got.Reason)
+			assert.Equal(t, tt.errorCode, got.ResolutionDetail().ErrorCode)
+		})
+	}
+
+	t.Run("caches resolved booleans", func(t *testing.T) {
+		clock := time.Now()
+
+		now = func() time.Time { return clock }
+
+		t.Cleanup(func() {
+			now = time.Now
+		})
+
+		p := NewProvider(server.URL, WithCacheTTL(time.Minute))
+
+		got := p.BooleanEvaluation(ctx, "freeze", true, nil)
+		assert.False(t, got.Value)
+
+		if err := client.HSet(ctx, "freeze", booleans.BOOLEAN_VALUE, true).Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		got = p.BooleanEvaluation(ctx, "freeze", false, nil)
+		assert.False(t, got.Value)
+		assert.Equal(t, openfeature.CachedReason, got.Reason)
+
+		clock = clock.Add(time.Minute)
+
+		got = p.BooleanEvaluation(ctx, "freeze", false, nil)
+		assert.True(t, got.Value)
+		assert.Equal(t, openfeature.StaticReason, got.Reason)
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		p := NewProvider(server.URL)
+
+		got := p.BooleanEvaluation(ctx, "late", false, nil)
+		assert.Equal(t, openfeature.FlagNotFoundCode, got.ResolutionDetail().ErrorCode)
+
+		if err := client.HSet(ctx, "late", &booleans.Boolean{Value: true}).Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		got = p.BooleanEvaluation(ctx, "late", false, nil)
+		assert.True(t, got.Value)
+	})
+
+	t.Run("serves the OpenFeature SDK", func(t *testing.T) {
+		if err := openfeature.SetNamedProviderAndWait(t.Name(), NewProvider(server.URL)); err != nil {
+			t.Fatal(err)
+		}
+
+		c := openfeature.NewClient(t.Name())
+
+		value, err := c.BooleanValue(ctx, "checkout", false, openfeature.NewEvaluationContext("user-42", nil))
+		assert.NoError(t, err)
+		assert.True(t, value)
+
+		details, err := c.BooleanValueDetails(ctx, "inexistent", false, openfeature.EvaluationContext{})
+		assert.Error(t, err)
+		assert.Equal(t, openfeature.FlagNotFoundCode, details.ErrorCode)
+
+		if _, err := c.StringValue(ctx, "checkout", "", openfeature.EvaluationContext{}); err == nil {
+			t.Errorf("StringValue() error = %v, wantErr %v", err, true)
+		}
+	})
+}
+
+func TestProviderCache(t *testing.T) {
+	p := NewProvider("http://localhost", WithCacheSize(2))
+
+	for _, key := range []string{"a", "b"} {
+		p.store(key, openfeature.BoolResolutionDetail{Value: true})
+	}
+
+	// a is used more recently than b
+	if _, ok := p.cached("a"); !ok {
+		t.Fatal("cached(a) = false, want true")
+	}
+
+	p.store("c", openfeature.BoolResolutionDetail{Value: true})
+
+	assert.Len(t, p.cache, 2)
+	assert.Equal(t, 2, p.lru.Len())
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		_, ok := p.cached(key)
+		assert.Equal(t, want, ok, key)
+	}
+}
+
+func TestProviderOptions(t *testing.T) {
+	var attempts atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	t.Cleanup(server.Close)
+
+	t.Run("does not retry lookups", func(t *testing.T) {
+		attempts.Store(0)
+
+		p := NewProvider(server.URL, WithHTTPClient(server.Client()))
+
+		got := p.BooleanEvaluation(context.Background(), "freeze", true, nil)
+
+		assert.True(t, got.Value)
+		assert.Equal(t, openfeature.ErrorReason, got.Reason)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+}
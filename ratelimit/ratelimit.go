@@ -0,0 +1,107 @@
+// Package ratelimit implements token buckets stored in Redis, so that the
+// limits apply across all instances of the service.
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/config"
+)
+
+const (
+	KEY_PREFIX = "ratelimit:"
+
+	SCOPE_CLIENT  = "client"
+	SCOPE_BOOLEAN = "boolean"
+)
+
+// takeScript refills the bucket according to the time passed since the last
+// request, using the clock of Redis, as the clocks of the instances may
+// differ, and takes a token, if available. The bucket expires once it would
+// be full again. Times are in microseconds.
+var takeScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "updated_at")
+local tokens = tonumber(bucket[1]) or capacity
+local updated = tonumber(bucket[2]) or now
+
+tokens = math.min(capacity, tokens + math.max(0, now - updated) / interval)
+
+local allowed = 0
+local retry = 0
+
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) * interval)
+end
+
+local reset = math.ceil((capacity - tokens) * interval)
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "updated_at", now)
+redis.call("PEXPIRE", KEYS[1], math.max(math.ceil(reset / 1000), 1))
+
+return {allowed, math.floor(tokens), retry, reset}
+`)
+
+// Result describes the state of a bucket after a request.
+type Result struct {
+	Allowed bool
+
+	// Limit is the capacity of the bucket.
+	Limit int
+
+	// Remaining is the amount of requests allowed at once.
+	Remaining int
+
+	// RetryAfter is the time until the next request is allowed, if the
+	// request was rejected.
+	RetryAfter time.Duration
+
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+}
+
+// ClientKey returns the key of the bucket of a client, identified by its API
+// key or IP. API keys are hashed, so that they are not stored in Redis.
+func ClientKey(id string) string {
+	sum := sha256.Sum256([]byte(id))
+
+	return KEY_PREFIX + SCOPE_CLIENT + ":" + hex.EncodeToString(sum[:16])
+}
+
+// BooleanKey returns the key of the bucket limiting the writes to a boolean.
+func BooleanKey(id string) string {
+	return KEY_PREFIX + SCOPE_BOOLEAN + ":" + id
+}
+
+// Take takes a token from the bucket at key, which is created according to
+// the limit on first use.
+func Take(client redis.UniversalClient, ctx context.Context, key string, limit config.Limit) (r *Result, err error) {
+	capacity := limit.Capacity()
+
+	var values []int64
+	interval := max(limit.Interval().Microseconds(), 1)
+
+	if values, err = takeScript.Run(ctx, client, []string{key}, capacity, interval).Int64Slice(); err != nil {
+		return
+	}
+
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      capacity,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+		Reset:      time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/saschazar21/go-baas/config"
+	"github.com/saschazar21/go-baas/db"
+	"github.com/saschazar21/go-baas/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTake(t *testing.T) {
+	ctx := context.Background()
+
+	container, err := test.CreateContainer(ctx, t)
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	t.Cleanup(func() {
+		test.TerminateContainer(container, t)
+	})
+
+	opts, err := redis.ParseURL(os.Getenv(db.REDIS_URL_ENV))
+
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	rdb := redis.NewClient(opts)
+
+	limit := config.Limit{Rate: 2, Period: time.Hour, Burst: 3}
+	key := BooleanKey("test")
+
+	for i := 2; i >= 0; i-- {
+		res, err := Take(rdb, ctx, key, limit)
+		if err != nil {
+			t.Fatalf("Take() error = %v", err)
+		}
+
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 3, res.Limit)
+		assert.Equal(t, i, res.Remaining)
+		assert.Zero(t, res.RetryAfter)
+	}
+
+	res, err := Take(rdb, ctx, key, limit)
+	if err != nil {
+		t.Fatalf("Take() error = %v", err)
+	}
+
+	assert.False(t, res.Allowed)
+	assert.Zero(t, res.Remaining)
+	assert.InDelta(t, 30*time.Minute, res.RetryAfter, float64(time.Second))
+	assert.InDelta(t, 90*time.Minute, res.Reset, float64(time.Second))
+
+	ttl := rdb.PTTL(ctx, key).Val()
+
+	assert.InDelta(t, 90*time.Minute, ttl, float64(time.Second))
+
+	res, err = Take(rdb, ctx, BooleanKey("other"), limit)
+
+	assert.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
+
+func TestClientKey(t *testing.T) {
+	key := ClientKey("key:secret")
+
+	assert.Equal(t, key, ClientKey("key:secret"))
+	assert.NotEqual(t, key, ClientKey("key:other"))
+	assert.NotContains(t, key, "secret")
+	assert.Contains(t, key, KEY_PREFIX+SCOPE_CLIENT+":")
+}
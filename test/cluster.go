@@ -0,0 +1,181 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const CLUSTER_SLOTS = 16384
+
+var ErrCrossSlot = errors.New("CROSSSLOT Keys in request don't hash to the same slot")
+
+// CreateCluster starts the given amount of single node containers and
+// returns a cluster client, which distributes the slots evenly among them.
+// Like Redis Cluster, the client rejects multi-key commands, scripts and
+// transactions, whose keys do not share a slot.
+func CreateCluster(ctx context.Context, t *testing.T, nodes int) (client *goredis.ClusterClient, err error) {
+	addrs := make([]string, 0, nodes)
+
+	for i := 0; i < nodes; i++ {
+		container, err := CreateContainer(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+
+		t.Cleanup(func() {
+			TerminateContainer(container, t)
+		})
+
+		options, err := goredis.ParseURL(os.Getenv(REDIS_URL_ENV))
+		if err != nil {
+			return nil, err
+		}
+
+		addrs = append(addrs, options.Addr)
+	}
+
+	client = goredis.NewClusterClient(&goredis.ClusterOptions{
+		ClusterSlots: func(ctx context.Context) ([]goredis.ClusterSlot, error) {
+			slots := make([]goredis.ClusterSlot, 0, len(addrs))
+
+			for i, addr := range addrs {
+				slots = append(slots, goredis.ClusterSlot{
+					Start: i * CLUSTER_SLOTS / len(addrs),
+					End:   (i+1)*CLUSTER_SLOTS/len(addrs) - 1,
+					Nodes: []goredis.ClusterNode{{Addr: addr}},
+				})
+			}
+
+			return slots, nil
+		},
+	})
+
+	client.AddHook(crossSlotHook{})
+
+	t.Cleanup(func() {
+		client.Close()
+	})
+
+	return
+}
+
+// Slot returns the cluster slot of the key, hashing its hash tag, if any.
+func Slot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+
+	// CRC16-CCITT (XMODEM)
+	var crc uint16
+
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+
+	return int(crc) % CLUSTER_SLOTS
+}
+
+// crossSlotHook emulates the CROSSSLOT errors of Redis Cluster, which single
+// nodes do not report.
+type crossSlotHook struct{}
+
+func (crossSlotHook) DialHook(next goredis.DialHook) goredis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (crossSlotHook) ProcessHook(next goredis.ProcessHook) goredis.ProcessHook {
+	return func(ctx context.Context, cmd goredis.Cmder) error {
+		if !sameSlot(keys(cmd)) {
+			cmd.SetErr(ErrCrossSlot)
+			return ErrCrossSlot
+		}
+
+		return next(ctx, cmd)
+	}
+}
+
+func (crossSlotHook) ProcessPipelineHook(next goredis.ProcessPipelineHook) goredis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []goredis.Cmder) error {
+		if len(cmds) == 0 || cmds[0].Name() != "multi" {
+			return next(ctx, cmds)
+		}
+
+		var all []string
+		for _, cmd := range cmds {
+			all = append(all, keys(cmd)...)
+		}
+
+		if !sameSlot(all) {
+			for _, cmd := range cmds {
+				cmd.SetErr(ErrCrossSlot)
+			}
+
+			return ErrCrossSlot
+		}
+
+		return next(ctx, cmds)
+	}
+}
+
+// keys returns the keys of the commands used by this project.
+func keys(cmd goredis.Cmder) []string {
+	args := cmd.Args()
+	str := fmt.Sprint
+
+	switch strings.ToLower(cmd.Name()) {
+	case "multi", "exec", "ping", "scan", "hello", "client", "select", "script", "command", "cluster":
+		return nil
+	case "del", "exists", "mget", "touch", "unlink", "watch":
+		keys := make([]string, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			keys = append(keys, str(arg))
+		}
+
+		return keys
+	case "eval", "evalsha", "eval_ro", "evalsha_ro":
+		n, _ := strconv.Atoi(str(args[2]))
+
+		keys := make([]string, 0, n)
+		for _, arg := range args[3 : 3+n] {
+			keys = append(keys, str(arg))
+		}
+
+		return keys
+	default:
+		if len(args) < 2 {
+			return nil
+		}
+
+		return []string{str(args[1])}
+	}
+}
+
+func sameSlot(keys []string) bool {
+	for _, key := range keys {
+		if Slot(key) != Slot(keys[0]) {
+			return false
+		}
+	}
+
+	return true
+}
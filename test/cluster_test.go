@@ -0,0 +1,57 @@
+package test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlot(t *testing.T) {
+	assert.Equal(t, 12739, Slot("123456789"))
+	assert.Equal(t, 12182, Slot("foo"))
+	assert.Equal(t, Slot("user1000"), Slot("{user1000}.following"))
+	assert.Equal(t, Slot("{}.following"), Slot("{}.following"))
+	assert.NotEqual(t, Slot("{}.following"), Slot("{}.followers"))
+}
+
+func TestCluster(t *testing.T) {
+	ctx := context.Background()
+
+	client, err := CreateCluster(ctx, t, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var masters atomic.Int32
+
+	if err = client.ForEachMaster(ctx, func(ctx context.Context, node *goredis.Client) error {
+		masters.Add(1)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int32(3), masters.Load())
+
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		if err = client.Set(ctx, key, key, 0).Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, key, client.Get(ctx, key).Val())
+	}
+
+	assert.ErrorIs(t, client.Del(ctx, "a", "b").Err(), ErrCrossSlot)
+	assert.NoError(t, client.Del(ctx, "a", "{a}.b").Err())
+
+	_, err = client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, "a", "1", 0)
+		pipe.Set(ctx, "b", "1", 0)
+		return nil
+	})
+
+	assert.ErrorIs(t, err, ErrCrossSlot)
+}
@@ -0,0 +1,18 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/saschazar21/go-baas/config"
+)
+
+// Config loads the config from the env, e.g. the REDIS_URL set by
+// CreateContainer.
+func Config(t *testing.T) *config.Config {
+	c, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
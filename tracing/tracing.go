@@ -0,0 +1,123 @@
+// Package tracing sets up OpenTelemetry tracing, exporting spans using OTLP
+// as configured by the standard OTEL_* env, and propagating the W3C trace
+// context.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+const SERVICE_NAME = "go-baas"
+
+const (
+	SDK_DISABLED_ENV         = "OTEL_SDK_DISABLED"
+	TRACES_EXPORTER_ENV      = "OTEL_TRACES_EXPORTER"
+	OTLP_ENDPOINT_ENV        = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	OTLP_TRACES_ENDPOINT_ENV = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	OTLP_PROTOCOL_ENV        = "OTEL_EXPORTER_OTLP_PROTOCOL"
+	OTLP_TRACES_PROTOCOL_ENV = "OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"
+)
+
+const (
+	TRACES_EXPORTER_OTLP = "otlp"
+	TRACES_EXPORTER_NONE = "none"
+)
+
+const (
+	PROTOCOL_GRPC          = "grpc"
+	PROTOCOL_HTTP_PROTOBUF = "http/protobuf"
+
+	DEFAULT_PROTOCOL = PROTOCOL_HTTP_PROTOBUF
+)
+
+// Enabled reports whether spans are exported, i.e. the SDK is not disabled
+// and either the OTLP exporter or an OTLP endpoint is configured.
+func Enabled() bool {
+	if strings.EqualFold(os.Getenv(SDK_DISABLED_ENV), "true") {
+		return false
+	}
+
+	switch os.Getenv(TRACES_EXPORTER_ENV) {
+	case TRACES_EXPORTER_OTLP:
+		return true
+	case TRACES_EXPORTER_NONE:
+		return false
+	}
+
+	return os.Getenv(OTLP_ENDPOINT_ENV) != "" || os.Getenv(OTLP_TRACES_ENDPOINT_ENV) != ""
+}
+
+// protocol returns the OTLP protocol of the traces, which defaults to
+// http/protobuf.
+func protocol() string {
+	for _, env := range []string{OTLP_TRACES_PROTOCOL_ENV, OTLP_PROTOCOL_ENV} {
+		if p := os.Getenv(env); p != "" {
+			return p
+		}
+	}
+
+	return DEFAULT_PROTOCOL
+}
+
+func newExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	switch p := protocol(); p {
+	case PROTOCOL_GRPC:
+		return otlptracegrpc.New(ctx)
+	case PROTOCOL_HTTP_PROTOBUF:
+		return otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q, use %s or %s", p, PROTOCOL_GRPC, PROTOCOL_HTTP_PROTOBUF)
+	}
+}
+
+// Setup installs the W3C trace context and baggage propagators and, if
+// Enabled, a tracer provider exporting spans in batches. The endpoint,
+// headers, sampler and batching are configured by the OTEL_* env, the service
+// name defaults to go-baas. The returned provider is nil, if tracing is
+// disabled, otherwise it has to be flushed before the process is frozen or
+// exits.
+func Setup(ctx context.Context) (*sdktrace.TracerProvider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !Enabled() {
+		return nil, nil
+	}
+
+	exporter, err := newExporter(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create span exporter: %w", err)
+	}
+
+	// the env takes precedence over the default service name
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(SERVICE_NAME)))
+	if err == nil {
+		res, err = resource.Merge(res, resource.Environment())
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tp)
+
+	return tp, nil
+}
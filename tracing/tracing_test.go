@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want bool
+	}{
+		{
+			name: "unconfigured",
+		},
+		{
+			name: "endpoint",
+			env:  map[string]string{OTLP_ENDPOINT_ENV: "http://collector:4318"},
+			want: true,
+		},
+		{
+			name: "traces endpoint",
+			env:  map[string]string{OTLP_TRACES_ENDPOINT_ENV: "http://collector:4318/v1/traces"},
+			want: true,
+		},
+		{
+			name: "otlp exporter",
+			env:  map[string]string{TRACES_EXPORTER_ENV: TRACES_EXPORTER_OTLP},
+			want: true,
+		},
+		{
+			name: "no exporter",
+			env:  map[string]string{TRACES_EXPORTER_ENV: TRACES_EXPORTER_NONE, OTLP_ENDPOINT_ENV: "http://collector:4318"},
+		},
+		{
+			name: "disabled sdk",
+			env:  map[string]string{SDK_DISABLED_ENV: "TRUE", OTLP_ENDPOINT_ENV: "http://collector:4318"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, env := range []string{SDK_DISABLED_ENV, TRACES_EXPORTER_ENV, OTLP_ENDPOINT_ENV, OTLP_TRACES_ENDPOINT_ENV} {
+				t.Setenv(env, tc.env[env])
+			}
+
+			assert.Equal(t, tc.want, Enabled())
+		})
+	}
+}
+
+func TestSetup(t *testing.T) {
+	t.Setenv(TRACES_EXPORTER_ENV, TRACES_EXPORTER_OTLP)
+
+	t.Run("exports using http/protobuf by default", func(t *testing.T) {
+		tp, err := Setup(context.Background())
+
+		assert.NoError(t, err)
+		assert.NotNil(t, tp)
+
+		assert.NoError(t, tp.Shutdown(context.Background()))
+	})
+
+	t.Run("rejects unsupported protocol", func(t *testing.T) {
+		t.Setenv(OTLP_TRACES_PROTOCOL_ENV, "http/json")
+
+		_, err := Setup(context.Background())
+
+		assert.ErrorContains(t, err, `unsupported OTLP protocol "http/json"`)
+	})
+
+	t.Run("skips provider when disabled", func(t *testing.T) {
+		t.Setenv(TRACES_EXPORTER_ENV, TRACES_EXPORTER_NONE)
+
+		tp, err := Setup(context.Background())
+
+		assert.NoError(t, err)
+		assert.Nil(t, tp)
+	})
+}
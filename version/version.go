@@ -0,0 +1,51 @@
+// Package version holds the build information of the binaries, which is
+// injected at build time, e.g.:
+//
+//	go build -ldflags "-X github.com/saschazar21/go-baas/version.Version=v1.2.3"
+//
+// Without injection, the commit and build time are taken from the VCS
+// information embedded by the Go toolchain, if available.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+const UNKNOWN = "unknown"
+
+var (
+	Version   = "dev"
+	Commit    = UNKNOWN
+	BuildTime = UNKNOWN
+)
+
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information of the running binary.
+func Get() *Info {
+	info := &Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range build.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == UNKNOWN:
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildTime == UNKNOWN:
+				info.BuildTime = s.Value
+			}
+		}
+	}
+
+	return info
+}